@@ -0,0 +1,606 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/client9/gospell"
+)
+
+// version is the release number (set with -ldflags "-X main.version=..." when packaging)
+var version = "0.3.0"
+
+// Exit codes for scripting
+const (
+	exitOK       = 0 // success, nothing to report
+	exitFindings = 1 // a check found problems (misspellings, lint findings, files needing conversion)
+	exitUsage    = 2 // bad flags or arguments
+	exitFailure  = 3 // a file, dictionary or config couldn't be read or written
+)
+
+// fileTarget is a file named on the command line and where to put the cursor
+type fileTarget struct {
+	path string
+	line int // 1-based, 0 for the top
+	col  int // 1-based byte column, 0 for the line start
+}
+
+// parseFileArgs reads file arguments: "+LINE" applies to the file after it,
+// and "file:line" or "file:line:col" jump within the file (unless a file by
+// that full name exists)
+func parseFileArgs(args []string) ([]fileTarget, error) {
+	var targets []fileTarget
+	pendingLine := 0
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "+") && len(arg) > 1 {
+			n, err := strconv.Atoi(arg[1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid line number %q", arg)
+			}
+			pendingLine = n
+			continue
+		}
+
+		target := splitFilePosition(arg)
+		if pendingLine > 0 {
+			target.line, target.col = pendingLine, 0
+			pendingLine = 0
+		}
+		targets = append(targets, target)
+	}
+	if pendingLine > 0 {
+		return nil, fmt.Errorf("+%d must come before a file name", pendingLine)
+	}
+	return targets, nil
+}
+
+// splitFilePosition turns "notes.md:12:5" into a path, line and column
+func splitFilePosition(arg string) fileTarget {
+	if _, err := os.Stat(arg); err == nil {
+		return fileTarget{path: arg}
+	}
+
+	parts := strings.Split(arg, ":")
+	var numbers []int
+	for len(parts) > 1 && len(numbers) < 2 {
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil || n < 1 {
+			break
+		}
+		numbers = append([]int{n}, numbers...)
+		parts = parts[:len(parts)-1]
+	}
+
+	target := fileTarget{path: strings.Join(parts, ":")}
+	if len(numbers) > 0 {
+		target.line = numbers[0]
+	}
+	if len(numbers) > 1 {
+		target.col = numbers[1]
+	}
+	return target
+}
+
+// parseInterleaved parses flags that may come before, between or after file names
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// subcommand is a non-interactive tool run as "tuiwrite <name> ..."
+type subcommand struct {
+	summary string
+	run     func(args []string, stdout io.Writer, stderr io.Writer) int
+}
+
+// subcommands are looked up by the first command-line argument
+var subcommands = map[string]subcommand{
+	"stats":   {"Print word counts and readability scores", runStatsCommand},
+	"spell":   {"List misspelled words (exit code 1 when any are found)", runSpellCommand},
+	"lint":    {"List prose style findings (exit code 1 when any are found)", runLintCommand},
+	"export":  {"Render a document as HTML or plain text", runExportCommand},
+	"convert": {"Normalise line endings, encoding and tabs (--check to only report)", runConvertCommand},
+}
+
+// printUsage writes the top-level help text
+func printUsage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "Usage: tuiwrite [flags] [+LINE] [file[:line[:col]] ...]")
+	fmt.Fprintln(w, "       tuiwrite <command> [flags] file ...")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, subcommands[name].summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+// newSubcommandFlags creates the flag set shared by every subcommand
+func newSubcommandFlags(name string, stderr io.Writer, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&userConfigOverride, "config", "", "Use this config file instead of the user config.toml")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: tuiwrite %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseSubcommandArgs parses flags and requires at least one file
+func parseSubcommandArgs(fs *flag.FlagSet, args []string) ([]string, int) {
+	files, err := parseInterleaved(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil, exitOK
+		}
+		return nil, exitUsage
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return nil, exitUsage
+	}
+	return files, -1
+}
+
+// readDocument loads a file for a subcommand (a missing file is an error here)
+func readDocument(path string) ([]string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return loadFile(path)
+}
+
+// documentConfig loads the settings that apply to a file's directory
+func documentConfig(path string) (Config, error) {
+	return loadConfig(configPaths(filepath.Dir(path)))
+}
+
+// fileStats is the JSON form of one file's statistics
+type fileStats struct {
+	File                 string  `json:"file"`
+	Words                int     `json:"words"`
+	Characters           int     `json:"characters"`
+	CharactersNoSpaces   int     `json:"characters_no_spaces"`
+	Sentences            int     `json:"sentences"`
+	Paragraphs           int     `json:"paragraphs"`
+	ReadingMinutes       int     `json:"reading_minutes"`
+	FleschReadingEase    float64 `json:"flesch_reading_ease"`
+	FleschKincaidGrade   float64 `json:"flesch_kincaid_grade"`
+	GunningFog           float64 `json:"gunning_fog"`
+	ColemanLiau          float64 `json:"coleman_liau"`
+	AutomatedReadability float64 `json:"automated_readability"`
+}
+
+// newFileStats summarises computeTextStats for output
+func newFileStats(path string, s textStats) fileStats {
+	round := func(f float64) float64 { return float64(int(f*10+0.5)) / 10 }
+	return fileStats{
+		File:                 path,
+		Words:                s.words,
+		Characters:           s.characters,
+		CharactersNoSpaces:   s.charsNoSpace,
+		Sentences:            s.sentences,
+		Paragraphs:           s.paragraphs,
+		ReadingMinutes:       s.readingMinutes(),
+		FleschReadingEase:    round(s.fleschReadingEase()),
+		FleschKincaidGrade:   round(s.fleschKincaidGrade()),
+		GunningFog:           round(s.gunningFog()),
+		ColemanLiau:          round(s.colemanLiau()),
+		AutomatedReadability: round(s.automatedReadability()),
+	}
+}
+
+// runStatsCommand handles "tuiwrite stats [--json] file..."
+func runStatsCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := newSubcommandFlags("stats", stderr, "[--json] file ...")
+	asJSON := fs.Bool("json", false, "Print JSON instead of a table")
+	files, code := parseSubcommandArgs(fs, args)
+	if code >= 0 {
+		return code
+	}
+
+	var results []fileStats
+	status := exitOK
+	total := 0
+	for _, path := range files {
+		lines, err := readDocument(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "tuiwrite stats: %v\n", err)
+			status = exitFailure
+			continue
+		}
+		s := newFileStats(path, computeTextStats(lines))
+		results = append(results, s)
+		total += s.Words
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return exitFailure
+		}
+		return status
+	}
+
+	for i, s := range results {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintln(stdout, s.File)
+		fmt.Fprintf(stdout, "  Words:             %s\n", formatCount(s.Words))
+		fmt.Fprintf(stdout, "  Characters:        %s (%s without spaces)\n", formatCount(s.Characters), formatCount(s.CharactersNoSpaces))
+		fmt.Fprintf(stdout, "  Sentences:         %s\n", formatCount(s.Sentences))
+		fmt.Fprintf(stdout, "  Paragraphs:        %s\n", formatCount(s.Paragraphs))
+		fmt.Fprintf(stdout, "  Reading time:      %d min\n", s.ReadingMinutes)
+		fmt.Fprintf(stdout, "  Flesch ease:       %.1f\n", s.FleschReadingEase)
+		fmt.Fprintf(stdout, "  Flesch-Kincaid:    %.1f\n", s.FleschKincaidGrade)
+		fmt.Fprintf(stdout, "  Gunning fog:       %.1f\n", s.GunningFog)
+		fmt.Fprintf(stdout, "  Coleman-Liau:      %.1f\n", s.ColemanLiau)
+		fmt.Fprintf(stdout, "  ARI:               %.1f\n", s.AutomatedReadability)
+	}
+	if len(results) > 1 {
+		fmt.Fprintf(stdout, "\nTotal: %s words in %d files\n", formatCount(total), len(results))
+	}
+	return status
+}
+
+// documentSpellChecker picks a file's languages the way the editor does:
+// --lang, then the document, then the project, then the configured default.
+// Dictionaries already in loaded are reused (and new ones added to it); nothing is downloaded and
+// missing dictionaries are returned.
+func documentSpellChecker(path string, lines []string, langs []string, cfg Config, loaded map[string]*gospell.GoSpell) (*SpellChecker, []string) {
+	doc := model{
+		filename:     path,
+		lines:        lines,
+		config:       cfg,
+		spellChecker: newSpellChecker(cfg.SpellLanguage),
+	}
+	doc.spellChecker.checkers = loaded
+
+	var missing []string
+	if len(langs) > 0 {
+		doc.spellChecker.loadWordLists(doc.projectDir())
+		missing = doc.spellChecker.useLocalLanguages(langs)
+	} else {
+		missing = doc.applyDocumentLanguages()
+		if !doc.spellChecker.loaded() && !containsString(missing, cfg.SpellLanguage) {
+			missing = append(missing, doc.spellChecker.useLocalLanguages([]string{cfg.SpellLanguage})...)
+		}
+	}
+	doc.spellChecker.enabled = doc.spellChecker.loaded() // the spell command always checks
+	return doc.spellChecker, missing
+}
+
+// proseLines hides front matter and code in Markdown files from the checkers
+func proseLines(path string, lines []string) []string {
+	if isMarkdownFile(path) {
+		return maskNonProse(lines)
+	}
+	return lines
+}
+
+// parseReportFormat validates --format for spell and lint
+func parseReportFormat(format string, name string, stderr io.Writer) bool {
+	if containsString(reportFormats, format) {
+		return true
+	}
+	fmt.Fprintf(stderr, "tuiwrite %s: unknown format %q (use %s)\n", name, format, strings.Join(reportFormats, ", "))
+	return false
+}
+
+// runSpellCommand handles "tuiwrite spell [--lang uk,es] [--format text|json|sarif] file..."
+func runSpellCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := newSubcommandFlags("spell", stderr, "[--lang codes] [--format text|json|sarif] file ...")
+	langFlag := fs.String("lang", "", "Languages to check against, e.g. uk or us,es (default: as the editor would choose)")
+	format := fs.String("format", "text", "Output format: text, json or sarif")
+	suggestions := fs.Int("suggestions", 3, "Suggestions to list per misspelling (0 for none)")
+	files, code := parseSubcommandArgs(fs, args)
+	if code >= 0 {
+		return code
+	}
+	if !parseReportFormat(*format, "spell", stderr) {
+		return exitUsage
+	}
+
+	langs := parseLanguageList(*langFlag)
+	if *langFlag != "" && len(langs) == 0 {
+		fmt.Fprintf(stderr, "tuiwrite spell: no dictionary for %q\n", *langFlag)
+		return exitUsage
+	}
+
+	status := exitOK
+	loaded := make(map[string]*gospell.GoSpell)
+	var issues []checkIssue
+	for _, path := range files {
+		lines, err := readDocument(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "tuiwrite spell: %v\n", err)
+			status = exitFailure
+			continue
+		}
+		cfg, err := documentConfig(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "tuiwrite spell: config: %v\n", err)
+			return exitFailure
+		}
+
+		sc, missing := documentSpellChecker(path, lines, langs, cfg, loaded)
+		if len(missing) > 0 {
+			fmt.Fprintf(stderr, "tuiwrite spell: %s: dictionary not installed: %s (run :spell <lang> in the editor to download)\n",
+				path, strings.ToUpper(strings.Join(missing, ", ")))
+			status = exitFailure
+			continue
+		}
+
+		for _, miss := range findMisspellings(proseLines(path, lines), sc) {
+			issue := newCheckIssue(path, lines, miss.line, miss.col, miss.col+len(miss.word))
+			issue.Rule = spellingRule
+			issue.Word = miss.word
+			issue.Message = "Unknown word: " + miss.word
+			if *suggestions > 0 {
+				issue.Suggestions = sc.suggest(miss.word, miss.lang, *suggestions)
+				if len(issue.Suggestions) > 0 {
+					issue.Message += " (did you mean " + strings.Join(issue.Suggestions, ", ") + "?)"
+				}
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	if err := writeReport(stdout, *format, issues, []reportRule{{spellingRule, "Word not found in the dictionary or word lists"}}); err != nil {
+		return exitFailure
+	}
+	if status == exitOK && len(issues) > 0 {
+		status = exitFindings
+	}
+	return status
+}
+
+// runLintCommand handles "tuiwrite lint [--format text|json|sarif] file..."
+func runLintCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := newSubcommandFlags("lint", stderr, "[--format text|json|sarif] file ...")
+	format := fs.String("format", "text", "Output format: text, json or sarif")
+	files, code := parseSubcommandArgs(fs, args)
+	if code >= 0 {
+		return code
+	}
+	if !parseReportFormat(*format, "lint", stderr) {
+		return exitUsage
+	}
+
+	status := exitOK
+	var issues []checkIssue
+	for _, path := range files {
+		lines, err := readDocument(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "tuiwrite lint: %v\n", err)
+			status = exitFailure
+			continue
+		}
+		cfg, err := documentConfig(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "tuiwrite lint: config: %v\n", err)
+			return exitFailure
+		}
+		lintConfig, err := loadLintConfig(filepath.Dir(path))
+		if err != nil {
+			fmt.Fprintf(stderr, "tuiwrite lint: %v\n", err)
+			return exitFailure
+		}
+
+		// The spelling-variant rule follows the document's language
+		language := cfg.SpellLanguage
+		if langs := parseDocumentLanguages(lines); len(langs) > 0 {
+			language = langs[0]
+		}
+
+		linter := &Linter{enabled: true, config: lintConfig}
+		for _, f := range linter.lintDocument(proseLines(path, lines), language) {
+			issue := newCheckIssue(path, lines, f.Line, f.Start, f.End)
+			issue.Rule = f.Rule
+			issue.Message = f.Message
+			issues = append(issues, issue)
+		}
+	}
+
+	var rules []reportRule
+	for _, r := range lintRules {
+		rules = append(rules, reportRule{r.name, r.description})
+	}
+	if err := writeReport(stdout, *format, issues, rules); err != nil {
+		return exitFailure
+	}
+	if status == exitOK && len(issues) > 0 {
+		status = exitFindings
+	}
+	return status
+}
+
+// runExportCommand handles "tuiwrite export [--format html|txt] [-o out] file"
+func runExportCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := newSubcommandFlags("export", stderr, "[--format html|txt] [-o output] file")
+	format := fs.String("format", "", "Output format: html or txt (default: from the -o extension, else html)")
+	output := fs.String("o", "", "Write to this file instead of standard output")
+	files, code := parseSubcommandArgs(fs, args)
+	if code >= 0 {
+		return code
+	}
+	if len(files) != 1 {
+		fmt.Fprintln(stderr, "tuiwrite export: give exactly one file")
+		return exitUsage
+	}
+
+	if *format == "" {
+		*format = "html"
+		if ext := strings.TrimPrefix(filepath.Ext(*output), "."); ext != "" {
+			*format = ext
+		}
+	}
+	exporter, ok := exportFormats[strings.ToLower(*format)]
+	if !ok {
+		fmt.Fprintf(stderr, "tuiwrite export: unknown format %q (use html or txt)\n", *format)
+		return exitUsage
+	}
+
+	lines, err := readDocument(files[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "tuiwrite export: %v\n", err)
+		return exitFailure
+	}
+	text := exporter(lines, documentTitle(files[0], lines))
+
+	if *output == "" {
+		fmt.Fprint(stdout, text)
+		return exitOK
+	}
+	if err := os.WriteFile(*output, []byte(text), 0644); err != nil {
+		fmt.Fprintf(stderr, "tuiwrite export: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// runConvertCommand handles "tuiwrite convert [--check] [--crlf] [--keep-tabs] file..."
+func runConvertCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := newSubcommandFlags("convert", stderr, "[--check] [--crlf] [--keep-tabs] [--trim] file ...")
+	check := fs.Bool("check", false, "Only report files that need converting (exit code 1 if any do)")
+	crlf := fs.Bool("crlf", false, "Write Windows (CRLF) line endings instead of LF")
+	keepTabs := fs.Bool("keep-tabs", false, "Leave tabs alone instead of expanding them to tab_width spaces")
+	trim := fs.Bool("trim", false, "Remove trailing whitespace")
+	files, code := parseSubcommandArgs(fs, args)
+	if code >= 0 {
+		return code
+	}
+
+	status := exitOK
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "tuiwrite convert: %v\n", err)
+			status = exitFailure
+			continue
+		}
+		cfg, err := documentConfig(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "tuiwrite convert: config: %v\n", err)
+			return exitFailure
+		}
+
+		opts := normalizeOptions{crlf: *crlf, trimTrailing: *trim}
+		if !*keepTabs {
+			opts.tabWidth = cfg.TabWidth
+		}
+		converted, changes := normalizeText(data, opts)
+		if len(changes) == 0 {
+			continue
+		}
+
+		if *check {
+			fmt.Fprintf(stdout, "%s: %s\n", path, strings.Join(changes, ", "))
+			if status == exitOK {
+				status = exitFindings
+			}
+			continue
+		}
+		if err := os.WriteFile(path, converted, 0644); err != nil {
+			fmt.Fprintf(stderr, "tuiwrite convert: %v\n", err)
+			status = exitFailure
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: %s\n", path, strings.Join(changes, ", "))
+	}
+	return status
+}
+
+// jumpTo moves the cursor to a 1-based line and column, clamped to the document
+func (m *model) jumpTo(line int, col int) {
+	if line < 1 {
+		return
+	}
+	m.cursorY = line - 1
+	if m.cursorY >= len(m.lines) {
+		m.cursorY = len(m.lines) - 1
+	}
+	m.cursorX = 0
+	if col > 1 {
+		m.cursorX = col - 1
+		m.clampCursorX()
+	}
+	m.adjustViewport()
+}
+
+// openArgFile switches to another file from the command line
+func (m model) openArgFile(i int) (tea.Model, tea.Cmd) {
+	if len(m.argFiles) < 2 {
+		m.setStatus("Only one file was given on the command line", "yellow")
+		return m, nil
+	}
+	if i < 0 || i >= len(m.argFiles) {
+		m.setStatus(fmt.Sprintf("No more files (%d of %d)", m.argIndex+1, len(m.argFiles)), "yellow")
+		return m, nil
+	}
+	if m.modified && !m.readOnly {
+		m.setStatus("Unsaved changes (:w first)", "yellow")
+		return m, nil
+	}
+
+	target := m.argFiles[i]
+	result, cmd := m.openFileInCurrentInstance(target.path)
+	next, ok := result.(model)
+	if !ok {
+		return result, cmd
+	}
+	next.argIndex = i
+	next.jumpTo(target.line, target.col)
+	if next.statusMsg.Color == "green" {
+		next.setStatus(fmt.Sprintf("Opened %s (%d of %d)", filepath.Base(target.path), i+1, len(m.argFiles)), "green")
+	}
+	return next, cmd
+}
+
+// showArgFiles lists the command-line files in the info pane
+func (m *model) showArgFiles() {
+	if len(m.argFiles) == 0 {
+		m.setStatus("No files were given on the command line", "yellow")
+		return
+	}
+	var lines []string
+	for i, target := range m.argFiles {
+		marker := "  "
+		if i == m.argIndex {
+			marker = "* "
+		}
+		lines = append(lines, marker+target.path)
+	}
+	m.openInfoPane("Files (:next, :prev)", lines)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/client9/gospell"
+)
+
+func TestParseFileArgs(t *testing.T) {
+	dir := t.TempDir()
+	// A file whose name looks like a position is opened as-is
+	odd := filepath.Join(dir, "notes:12")
+	if err := os.WriteFile(odd, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseFileArgs([]string{"a.md", "+40", "b.md", "c.md:12", "d.md:3:7", odd, "e.md:x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []fileTarget{
+		{path: "a.md"},
+		{path: "b.md", line: 40},
+		{path: "c.md", line: 12},
+		{path: "d.md", line: 3, col: 7},
+		{path: odd},
+		{path: "e.md:x"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFileArgs = %+v\nwant %+v", got, want)
+	}
+
+	for _, bad := range [][]string{{"+0", "a.md"}, {"+x", "a.md"}, {"a.md", "+3"}} {
+		if _, err := parseFileArgs(bad); err == nil {
+			t.Errorf("parseFileArgs(%q) should fail", bad)
+		}
+	}
+}
+
+func TestJumpTo(t *testing.T) {
+	m := model{
+		lines:     []string{"one", "two", "three"},
+		wrapCache: make(map[int][]wrappedLine),
+		config:    defaultConfig(),
+		width:     80,
+		height:    20,
+	}
+	m.jumpTo(2, 3)
+	if m.cursorY != 1 || m.cursorX != 2 {
+		t.Errorf("jumpTo(2, 3) = %d:%d", m.cursorY, m.cursorX)
+	}
+	m.jumpTo(99, 99)
+	if m.cursorY != 2 || m.cursorX > len("three") {
+		t.Errorf("jumpTo should clamp, got %d:%d", m.cursorY, m.cursorX)
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	in := []byte("\xef\xbb\xbfline\tone  \r\nsecond\rthird")
+	got, changes := normalizeText(in, normalizeOptions{tabWidth: 4, trimTrailing: true})
+	if string(got) != "line    one\nsecond\nthird" {
+		t.Errorf("normalizeText = %q", got)
+	}
+	if len(changes) != 4 {
+		t.Errorf("changes = %q", changes)
+	}
+
+	got, changes = normalizeText([]byte("caf\xe9\n"), normalizeOptions{crlf: true})
+	if string(got) != "café\r\n" || len(changes) != 2 {
+		t.Errorf("Latin-1 to CRLF = %q, %q", got, changes)
+	}
+
+	if _, changes := normalizeText([]byte("clean\ntext\n"), normalizeOptions{tabWidth: 4}); len(changes) != 0 {
+		t.Errorf("clean text reported %q", changes)
+	}
+}
+
+func TestExport(t *testing.T) {
+	lines := []string{
+		"---",
+		"title: \"A <Story>\"",
+		"---",
+		"# One",
+		"",
+		"It was *dark* & [muy]{lang=es} **cold**.",
+		"",
+		"* * *",
+		"",
+		"> quoted",
+		"<!-- tuiwrite: spelllang=uk -->",
+	}
+	if got := documentTitle("draft.md", lines); got != "A <Story>" {
+		t.Errorf("title = %q", got)
+	}
+	if got := documentTitle("dir/draft.md", nil); got != "draft" {
+		t.Errorf("fallback title = %q", got)
+	}
+
+	out := exportHTML(lines, documentTitle("draft.md", lines))
+	for _, want := range []string{
+		"<title>A &lt;Story&gt;</title>",
+		"<h1>One</h1>",
+		"<p>It was <em>dark</em> &amp; muy <strong>cold</strong>.</p>",
+		"<hr>",
+		"<blockquote><p>quoted</p></blockquote>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "spelllang") || strings.Contains(out, "lang=") {
+		t.Errorf("HTML kept editor markup:\n%s", out)
+	}
+
+	text := exportText(lines, "")
+	if !strings.HasPrefix(text, "One\n\nIt was dark & muy cold.\n") {
+		t.Errorf("text export = %q", text)
+	}
+}
+
+func TestFindMisspellings(t *testing.T) {
+	checker, err := gospell.NewGoSpellReader(strings.NewReader("SET UTF-8\n"), strings.NewReader("3\nthe\ncat\nsat\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := newSpellChecker("uk")
+	sc.checkers["uk"] = checker
+	sc.languages = []string{"uk"}
+	sc.enabled = true
+
+	got := findMisspellings([]string{"the cat sat", "the dgo sat [perro]{lang=es}"}, sc)
+	want := []misspelling{{line: 1, col: 4, word: "dgo"}, {line: 1, col: 13, word: "perro", lang: "es"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findMisspellings = %+v, want %+v", got, want)
+	}
+}
+
+func TestSubcommandExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	clean := filepath.Join(dir, "clean.md")
+	dirty := filepath.Join(dir, "dirty.md")
+	if err := os.WriteFile(clean, []byte("Some words here.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dirty, []byte("Windows\r\nfile\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(name string, args ...string) (int, string) {
+		var stdout, stderr bytes.Buffer
+		code := subcommands[name].run(args, &stdout, &stderr)
+		return code, stdout.String()
+	}
+
+	if code, out := run("stats", clean); code != exitOK || !strings.Contains(out, "Words:             3") {
+		t.Errorf("stats = %d, %q", code, out)
+	}
+	if code, _ := run("stats", filepath.Join(dir, "missing.md")); code != exitFailure {
+		t.Errorf("stats on a missing file = %d", code)
+	}
+	if code, _ := run("stats"); code != exitUsage {
+		t.Errorf("stats without files = %d", code)
+	}
+	if code, _ := run("export", "--format", "pdf", clean); code != exitUsage {
+		t.Errorf("unknown export format = %d", code)
+	}
+
+	if code, out := run("convert", "--check", clean, dirty); code != exitFindings || !strings.Contains(out, "dirty.md") || strings.Contains(out, "clean.md") {
+		t.Errorf("convert --check = %d, %q", code, out)
+	}
+	if data, _ := os.ReadFile(dirty); !bytes.Contains(data, []byte("\r\n")) {
+		t.Error("convert --check should not write")
+	}
+	if code, _ := run("convert", dirty); code != exitOK {
+		t.Errorf("convert = %d", code)
+	}
+	if code, _ := run("convert", "--check", dirty); code != exitOK {
+		t.Errorf("a converted file should pass --check, got %d", code)
+	}
+}
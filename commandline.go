@@ -0,0 +1,557 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// commandHistoryFile keeps command-line history between sessions, in the config directory
+const commandHistoryFile = "command_history"
+
+// commandHistoryLimit is how many commands are remembered
+const commandHistoryLimit = 200
+
+// completionMenuRows is the most completions shown at once above the status bar
+const completionMenuRows = 8
+
+// commandInfo describes a command for completion and the command palette
+type commandInfo struct {
+	name        string
+	usage       string // arguments, e.g. "<file>"; required ones make the palette prefill the command line
+	description string
+}
+
+// commandList holds the commands offered by tab completion and the palette (aliases are left out)
+var commandList = []commandInfo{
+	{"args", "", "List the files given on the command line"},
+	{"delete", "[path]", "Move the selected file or folder to the trash"},
+	{"duplicate", "[name]", "Copy the selected file or folder"},
+	{"e", "<file>", "Open a file in this window"},
+	{"goal", "[daily|file] <words|off>", "Set a daily or per-file word goal"},
+	{"goals", "", "Show goal progress and the writing streak"},
+	{"help", "", "Open the command palette"},
+	{"keys", "[mode]", "List the key bindings"},
+	{"lint", "[on|off|list|rules|reload]", "Toggle prose linting or list its findings"},
+	{"map", "<mode> <keys> <action>", "Bind keys to an action for this session"},
+	{"mkdir", "<path>", "Create a folder in the project"},
+	{"mkfile", "<path>", "Create an empty file in the project"},
+	{"move", "<folder or path>", "Move the selected file or folder"},
+	{"new", "<file>", "Open a file in a new tmux/screen split"},
+	{"next", "", "Open the next command-line file"},
+	{"prev", "", "Open the previous command-line file"},
+	{"q", "", "Quit"},
+	{"put", "[register]", "Paste a register or the clipboard"},
+	{"registers", "", "List the registers and the kill ring"},
+	{"rename", "<name>", "Rename the selected file or folder"},
+	{"set", "[option=value]", "Show or change settings"},
+	{"source", "", "Reload the config files"},
+	{"spell", "[lang ...]", "Toggle spell-checking or choose languages"},
+	{"split", "<file>", "Open a file in a new top/bottom split"},
+	{"sprint", "[minutes|stop]", "Start or stop a writing sprint"},
+	{"stats", "", "Toggle the statistics panel"},
+	{"theme", "[name]", "List themes or switch theme"},
+	{"thesaurus", "", "Look up the word under the cursor"},
+	{"undelete", "", "Restore the most recently deleted file"},
+	{"unmap", "<mode> <keys>", "Remove a key binding for this session"},
+	{"vnew", "<file>", "Open a file in a new side-by-side split"},
+	{"w", "", "Save the file"},
+	{"wq", "", "Save and quit"},
+	{"zen", "[focus|typewriter]", "Toggle zen mode or its options"},
+}
+
+// commandNames returns the names of commandList
+func commandNames() []string {
+	names := make([]string, 0, len(commandList))
+	for _, c := range commandList {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// commandArguments lists fixed argument words for commands that take them
+var commandArguments = map[string][]string{
+	"lint":   {"list", "off", "on", "reload", "rules"},
+	"zen":    {"focus", "typewriter"},
+	"sprint": {"cancel", "stop"},
+}
+
+// commandHistoryPath returns where command history is stored
+func commandHistoryPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, commandHistoryFile), nil
+}
+
+// loadCommandHistory reads the saved history, oldest first
+func loadCommandHistory() []string {
+	path, err := commandHistoryPath()
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var history []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			history = append(history, line)
+		}
+	}
+	return history
+}
+
+// saveCommandHistory writes the history, keeping the newest entries
+func saveCommandHistory(history []string) error {
+	path, err := commandHistoryPath()
+	if err != nil {
+		return err
+	}
+	if len(history) > commandHistoryLimit {
+		history = history[len(history)-commandHistoryLimit:]
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0644)
+}
+
+// addCommandHistory records a run command; repeats move to the newest position
+func (m *model) addCommandHistory(cmd string) {
+	if strings.TrimSpace(cmd) == "" {
+		return
+	}
+	history := make([]string, 0, len(m.commandHistory)+1)
+	for _, entry := range m.commandHistory {
+		if entry != cmd {
+			history = append(history, entry)
+		}
+	}
+	m.commandHistory = append(history, cmd)
+	if len(m.commandHistory) > commandHistoryLimit {
+		m.commandHistory = m.commandHistory[len(m.commandHistory)-commandHistoryLimit:]
+	}
+	if err := saveCommandHistory(m.commandHistory); err != nil {
+		LogWarningf("Failed to save command history: %v", err)
+	}
+}
+
+// browseCommandHistory steps through history entries starting with what was
+// typed before browsing began (-1 older, +1 newer)
+func (m *model) browseCommandHistory(step int) {
+	if m.historyIndex < 0 {
+		m.historyIndex = len(m.commandHistory)
+		m.historyDraft = m.commandBuffer[1:]
+	}
+	for i := m.historyIndex + step; i >= 0 && i <= len(m.commandHistory); i += step {
+		if i == len(m.commandHistory) {
+			m.historyIndex = i
+			m.setCommandText(m.historyDraft)
+			return
+		}
+		if strings.HasPrefix(m.commandHistory[i], m.historyDraft) {
+			m.historyIndex = i
+			m.setCommandText(m.commandHistory[i])
+			return
+		}
+	}
+}
+
+// setCommandText replaces the command line (after the ":") and moves to its end
+func (m *model) setCommandText(text string) {
+	m.commandBuffer = ":" + text
+	m.commandCursor = len(m.commandBuffer)
+	m.closeCompletions()
+}
+
+// commandEdited forgets history browsing and completions after a change
+func (m *model) commandEdited() {
+	m.historyIndex = -1
+	m.closeCompletions()
+}
+
+// clampCommandCursor keeps the cursor after the ":" and inside the buffer
+func (m *model) clampCommandCursor() {
+	if m.commandCursor < 1 || m.commandCursor > len(m.commandBuffer) {
+		m.commandCursor = len(m.commandBuffer)
+	}
+}
+
+// insertCommandText types text at the command-line cursor
+func (m *model) insertCommandText(text string) {
+	m.clampCommandCursor()
+	m.commandBuffer = m.commandBuffer[:m.commandCursor] + text + m.commandBuffer[m.commandCursor:]
+	m.commandCursor += len(text)
+	m.commandEdited()
+}
+
+// moveCommandCursor moves the cursor one character left (-1) or right (+1)
+func (m *model) moveCommandCursor(step int) {
+	m.clampCommandCursor()
+	if step < 0 && m.commandCursor > 1 {
+		_, size := utf8.DecodeLastRuneInString(m.commandBuffer[:m.commandCursor])
+		m.commandCursor -= size
+	} else if step > 0 && m.commandCursor < len(m.commandBuffer) {
+		_, size := utf8.DecodeRuneInString(m.commandBuffer[m.commandCursor:])
+		m.commandCursor += size
+	}
+	m.closeCompletions()
+}
+
+// commandWordStart finds the start of the word before the cursor
+func (m model) commandWordStart() int {
+	i := m.commandCursor
+	for i > 1 {
+		r, size := utf8.DecodeLastRuneInString(m.commandBuffer[:i])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		i -= size
+	}
+	for i > 1 {
+		r, size := utf8.DecodeLastRuneInString(m.commandBuffer[:i])
+		if unicode.IsSpace(r) || r == '/' && i != m.commandCursor {
+			break
+		}
+		i -= size
+	}
+	return i
+}
+
+// commandWordEnd finds the end of the word after the cursor
+func (m model) commandWordEnd() int {
+	i := m.commandCursor
+	for i < len(m.commandBuffer) && m.commandBuffer[i] == ' ' {
+		i++
+	}
+	for i < len(m.commandBuffer) && m.commandBuffer[i] != ' ' {
+		i++
+	}
+	return i
+}
+
+// moveCommandWord moves the cursor a word left (-1) or right (+1)
+func (m *model) moveCommandWord(step int) {
+	m.clampCommandCursor()
+	if step < 0 {
+		m.commandCursor = m.commandWordStart()
+	} else {
+		m.commandCursor = m.commandWordEnd()
+	}
+	m.closeCompletions()
+}
+
+// moveCommandCursorTo jumps to the start (after the ":") or the end of the line
+func (m *model) moveCommandCursorTo(end bool) {
+	m.commandCursor = 1
+	if end {
+		m.commandCursor = len(m.commandBuffer)
+	}
+	m.closeCompletions()
+}
+
+// deleteCommandRange removes buffer[from:to] and leaves the cursor at from
+func (m *model) deleteCommandRange(from int, to int) {
+	if from >= to {
+		return
+	}
+	m.commandBuffer = m.commandBuffer[:from] + m.commandBuffer[to:]
+	m.commandCursor = from
+	m.commandEdited()
+}
+
+// commandDeleteWord deletes the word before the cursor (ctrl+w)
+func (m *model) commandDeleteWord() {
+	m.clampCommandCursor()
+	m.deleteCommandRange(m.commandWordStart(), m.commandCursor)
+}
+
+// commandDeleteToStart deletes everything before the cursor (ctrl+u)
+func (m *model) commandDeleteToStart() {
+	m.clampCommandCursor()
+	m.deleteCommandRange(1, m.commandCursor)
+}
+
+// commandDeleteForward deletes the character under the cursor
+func (m *model) commandDeleteForward() {
+	m.clampCommandCursor()
+	if m.commandCursor < len(m.commandBuffer) {
+		_, size := utf8.DecodeRuneInString(m.commandBuffer[m.commandCursor:])
+		m.deleteCommandRange(m.commandCursor, m.commandCursor+size)
+	}
+}
+
+// commandCompletions lists candidates for the word ending at the cursor and
+// where that word starts in the buffer
+func (m model) commandCompletions() ([]string, int) {
+	text := m.commandBuffer[1:m.commandCursor]
+	start := strings.LastIndexAny(text, " ") + 1
+	word := text[start:]
+	args := strings.Fields(text[:start])
+	start++ // account for the ":"
+
+	if len(args) == 0 {
+		return prefixMatches(commandNames(), word), start
+	}
+
+	switch cmd, argIndex := args[0], len(args)-1; cmd {
+	case "e", "edit", "open", "new", "vnew", "split":
+		dir, file := filepath.Split(word)
+		return completePath(dir, file), start + len(dir)
+	case "mkfile", "mkdir", "move", "delete":
+		// File commands take paths relative to the project folder
+		dir, file := filepath.Split(word)
+		return completePath(m.resolveProjectPath(dir), file), start + len(dir)
+	case "spell", "spellcheck":
+		return prefixMatches(availableDictNames(), word), start
+	case "theme":
+		if argIndex == 0 {
+			return prefixMatches(availableThemes(), word), start
+		}
+	case "set":
+		if name, value, found := strings.Cut(word, "="); found {
+			return prefixMatches(optionValues(name), value), start + len(name) + 1
+		}
+		return prefixMatches(configOptionNames(), word), start
+	case "keys":
+		if argIndex == 0 {
+			return prefixMatches(keymapModes, word), start
+		}
+	case "map", "unmap":
+		if argIndex == 0 {
+			return prefixMatches(keymapModes, word), start
+		}
+		if cmd == "map" && argIndex >= 1 {
+			return prefixMatches(keyActionNames(), word), start
+		}
+	default:
+		if argIndex == 0 {
+			return prefixMatches(commandArguments[cmd], word), start
+		}
+	}
+	return nil, start
+}
+
+// prefixMatches returns the sorted candidates starting with prefix
+func prefixMatches(candidates []string, prefix string) []string {
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			matches = append(matches, c)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// completePath lists entries of dir (relative to the working directory)
+// starting with prefix. Directories end in "/"; hidden entries are only
+// offered when the prefix starts with ".".
+func completePath(dir string, prefix string) []string {
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	} else if strings.HasPrefix(readDir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			readDir = filepath.Join(home, readDir[2:])
+		}
+	}
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		matches = append(matches, name)
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// availableDictNames returns the spell-check language codes
+func availableDictNames() []string {
+	names := make([]string, 0, len(availableDicts))
+	for name := range availableDicts {
+		names = append(names, name)
+	}
+	return names
+}
+
+// configOptionNames returns the :set option names
+func configOptionNames() []string {
+	names := make([]string, 0, len(configOptions))
+	for _, opt := range configOptions {
+		names = append(names, opt.name)
+	}
+	return names
+}
+
+// optionValues suggests values for ":set name=" where the choices are known
+func optionValues(name string) []string {
+	switch name {
+	case "theme", "theme_light", "theme_dark":
+		return append([]string{"auto"}, availableThemes()...)
+	case "keymap":
+		return keymapPresetNames()
+	case "spell_language":
+		return availableDictNames()
+	case "clipboard":
+		return clipboardModes
+	case "zen_typewriter", "sprint_block_quit":
+		return []string{"false", "true"}
+	}
+	return nil
+}
+
+// completeCommand handles tab: a single match is inserted, several insert
+// their common prefix and open the menu, and further tabs cycle the menu
+func (m *model) completeCommand(step int) {
+	m.clampCommandCursor()
+	if len(m.completions) > 0 {
+		m.cycleCompletion(step)
+		return
+	}
+
+	items, start := m.commandCompletions()
+	word := m.commandBuffer[start:m.commandCursor]
+	switch len(items) {
+	case 0:
+		return
+	case 1:
+		completed := items[0]
+		if !strings.HasSuffix(completed, "/") && m.commandCursor == len(m.commandBuffer) {
+			completed += " "
+		}
+		m.replaceCommandWord(start, completed)
+		m.historyIndex = -1
+		return
+	}
+
+	if common := commonPrefix(items); len(common) > len(word) {
+		m.replaceCommandWord(start, common)
+	}
+	m.completions = items
+	m.completionIndex = -1
+	m.completionStart = start
+}
+
+// cycleCompletion selects the next (+1) or previous (-1) item in the menu
+func (m *model) cycleCompletion(step int) {
+	n := len(m.completions)
+	if m.completionIndex < 0 && step < 0 {
+		m.completionIndex = n - 1
+	} else {
+		m.completionIndex = ((m.completionIndex+step)%n + n) % n
+	}
+	m.replaceCommandWord(m.completionStart, m.completions[m.completionIndex])
+}
+
+// replaceCommandWord swaps the text from start to the cursor for word
+func (m *model) replaceCommandWord(start int, word string) {
+	m.commandBuffer = m.commandBuffer[:start] + word + m.commandBuffer[m.commandCursor:]
+	m.commandCursor = start + len(word)
+}
+
+// closeCompletions hides the completion menu
+func (m *model) closeCompletions() {
+	m.completions = nil
+	m.completionIndex = -1
+}
+
+// commonPrefix returns the longest prefix shared by every item
+func commonPrefix(items []string) string {
+	prefix := items[0]
+	for _, item := range items[1:] {
+		for !strings.HasPrefix(item, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// completionMenuHeight returns the rows taken by the completion menu (0 when closed)
+func (m model) completionMenuHeight() int {
+	if !m.commandMode || len(m.completions) == 0 {
+		return 0
+	}
+	return min(len(m.completions), completionMenuRows)
+}
+
+// renderCompletionMenu lists completions above the status bar, keeping the selection in view
+func (m model) renderCompletionMenu() string {
+	itemStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.bg")).
+		Foreground(m.themeColor("pane.fg")).
+		Width(m.width)
+	selectedStyle := itemStyle.
+		Background(m.themeColor("pane.selected.bg")).
+		Foreground(m.themeColor("pane.selected.fg")).
+		Reverse(monochrome())
+
+	rows := m.completionMenuHeight()
+	offset := 0
+	if m.completionIndex >= rows {
+		offset = m.completionIndex - rows + 1
+	}
+
+	var lines []string
+	for i := offset; i < offset+rows; i++ {
+		text := truncateRunes(" "+m.completions[i], m.width)
+		if i == m.completionIndex {
+			lines = append(lines, selectedStyle.Render(text))
+		} else {
+			lines = append(lines, itemStyle.Render(text))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderCommandLine shows the command buffer with the cursor across the full width.
+// The pieces are styled separately so the cursor's reset doesn't clear the background.
+func (m model) renderCommandLine(style lipgloss.Style) string {
+	cursor := m.commandCursor
+	if cursor < 1 || cursor > len(m.commandBuffer) {
+		cursor = len(m.commandBuffer)
+	}
+	cursorStyle := lipgloss.NewStyle().
+		Background(m.themeColor("cursor.bg")).
+		Foreground(m.themeColor("cursor.fg")).
+		Reverse(monochrome())
+
+	under := " "
+	rest := ""
+	if cursor < len(m.commandBuffer) {
+		_, size := utf8.DecodeRuneInString(m.commandBuffer[cursor:])
+		under = m.commandBuffer[cursor : cursor+size]
+		rest = m.commandBuffer[cursor+size:]
+	}
+
+	before := m.commandBuffer[:cursor]
+	padding := m.width - lipgloss.Width(before) - lipgloss.Width(under) - lipgloss.Width(rest)
+	if padding < 0 {
+		padding = 0
+	}
+	plain := style.UnsetWidth()
+	return plain.Render(before) + cursorStyle.Render(under) + plain.Render(rest+strings.Repeat(" ", padding))
+}
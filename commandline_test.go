@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newCommandLineTestModel opens the command line with history kept in a temporary HOME
+func newCommandLineTestModel(t *testing.T) model {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("config directory is only redirected through HOME on Linux")
+	}
+	t.Setenv("HOME", t.TempDir())
+	m := newKeymapTestModel(t, "default")
+	return pressKeys(t, m, ":")
+}
+
+// pressSpecial feeds non-rune keys such as arrows and tab
+func pressSpecial(t *testing.T, m model, keys ...tea.KeyType) model {
+	t.Helper()
+	for _, k := range keys {
+		result, _ := m.handleKeyPress(tea.KeyMsg{Type: k})
+		m = result.(model)
+	}
+	return m
+}
+
+func TestCommandLineEditing(t *testing.T) {
+	m := newCommandLineTestModel(t)
+	m = pressKeys(t, m, "s", "e", "t", " ", "t", "a", "b")
+	m = pressSpecial(t, m, tea.KeyLeft, tea.KeyLeft, tea.KeyLeft)
+	m = pressKeys(t, m, "x")
+	if m.commandBuffer != ":set xtab" || m.commandCursor != 6 {
+		t.Errorf("insert at cursor: %q cursor %d", m.commandBuffer, m.commandCursor)
+	}
+
+	m = pressSpecial(t, m, tea.KeyDelete, tea.KeyBackspace)
+	if m.commandBuffer != ":set ab" {
+		t.Errorf("delete and backspace: %q", m.commandBuffer)
+	}
+
+	m = pressSpecial(t, m, tea.KeyEnd, tea.KeyCtrlW)
+	if m.commandBuffer != ":set " {
+		t.Errorf("ctrl+w: %q", m.commandBuffer)
+	}
+	m = pressKeys(t, m, "a", "b")
+	m = pressSpecial(t, m, tea.KeyHome)
+	m = pressKeys(t, m, "x")
+	m = pressSpecial(t, m, tea.KeyRight, tea.KeyRight, tea.KeyCtrlU)
+	if m.commandBuffer != ":t ab" || m.commandCursor != 1 {
+		t.Errorf("ctrl+u: %q cursor %d", m.commandBuffer, m.commandCursor)
+	}
+}
+
+func TestCommandHistory(t *testing.T) {
+	m := newCommandLineTestModel(t)
+	for _, cmd := range []string{"set tab_width=2", "stats", "set tree_width=30", "stats"} {
+		m.commandBuffer = ":" + cmd
+		result, _ := m.submitCommandLine()
+		m = result.(model)
+		m.openCommandLine()
+	}
+	if want := []string{"set tab_width=2", "set tree_width=30", "stats"}; !reflect.DeepEqual(m.commandHistory, want) {
+		t.Fatalf("history = %q, want %q", m.commandHistory, want)
+	}
+
+	m = pressSpecial(t, m, tea.KeyUp)
+	if m.commandBuffer != ":stats" {
+		t.Errorf("up: %q", m.commandBuffer)
+	}
+	m = pressSpecial(t, m, tea.KeyDown)
+	if m.commandBuffer != ":" {
+		t.Errorf("down past the newest entry should restore the draft: %q", m.commandBuffer)
+	}
+
+	// Browsing only shows entries starting with what was typed
+	m = pressKeys(t, m, "s", "e")
+	m = pressSpecial(t, m, tea.KeyUp, tea.KeyUp, tea.KeyUp)
+	if m.commandBuffer != ":set tab_width=2" {
+		t.Errorf("prefix history: %q", m.commandBuffer)
+	}
+
+	// A new session reads the saved history
+	fresh := newKeymapTestModel(t, "default")
+	fresh = pressKeys(t, fresh, ":")
+	if !reflect.DeepEqual(fresh.commandHistory, m.commandHistory) {
+		t.Errorf("saved history = %q", fresh.commandHistory)
+	}
+}
+
+func TestCommandCompletion(t *testing.T) {
+	m := newCommandLineTestModel(t)
+
+	m = pressKeys(t, m, "t", "h")
+	m = pressSpecial(t, m, tea.KeyTab)
+	if m.commandBuffer != ":the" || !reflect.DeepEqual(m.completions, []string{"theme", "thesaurus"}) {
+		t.Fatalf("common prefix: %q, menu %q", m.commandBuffer, m.completions)
+	}
+	if m.completionMenuHeight() != 2 {
+		t.Errorf("menu height = %d", m.completionMenuHeight())
+	}
+	m = pressSpecial(t, m, tea.KeyTab, tea.KeyTab)
+	if m.commandBuffer != ":thesaurus" {
+		t.Errorf("cycling: %q", m.commandBuffer)
+	}
+	m = pressSpecial(t, m, tea.KeyShiftTab)
+	if m.commandBuffer != ":theme" {
+		t.Errorf("shift+tab: %q", m.commandBuffer)
+	}
+	m = pressSpecial(t, m, tea.KeyEsc)
+	if !m.commandMode || len(m.completions) != 0 {
+		t.Error("esc should close the menu but keep the command line")
+	}
+
+	m = pressKeys(t, m, " ", "s", "o", "l", "a", "r", "i", "z", "e", "d", "-", "l")
+	m = pressSpecial(t, m, tea.KeyTab)
+	if m.commandBuffer != ":theme solarized-light " {
+		t.Errorf("theme name: %q", m.commandBuffer)
+	}
+
+	m.setCommandText("set zen_t")
+	m = pressSpecial(t, m, tea.KeyTab)
+	if m.commandBuffer != ":set zen_typewriter " {
+		t.Errorf("option name: %q", m.commandBuffer)
+	}
+	m.setCommandText("set keymap=em")
+	m = pressSpecial(t, m, tea.KeyTab)
+	if m.commandBuffer != ":set keymap=emacs " {
+		t.Errorf("option value: %q", m.commandBuffer)
+	}
+	m.setCommandText("spell u")
+	m = pressSpecial(t, m, tea.KeyTab)
+	if !reflect.DeepEqual(m.completions, []string{"uk", "us"}) {
+		t.Errorf("languages: %q", m.completions)
+	}
+}
+
+func TestCommandPathCompletion(t *testing.T) {
+	m := newCommandLineTestModel(t)
+	dir := t.TempDir()
+	for _, name := range []string{"chapter-one.md", "chapter-two.md", ".hidden.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m.setCommandText("e " + dir + "/n")
+	m = pressSpecial(t, m, tea.KeyTab)
+	if m.commandBuffer != ":e "+dir+"/notes/" {
+		t.Errorf("directory: %q", m.commandBuffer)
+	}
+
+	m.setCommandText("e " + dir + "/")
+	m = pressSpecial(t, m, tea.KeyTab)
+	if m.commandBuffer != ":e "+dir+"/" || !reflect.DeepEqual(m.completions, []string{"chapter-one.md", "chapter-two.md", "notes/"}) {
+		t.Errorf("listing: %q, menu %q", m.commandBuffer, m.completions)
+	}
+
+	m.setCommandText("e " + dir + "/ch")
+	m = pressSpecial(t, m, tea.KeyTab)
+	if m.commandBuffer != ":e "+dir+"/chapter-" {
+		t.Errorf("common prefix: %q", m.commandBuffer)
+	}
+	m = pressSpecial(t, m, tea.KeyTab)
+	if m.commandBuffer != ":e "+dir+"/chapter-one.md" {
+		t.Errorf("first file: %q", m.commandBuffer)
+	}
+}
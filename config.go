@@ -0,0 +1,446 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Config file names: the user config lives in getConfigDir(), the project
+// override in the file tree root
+const (
+	userConfigFile    = "config.toml"
+	projectConfigFile = ".tuiwrite.toml"
+)
+
+// configCheckInterval is how often config files are checked for changes
+const configCheckInterval = 2 * time.Second
+
+// configDuration is a time.Duration written as a string ("30m", "500ms") in TOML
+type configDuration struct {
+	time.Duration
+}
+
+// UnmarshalText parses a duration string
+func (d *configDuration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q (try \"30m\" or \"500ms\")", string(text))
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalText formats the duration as a string
+func (d configDuration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
+
+// Config holds the user-adjustable settings
+type Config struct {
+	AutoSaveInterval configDuration               `toml:"autosave_interval"` // 0 disables auto-save
+	CursorBlink      configDuration               `toml:"cursor_blink"`      // 0 disables blinking
+	TabWidth         int                          `toml:"tab_width"`         // spaces inserted by Tab
+	TreeWidth        int                          `toml:"tree_width"`        // file tree sidebar width
+	SpellLanguage    string                       `toml:"spell_language"`    // default spell-check language
+	ExcludeDirs      []string                     `toml:"exclude_dirs"`      // directories hidden from the file tree
+	TreeMaxEntries   int                          `toml:"tree_max_entries"`  // entries the file tree lists per folder
+	TreeInclude      []string                     `toml:"tree_include"`      // file patterns the tree shows besides text files
+	TreeShowHidden   bool                         `toml:"tree_show_hidden"`  // show dotfiles in the file tree
+	TreeColumns      []string                     `toml:"tree_columns"`      // details shown after tree names: words, modified, git
+	TreeSort         string                       `toml:"tree_sort"`         // name, modified or manual
+	ZenMeasure       int                          `toml:"zen_measure"`       // text column width in zen mode
+	ZenFocus         string                       `toml:"zen_focus"`         // paragraph, sentence or off
+	ZenTypewriter    bool                         `toml:"zen_typewriter"`    // keep the cursor line centered in zen mode
+	SprintBlockQuit  bool                         `toml:"sprint_block_quit"` // refuse to quit during a sprint
+	Clipboard        string                       `toml:"clipboard"`         // auto, terminal or internal
+	Keymap           string                       `toml:"keymap"`            // key binding preset
+	Theme            string                       `toml:"theme"`             // color theme, or "auto"
+	ThemeLight       string                       `toml:"theme_light"`       // theme "auto" uses on light terminals
+	ThemeDark        string                       `toml:"theme_dark"`        // theme "auto" uses on dark terminals
+	Keys             map[string]map[string]string `toml:"keys"`              // per-mode bindings layered over the preset
+}
+
+// defaultConfig returns the built-in settings
+func defaultConfig() Config {
+	return Config{
+		AutoSaveInterval: configDuration{30 * time.Minute},
+		CursorBlink:      configDuration{500 * time.Millisecond},
+		TabWidth:         4,
+		TreeWidth:        30,
+		SpellLanguage:    "uk",
+		ExcludeDirs:      []string{"node_modules", "vendor"},
+		TreeMaxEntries:   1000,
+		TreeSort:         treeSortName,
+		ZenMeasure:       defaultZenMeasure,
+		ZenFocus:         zenFocusParagraph,
+		ZenTypewriter:    true,
+		SprintBlockQuit:  true,
+		Clipboard:        clipboardAuto,
+		Keymap:           defaultKeymapPreset,
+		Theme:            themeAuto,
+		ThemeLight:       "latte",
+		ThemeDark:        defaultTheme,
+	}
+}
+
+// validate checks that every setting is in range
+func (c Config) validate() error {
+	if d := c.AutoSaveInterval.Duration; d < 0 || (d > 0 && d < 10*time.Second) {
+		return fmt.Errorf("autosave_interval must be 0 (off) or at least 10s")
+	}
+	if d := c.CursorBlink.Duration; d < 0 || (d > 0 && d < 100*time.Millisecond) {
+		return fmt.Errorf("cursor_blink must be 0 (off) or at least 100ms")
+	}
+	if c.TabWidth < 1 || c.TabWidth > 16 {
+		return fmt.Errorf("tab_width must be between 1 and 16")
+	}
+	if c.TreeWidth < 10 || c.TreeWidth > 80 {
+		return fmt.Errorf("tree_width must be between 10 and 80")
+	}
+	if normalizeLangCode(c.SpellLanguage) == "" {
+		return fmt.Errorf("spell_language %q is not a supported language", c.SpellLanguage)
+	}
+	for _, dir := range c.ExcludeDirs {
+		if dir == "" || strings.ContainsAny(dir, `/\`) {
+			return fmt.Errorf("exclude_dirs entries must be plain directory names, got %q", dir)
+		}
+	}
+	if c.TreeMaxEntries < 10 {
+		return fmt.Errorf("tree_max_entries must be at least 10")
+	}
+	for _, pattern := range c.TreeInclude {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("tree_include has an invalid pattern %q", pattern)
+		}
+	}
+	for _, column := range c.TreeColumns {
+		if !containsString(treeColumns, column) {
+			return fmt.Errorf("tree_columns entries must be %s, got %q", strings.Join(treeColumns, ", "), column)
+		}
+	}
+	if !containsString(treeSortModes, c.TreeSort) {
+		return fmt.Errorf("tree_sort must be name, modified or manual")
+	}
+	if c.ZenMeasure < 20 {
+		return fmt.Errorf("zen_measure must be at least 20")
+	}
+	if c.ZenFocus != zenFocusParagraph && c.ZenFocus != zenFocusSentence && c.ZenFocus != zenFocusOff {
+		return fmt.Errorf("zen_focus must be paragraph, sentence or off")
+	}
+	if !containsString(clipboardModes, c.Clipboard) {
+		return fmt.Errorf("clipboard must be auto, terminal or internal")
+	}
+	if _, err := newKeymap(c.Keymap, c.Keys); err != nil {
+		return err
+	}
+	themes := []string{c.ThemeLight, c.ThemeDark}
+	if c.Theme != themeAuto {
+		themes = append(themes, c.Theme)
+	}
+	for _, name := range themes {
+		if _, err := loadTheme(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalize canonicalises settings with several spellings
+func (c *Config) normalize() {
+	if lang := normalizeLangCode(c.SpellLanguage); lang != "" {
+		c.SpellLanguage = lang
+	}
+}
+
+// decodeConfigFile layers the settings present in a TOML file onto cfg.
+// Missing files are not an error.
+func decodeConfigFile(path string, cfg *Config) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	md, err := toml.DecodeFile(path, cfg)
+	if err != nil {
+		return fmt.Errorf("%s: %v", filepath.Base(path), err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("%s: unknown option %q", filepath.Base(path), undecoded[0].String())
+	}
+	return nil
+}
+
+// userConfigOverride replaces the user config file when set (--config)
+var userConfigOverride string
+
+// configPaths returns the user and project config files, in the order they apply
+func configPaths(projectDir string) []string {
+	var paths []string
+	if userConfigOverride != "" {
+		paths = append(paths, userConfigOverride)
+	} else if configDir, err := getConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(configDir, userConfigFile))
+	}
+	if projectDir != "" {
+		paths = append(paths, filepath.Join(projectDir, projectConfigFile))
+	}
+	return paths
+}
+
+// loadConfig reads the defaults, then each file in paths, and validates the result
+func loadConfig(paths []string) (Config, error) {
+	cfg := defaultConfig()
+	for _, path := range paths {
+		if err := decodeConfigFile(path, &cfg); err != nil {
+			return defaultConfig(), err
+		}
+	}
+	cfg.normalize()
+	if err := cfg.validate(); err != nil {
+		return defaultConfig(), err
+	}
+	return cfg, nil
+}
+
+// configStamp summarises the modification times of the config files, so
+// that a change to any of them (including creation or deletion) is noticed
+func configStamp(paths []string) string {
+	var sb strings.Builder
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			sb.WriteString(info.ModTime().String())
+		}
+		sb.WriteString("|")
+	}
+	return sb.String()
+}
+
+// configOption describes a setting that can be changed with :set
+type configOption struct {
+	name string
+	get  func(c Config) string
+	set  func(c *Config, value string) error
+}
+
+// configOptions lists the settings in the order :set shows them
+var configOptions = []configOption{
+	{"autosave_interval", func(c Config) string { return c.AutoSaveInterval.String() }, func(c *Config, v string) error { return c.AutoSaveInterval.UnmarshalText([]byte(v)) }},
+	{"cursor_blink", func(c Config) string { return c.CursorBlink.String() }, func(c *Config, v string) error { return c.CursorBlink.UnmarshalText([]byte(v)) }},
+	{"tab_width", func(c Config) string { return strconv.Itoa(c.TabWidth) }, func(c *Config, v string) error { return setIntOption(&c.TabWidth, v) }},
+	{"tree_width", func(c Config) string { return strconv.Itoa(c.TreeWidth) }, func(c *Config, v string) error { return setIntOption(&c.TreeWidth, v) }},
+	{"spell_language", func(c Config) string { return c.SpellLanguage }, func(c *Config, v string) error { c.SpellLanguage = strings.ToLower(v); return nil }},
+	{"exclude_dirs", func(c Config) string { return strings.Join(c.ExcludeDirs, ",") }, func(c *Config, v string) error { c.ExcludeDirs = parseListOption(v); return nil }},
+	{"tree_max_entries", func(c Config) string { return strconv.Itoa(c.TreeMaxEntries) }, func(c *Config, v string) error { return setIntOption(&c.TreeMaxEntries, v) }},
+	{"tree_include", func(c Config) string { return strings.Join(c.TreeInclude, ",") }, func(c *Config, v string) error { c.TreeInclude = parseListOption(v); return nil }},
+	{"tree_show_hidden", func(c Config) string { return strconv.FormatBool(c.TreeShowHidden) }, func(c *Config, v string) error { return setBoolOption(&c.TreeShowHidden, v) }},
+	{"tree_columns", func(c Config) string { return strings.Join(c.TreeColumns, ",") }, func(c *Config, v string) error { c.TreeColumns = parseListOption(v); return nil }},
+	{"tree_sort", func(c Config) string { return c.TreeSort }, func(c *Config, v string) error { c.TreeSort = strings.ToLower(v); return nil }},
+	{"zen_measure", func(c Config) string { return strconv.Itoa(c.ZenMeasure) }, func(c *Config, v string) error { return setIntOption(&c.ZenMeasure, v) }},
+	{"zen_focus", func(c Config) string { return c.ZenFocus }, func(c *Config, v string) error { c.ZenFocus = v; return nil }},
+	{"zen_typewriter", func(c Config) string { return strconv.FormatBool(c.ZenTypewriter) }, func(c *Config, v string) error { return setBoolOption(&c.ZenTypewriter, v) }},
+	{"sprint_block_quit", func(c Config) string { return strconv.FormatBool(c.SprintBlockQuit) }, func(c *Config, v string) error { return setBoolOption(&c.SprintBlockQuit, v) }},
+	{"clipboard", func(c Config) string { return c.Clipboard }, func(c *Config, v string) error { c.Clipboard = strings.ToLower(v); return nil }},
+	{"keymap", func(c Config) string { return c.Keymap }, func(c *Config, v string) error { c.Keymap = strings.ToLower(v); return nil }},
+	{"theme", func(c Config) string { return c.Theme }, func(c *Config, v string) error { c.Theme = strings.ToLower(v); return nil }},
+	{"theme_light", func(c Config) string { return c.ThemeLight }, func(c *Config, v string) error { c.ThemeLight = strings.ToLower(v); return nil }},
+	{"theme_dark", func(c Config) string { return c.ThemeDark }, func(c *Config, v string) error { c.ThemeDark = strings.ToLower(v); return nil }},
+}
+
+// findConfigOption looks up a :set option by name
+func findConfigOption(name string) (configOption, bool) {
+	for _, opt := range configOptions {
+		if opt.name == name {
+			return opt, true
+		}
+	}
+	return configOption{}, false
+}
+
+// setIntOption parses an integer setting
+func setIntOption(target *int, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", value)
+	}
+	*target = n
+	return nil
+}
+
+// setBoolOption parses a boolean setting (true/false, on/off, yes/no)
+func setBoolOption(target *bool, value string) error {
+	switch strings.ToLower(value) {
+	case "true", "on", "yes", "1":
+		*target = true
+	case "false", "off", "no", "0":
+		*target = false
+	default:
+		return fmt.Errorf("%q is not true or false", value)
+	}
+	return nil
+}
+
+// parseListOption splits a comma-separated list, dropping empty entries
+func parseListOption(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// configCheckMsg is sent periodically to look for config file changes
+type configCheckMsg time.Time
+
+// tickConfigCheck returns a command that sends configCheckMsg after configCheckInterval
+func tickConfigCheck() tea.Cmd {
+	return tea.Tick(configCheckInterval, func(t time.Time) tea.Msg {
+		return configCheckMsg(t)
+	})
+}
+
+// reloadConfig reads the config files again. On error the current settings
+// are kept and the error is returned for the status bar.
+func (m *model) reloadConfig() (tea.Cmd, error) {
+	paths := configPaths(m.projectDir())
+	m.configStamp = configStamp(paths)
+
+	cfg, err := loadConfig(paths)
+	if err != nil {
+		LogWarningf("Config error: %v", err)
+		return nil, err
+	}
+	LogInfof("Loaded config from %s", strings.Join(paths, ", "))
+	return m.applyConfig(cfg), nil
+}
+
+// applyConfig switches to new settings, updating whatever depends on them
+func (m *model) applyConfig(cfg Config) tea.Cmd {
+	old := m.config
+	m.config = cfg
+
+	var cmds []tea.Cmd
+
+	// Restart timers that had stopped because they were turned off
+	if cfg.AutoSaveInterval.Duration > 0 && !m.autoSaveRunning {
+		m.autoSaveRunning = true
+		cmds = append(cmds, tickAutoSave(cfg.AutoSaveInterval.Duration))
+	}
+	if cfg.CursorBlink.Duration > 0 && !m.cursorBlinkRunning {
+		m.cursorBlinkRunning = true
+		cmds = append(cmds, tickCursorBlink(cfg.CursorBlink.Duration))
+	}
+	if cfg.CursorBlink.Duration == 0 {
+		m.cursorVisible = true
+	}
+
+	// A new default language only applies where the document and project don't
+	// choose one
+	if m.spellChecker != nil && cfg.SpellLanguage != old.SpellLanguage {
+		if missing := m.spellChecker.useLocalLanguages([]string{cfg.SpellLanguage}); len(missing) > 0 {
+			LogWarningf("Default spell language %s is not installed", cfg.SpellLanguage)
+		}
+		m.applyDocumentLanguages()
+	}
+
+	treeChanged := strings.Join(cfg.ExcludeDirs, ",") != strings.Join(old.ExcludeDirs, ",") ||
+		strings.Join(cfg.TreeInclude, ",") != strings.Join(old.TreeInclude, ",") ||
+		cfg.TreeShowHidden != old.TreeShowHidden ||
+		cfg.TreeMaxEntries != old.TreeMaxEntries ||
+		containsString(cfg.TreeColumns, treeColumnWords) != containsString(old.TreeColumns, treeColumnWords)
+	if treeChanged && m.fileTreeRoot != "" {
+		if err := m.rebuildFileTree(); err != nil {
+			LogWarningf("Failed to rebuild file tree: %v", err)
+		}
+	} else if cfg.TreeSort != old.TreeSort && m.fileTreeRoot != "" {
+		m.resortTree()
+	}
+	if !containsString(old.TreeColumns, treeColumnGit) {
+		cmds = append(cmds, m.treeGitCmd(true))
+	}
+
+	if theme, err := loadTheme(cfg.themeName()); err == nil {
+		m.theme = theme
+	} else {
+		LogWarningf("Failed to load theme: %v", err)
+	}
+
+	if km, err := newKeymap(cfg.Keymap, cfg.Keys); err == nil {
+		m.keymap = km
+		m.pendingKeys = nil
+	}
+
+	// Tree width and zen measure change the wrap width
+	m.rewrapLines()
+
+	return tea.Batch(cmds...)
+}
+
+// handleSetCommand handles ":set", ":set option", ":set option=value" and ":set option value"
+func (m model) handleSetCommand(args []string) (tea.Model, tea.Cmd) {
+	if len(args) == 0 {
+		m.showConfig()
+		return m, nil
+	}
+
+	name, value, hasValue := strings.Cut(strings.Join(args, " "), "=")
+	if !hasValue {
+		name, value, hasValue = strings.Cut(name, " ")
+	}
+	name = strings.TrimSuffix(strings.TrimSpace(name), "?")
+	value = strings.TrimSpace(value)
+
+	opt, ok := findConfigOption(name)
+	if !ok {
+		m.setStatus("Unknown option: "+name+" (:set lists options)", "red")
+		return m, nil
+	}
+	if !hasValue {
+		m.setStatus(name+" = "+opt.get(m.config), "green")
+		return m, nil
+	}
+
+	cfg := m.config
+	cfg.ExcludeDirs = append([]string(nil), m.config.ExcludeDirs...)
+	cfg.TreeInclude = append([]string(nil), m.config.TreeInclude...)
+	cfg.TreeColumns = append([]string(nil), m.config.TreeColumns...)
+	if err := opt.set(&cfg, strings.Trim(value, `"'`)); err != nil {
+		m.setStatus(name+": "+err.Error(), "red")
+		return m, nil
+	}
+	cfg.normalize()
+	if err := cfg.validate(); err != nil {
+		m.setStatus(err.Error(), "red")
+		return m, nil
+	}
+
+	cmd := m.applyConfig(cfg)
+	m.setStatus(name+" = "+opt.get(m.config), "green")
+	return m, cmd
+}
+
+// showConfig lists every option and its current value in the info pane
+func (m *model) showConfig() {
+	width := 0
+	for _, opt := range configOptions {
+		if len(opt.name) > width {
+			width = len(opt.name)
+		}
+	}
+
+	var lines []string
+	for _, opt := range configOptions {
+		lines = append(lines, fmt.Sprintf("%-*s = %s", width, opt.name, opt.get(m.config)))
+	}
+	lines = append(lines, "", "Files: "+strings.Join(configPaths(m.projectDir()), ", "))
+
+	m.openInfoPane("Settings (:set option=value)", lines)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	dir := t.TempDir()
+	user := writeConfigFile(t, dir, userConfigFile, `
+autosave_interval = "5m"
+tab_width = 2
+spell_language = "en_US"
+exclude_dirs = ["build"]
+`)
+	project := writeConfigFile(t, dir, projectConfigFile, `
+tab_width = 8
+zen_focus = "sentence"
+`)
+
+	cfg, err := loadConfig([]string{user, project, filepath.Join(dir, "missing.toml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AutoSaveInterval.Duration != 5*time.Minute {
+		t.Errorf("autosave_interval = %v, want 5m", cfg.AutoSaveInterval)
+	}
+	if cfg.TabWidth != 8 {
+		t.Errorf("project tab_width should override user: got %d", cfg.TabWidth)
+	}
+	if cfg.SpellLanguage != "us" {
+		t.Errorf("spell_language = %q, want normalised \"us\"", cfg.SpellLanguage)
+	}
+	if cfg.ZenFocus != zenFocusSentence || cfg.TreeWidth != 30 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if len(cfg.ExcludeDirs) != 1 || cfg.ExcludeDirs[0] != "build" {
+		t.Errorf("exclude_dirs = %v", cfg.ExcludeDirs)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown option": `tab_size = 2`,
+		"out of range":   `tree_width = 200`,
+		"bad duration":   `cursor_blink = "fast"`,
+		"bad syntax":     `tab_width = `,
+		"bad language":   `spell_language = "klingon"`,
+	}
+	for name, content := range tests {
+		path := writeConfigFile(t, t.TempDir(), userConfigFile, content)
+		cfg, err := loadConfig([]string{path})
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if !reflect.DeepEqual(cfg, defaultConfig()) {
+			t.Errorf("%s: a bad config should fall back to defaults", name)
+		}
+	}
+}
+
+func TestConfigOptionsMatchTOMLKeys(t *testing.T) {
+	typ := reflect.TypeOf(Config{})
+	fields := 0
+	for i := 0; i < typ.NumField(); i++ {
+		key := typ.Field(i).Tag.Get("toml")
+		if key == "keys" {
+			continue // bindings are changed with :map, not :set
+		}
+		fields++
+		if _, ok := findConfigOption(key); !ok {
+			t.Errorf("config key %q has no :set option", key)
+		}
+	}
+	if fields != len(configOptions) {
+		t.Errorf("%d config fields but %d :set options", fields, len(configOptions))
+	}
+}
+
+func TestSetCommand(t *testing.T) {
+	m := model{
+		lines:     []string{"text"},
+		wrapCache: make(map[int][]wrappedLine),
+		config:    defaultConfig(),
+		width:     100,
+		height:    30,
+	}
+
+	result, _ := m.handleSetCommand([]string{"tab_width=2"})
+	m = result.(model)
+	if m.config.TabWidth != 2 {
+		t.Errorf("tab_width = %d, want 2", m.config.TabWidth)
+	}
+
+	result, _ = m.handleSetCommand([]string{"zen_typewriter", "off"})
+	m = result.(model)
+	if m.config.ZenTypewriter {
+		t.Error("zen_typewriter should be off")
+	}
+
+	// Invalid values are rejected and reported
+	result, _ = m.handleSetCommand([]string{"tab_width=0"})
+	m = result.(model)
+	if m.config.TabWidth != 2 || m.statusMsg.Color != "red" {
+		t.Errorf("invalid value accepted: tab_width=%d status=%q", m.config.TabWidth, m.statusMsg.Text)
+	}
+
+	result, _ = m.handleSetCommand([]string{"no_such_option=1"})
+	m = result.(model)
+	if !strings.Contains(m.statusMsg.Text, "Unknown option") {
+		t.Errorf("status = %q", m.statusMsg.Text)
+	}
+
+	// Turning auto-save back on restarts its timer
+	m.autoSaveRunning = false
+	_, cmd := m.handleSetCommand([]string{"autosave_interval=10m"})
+	if cmd == nil {
+		t.Error("enabling auto-save should schedule a tick")
+	}
+}
+
+// TestSetSpellLanguageKeepsSpellCheckOff verifies a new default language is
+// loaded without turning spell-checking on
+func TestSetSpellLanguageKeepsSpellCheckOff(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dictPath, err := getDictPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"en_US.aff", "en_US.dic"} {
+		content := "SET UTF-8\n"
+		if strings.HasSuffix(name, ".dic") {
+			content = "1\ncolor\n"
+		}
+		if err := os.WriteFile(filepath.Join(dictPath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := model{
+		lines:        []string{"text"},
+		wrapCache:    make(map[int][]wrappedLine),
+		config:       defaultConfig(),
+		width:        100,
+		height:       30,
+		spellChecker: newSpellChecker("uk"),
+	}
+	result, _ := m.handleSetCommand([]string{"spell_language=us"})
+	m = result.(model)
+	if m.spellChecker.language != "us" {
+		t.Errorf("language = %q, want us", m.spellChecker.language)
+	}
+	if m.spellChecker.enabled {
+		t.Error("changing spell_language turned spell-checking on")
+	}
+
+	m.spellChecker.enabled = true
+	result, _ = m.handleSetCommand([]string{"spell_language=uk"})
+	if m = result.(model); !m.spellChecker.enabled {
+		t.Error("changing spell_language turned spell-checking off")
+	}
+}
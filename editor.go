@@ -2,312 +2,372 @@ package main
 
 import (
 	"strings"
-
-	"github.com/atotto/clipboard"
-	tea "github.com/charmbracelet/bubbletea"
 )
 
-// handleEditMode processes keys in edit mode (full editing)
-func (m model) handleEditMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "shift+up", "shift+down", "shift+left", "shift+right":
-		// Start selection if not already active
-		if !m.selectionActive {
-			m.selectionActive = true
-			m.selectionStartX = m.cursorX
-			m.selectionStartY = m.cursorY
-		}
-
-		// Move cursor based on direction (selection remains anchored at start)
-		switch msg.String() {
-		case "shift+up":
-			currentWrappedIdx := m.getWrappedLineIndexForCursor()
-			if currentWrappedIdx > 0 {
-				m.moveToWrappedLine(currentWrappedIdx - 1)
-				lineLen := len(m.getCurrentLine())
-				if m.cursorX > lineLen {
-					m.cursorX = lineLen
-				}
-				m.adjustViewport()
-			}
-		case "shift+down":
-			currentWrappedIdx := m.getWrappedLineIndexForCursor()
-			m.moveToWrappedLine(currentWrappedIdx + 1)
-			lineLen := len(m.getCurrentLine())
-			if m.cursorX > lineLen {
-				m.cursorX = lineLen
-			}
-			m.adjustViewport()
-		case "shift+left":
-			if m.cursorX > 0 {
-				m.cursorX--
-			} else if m.cursorY > 0 {
-				m.cursorY--
-				m.cursorX = len(m.getCurrentLine())
-				m.adjustViewport()
-			}
-		case "shift+right":
-			lineLen := len(m.getCurrentLine())
-			if m.cursorX < lineLen {
-				m.cursorX++
-			} else if m.cursorY < len(m.lines)-1 {
-				m.cursorY++
-				m.cursorX = 0
-				m.adjustViewport()
-			}
-		}
-
-	case "up":
-		// Clear selection on non-shift movement
-		m.selectionActive = false
-		// Navigate by wrapped lines, not source lines
-		currentWrappedIdx := m.getWrappedLineIndexForCursor()
-		if currentWrappedIdx > 0 {
-			m.moveToWrappedLine(currentWrappedIdx - 1)
-			// Adjust cursor X if line is shorter
-			lineLen := len(m.getCurrentLine())
-			if m.cursorX > lineLen {
-				m.cursorX = lineLen
-			}
-			m.adjustViewport()
-		}
+// startSelection anchors a selection at the cursor if none is active
+func (m *model) startSelection() {
+	if !m.selectionActive {
+		m.selectionActive = true
+		m.selectionStartX = m.cursorX
+		m.selectionStartY = m.cursorY
+	}
+}
 
-	case "down":
-		// Clear selection on non-shift movement
-		m.selectionActive = false
-		// Navigate by wrapped lines, not source lines
-		currentWrappedIdx := m.getWrappedLineIndexForCursor()
-		m.moveToWrappedLine(currentWrappedIdx + 1)
-		// Adjust cursor X if line is shorter
-		lineLen := len(m.getCurrentLine())
-		if m.cursorX > lineLen {
-			m.cursorX = lineLen
-		}
-		m.adjustViewport()
+// insertTab inserts spaces up to the configured tab width
+func (m *model) insertTab() {
+	m.deleteSelection()
+	line := m.getCurrentLine()
+	m.lines[m.cursorY] = line[:m.cursorX] + strings.Repeat(" ", m.config.TabWidth) + line[m.cursorX:]
+	m.cursorX += m.config.TabWidth
+	m.modified = true
+	m.invalidateWrapCache(m.cursorY)
+}
 
-	case "left":
-		// Clear selection on non-shift movement
-		m.selectionActive = false
-		if m.cursorX > 0 {
-			m.cursorX--
-		} else if m.cursorY > 0 {
-			// Move to end of previous line
-			m.cursorY--
-			m.cursorX = len(m.getCurrentLine())
-			m.adjustViewport()
-		}
+// insertNewline splits the line at the cursor
+func (m *model) insertNewline() {
+	m.deleteSelection()
+	currentLine := m.getCurrentLine()
+	before := currentLine[:m.cursorX]
+	after := currentLine[m.cursorX:]
 
-	case "right":
-		// Clear selection on non-shift movement
-		m.selectionActive = false
-		lineLen := len(m.getCurrentLine())
-		if m.cursorX < lineLen {
-			m.cursorX++
-		} else if m.cursorY < len(m.lines)-1 {
-			// Move to start of next line
-			m.cursorY++
-			m.cursorX = 0
-			m.adjustViewport()
-		}
+	m.lines[m.cursorY] = before
+	m.lines = append(m.lines[:m.cursorY+1], append([]string{after}, m.lines[m.cursorY+1:]...)...)
 
-	case "home":
-		// Clear selection on non-shift movement
-		m.selectionActive = false
-		m.cursorX = 0
+	// Invalidate cache from current line onwards (all subsequent indices shift)
+	m.invalidateWrapCacheFrom(m.cursorY)
 
-	case "end":
-		// Clear selection on non-shift movement
-		m.selectionActive = false
-		m.cursorX = len(m.getCurrentLine())
+	m.cursorY++
+	m.cursorX = 0
+	m.modified = true
+	m.adjustViewport()
+}
 
-	case "tab":
-		// Insert 4 spaces for tab
+// deleteBackward deletes the character before the cursor, joining lines at the start of a line
+func (m *model) deleteBackward() {
+	if m.deleteSelection() {
+		return
+	}
+	if m.cursorX > 0 {
+		// Delete character before cursor
 		line := m.getCurrentLine()
-		m.lines[m.cursorY] = line[:m.cursorX] + "    " + line[m.cursorX:]
-		m.cursorX += 4
+		m.lines[m.cursorY] = line[:m.cursorX-1] + line[m.cursorX:]
+		m.cursorX--
 		m.modified = true
-		m.invalidateWrapCache(m.cursorY)
-
-	case "enter":
-		// Split line at cursor
+		m.invalidateWrapCache(m.cursorY) // Invalidate modified line
+	} else if m.cursorY > 0 {
+		// Join with previous line - this deletes a line, so indices shift
+		prevLine := m.lines[m.cursorY-1]
 		currentLine := m.getCurrentLine()
-		before := currentLine[:m.cursorX]
-		after := currentLine[m.cursorX:]
+		m.lines[m.cursorY-1] = prevLine + currentLine
+		m.lines = append(m.lines[:m.cursorY], m.lines[m.cursorY+1:]...)
+
+		// Invalidate cache from previous line onwards (all subsequent indices shift)
+		m.invalidateWrapCacheFrom(m.cursorY - 1)
+
+		m.cursorY--
+		m.cursorX = len(prevLine)
+		m.modified = true
+		m.adjustViewport()
+	}
+}
 
-		m.lines[m.cursorY] = before
-		m.lines = append(m.lines[:m.cursorY+1], append([]string{after}, m.lines[m.cursorY+1:]...)...)
+// deleteForward deletes the character at the cursor, joining lines at the end of a line
+func (m *model) deleteForward() {
+	if m.deleteSelection() {
+		return
+	}
+	line := m.getCurrentLine()
+	if m.cursorX < len(line) {
+		// Delete character at cursor
+		m.lines[m.cursorY] = line[:m.cursorX] + line[m.cursorX+1:]
+		m.modified = true
+		m.invalidateWrapCache(m.cursorY) // Invalidate modified line
+	} else if m.cursorY < len(m.lines)-1 {
+		// Join with next line - this deletes a line, so indices shift
+		nextLine := m.lines[m.cursorY+1]
+		m.lines[m.cursorY] = line + nextLine
+		m.lines = append(m.lines[:m.cursorY+1], m.lines[m.cursorY+2:]...)
 
 		// Invalidate cache from current line onwards (all subsequent indices shift)
 		m.invalidateWrapCacheFrom(m.cursorY)
 
-		m.cursorY++
-		m.cursorX = 0
 		m.modified = true
-		m.adjustViewport()
+	}
+}
 
-	case "backspace":
-		if m.cursorX > 0 {
-			// Delete character before cursor
-			line := m.getCurrentLine()
-			m.lines[m.cursorY] = line[:m.cursorX-1] + line[m.cursorX:]
-			m.cursorX--
-			m.modified = true
-			m.invalidateWrapCache(m.cursorY) // Invalidate modified line
-		} else if m.cursorY > 0 {
-			// Join with previous line - this deletes a line, so indices shift
-			prevLine := m.lines[m.cursorY-1]
-			currentLine := m.getCurrentLine()
-			m.lines[m.cursorY-1] = prevLine + currentLine
-			m.lines = append(m.lines[:m.cursorY], m.lines[m.cursorY+1:]...)
-
-			// Invalidate cache from previous line onwards (all subsequent indices shift)
-			m.invalidateWrapCacheFrom(m.cursorY - 1)
-
-			m.cursorY--
-			m.cursorX = len(prevLine)
-			m.modified = true
-			m.adjustViewport()
-		}
+// deleteWordBackward deletes from the start of the previous word to the cursor
+func (m *model) deleteWordBackward() {
+	if m.deleteSelection() {
+		return
+	}
+	if m.cursorX == 0 {
+		m.deleteBackward()
+		return
+	}
+	line := m.getCurrentLine()
+	start := m.cursorX
+	for start > 0 && line[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && line[start-1] != ' ' {
+		start--
+	}
+	m.lines[m.cursorY] = line[:start] + line[m.cursorX:]
+	m.cursorX = start
+	m.modified = true
+	m.invalidateWrapCache(m.cursorY)
+}
 
-	case "delete":
-		line := m.getCurrentLine()
-		if m.cursorX < len(line) {
-			// Delete character at cursor
-			m.lines[m.cursorY] = line[:m.cursorX] + line[m.cursorX+1:]
-			m.modified = true
-			m.invalidateWrapCache(m.cursorY) // Invalidate modified line
-		} else if m.cursorY < len(m.lines)-1 {
-			// Join with next line - this deletes a line, so indices shift
-			nextLine := m.lines[m.cursorY+1]
-			m.lines[m.cursorY] = line + nextLine
-			m.lines = append(m.lines[:m.cursorY+1], m.lines[m.cursorY+2:]...)
-
-			// Invalidate cache from current line onwards (all subsequent indices shift)
-			m.invalidateWrapCacheFrom(m.cursorY)
-
-			m.modified = true
-		}
+// deleteToLineEnd deletes from the cursor to the end of the line (joining the
+// next line when already at the end)
+func (m *model) deleteToLineEnd() {
+	line := m.getCurrentLine()
+	if m.cursorX >= len(line) {
+		m.deleteForward()
+		return
+	}
+	m.pushKillRing(line[m.cursorX:])
+	m.lines[m.cursorY] = line[:m.cursorX]
+	m.modified = true
+	m.invalidateWrapCache(m.cursorY)
+}
 
-	case "ctrl+c":
-		// Copy selected text to clipboard
-		if !m.selectionActive {
-			m.setStatus("No text selected", "yellow")
-			return m, nil
+// deleteLine removes the current line
+func (m *model) deleteLine() {
+	m.pushKillRing(m.getCurrentLine() + "\n")
+	if len(m.lines) == 1 {
+		m.lines[0] = ""
+	} else {
+		m.lines = append(m.lines[:m.cursorY], m.lines[m.cursorY+1:]...)
+		if m.cursorY >= len(m.lines) {
+			m.cursorY = len(m.lines) - 1
 		}
+	}
+	m.invalidateWrapCacheFrom(m.cursorY)
+	m.cursorX = 0
+	m.selectionActive = false
+	m.modified = true
+	m.adjustViewport()
+}
 
-		// Get selection bounds (normalize so start is always before end)
-		startY, startX := m.selectionStartY, m.selectionStartX
-		endY, endX := m.cursorY, m.cursorX
+// insertRune inserts a typed character at the cursor
+func (m *model) insertRune(r rune) {
+	// Typing replaces the selection
+	m.deleteSelection()
+	line := m.getCurrentLine()
+	ch := string(r)
+	m.lines[m.cursorY] = line[:m.cursorX] + ch + line[m.cursorX:]
+	m.cursorX += len(ch)
+	m.modified = true
+	m.invalidateWrapCache(m.cursorY) // Invalidate modified line
+}
 
-		// Swap if selection is backwards
-		if startY > endY || (startY == endY && startX > endX) {
-			startY, endY = endY, startY
-			startX, endX = endX, startX
-		}
+// copySelection copies the selected text to the clipboard (or the chosen register)
+func (m *model) copySelection() {
+	if !m.selectionActive {
+		m.register = 0
+		m.setStatus("No text selected", "yellow")
+		return
+	}
 
-		var selectedText string
-		if startY == endY {
-			// Single line selection
-			selectedText = m.lines[startY][startX:endX]
-		} else {
-			// Multi-line selection
-			var textBuilder strings.Builder
-			// First line: from startX to end
-			textBuilder.WriteString(m.lines[startY][startX:])
-			textBuilder.WriteString("\n")
-
-			// Middle lines: entire lines
-			for i := startY + 1; i < endY; i++ {
-				textBuilder.WriteString(m.lines[i])
-				textBuilder.WriteString("\n")
-			}
-
-			// Last line: from start to endX
-			textBuilder.WriteString(m.lines[endY][:endX])
-			selectedText = textBuilder.String()
-		}
+	where, color := m.storeClipboard(m.selectedText())
+	m.setStatus("Copied to "+where, color)
 
-		// Copy to clipboard
-		err := clipboard.WriteAll(selectedText)
-		if err != nil {
-			m.setStatus("Failed to copy to clipboard", "red")
-		} else {
-			m.setStatus("Copied to clipboard", "green")
-		}
+	// Keep selection active after copy (user might want to see what was copied)
+}
 
-		// Keep selection active after copy (user might want to see what was copied)
-		return m, nil
+// pasteClipboard inserts the clipboard contents at the cursor, replacing the selection
+func (m *model) pasteClipboard() {
+	clipText, err := m.clipboardText()
+	if err != nil {
+		m.setStatus("Can't paste: "+err.Error(), "yellow")
+		return
+	}
 
-	case "ctrl+v":
-		// Paste from clipboard
-		clipText, err := clipboard.ReadAll()
-		if err != nil {
-			m.setStatus("Failed to read clipboard", "red")
-			return m, nil
-		}
+	if clipText == "" {
+		return // Nothing to paste
+	}
 
-		if clipText == "" {
-			return m, nil // Nothing to paste
-		}
+	m.insertPaste(clipText)
+}
 
-		// Split pasted text into lines
-		pasteLines := strings.Split(clipText, "\n")
-
-		if len(pasteLines) == 1 {
-			// Single line paste - insert at cursor position
-			line := m.getCurrentLine()
-			m.lines[m.cursorY] = line[:m.cursorX] + clipText + line[m.cursorX:]
-			m.cursorX += len(clipText)
-			m.modified = true
-			m.invalidateWrapCache(m.cursorY)
-		} else {
-			// Multi-line paste
-			currentLine := m.getCurrentLine()
-			before := currentLine[:m.cursorX]
-			after := currentLine[m.cursorX:]
-
-			// First line: before + first paste line
-			m.lines[m.cursorY] = before + pasteLines[0]
-
-			// Insert middle lines
-			newLines := make([]string, 0, len(pasteLines)-1)
-			for i := 1; i < len(pasteLines)-1; i++ {
-				newLines = append(newLines, pasteLines[i])
-			}
-
-			// Last line: last paste line + after
-			lastPasteLine := pasteLines[len(pasteLines)-1]
-			newLines = append(newLines, lastPasteLine+after)
-
-			// Insert all new lines after current line
-			m.lines = append(m.lines[:m.cursorY+1], append(newLines, m.lines[m.cursorY+1:]...)...)
-
-			// Move cursor to end of pasted text
-			m.cursorY += len(pasteLines) - 1
-			m.cursorX = len(lastPasteLine)
-
-			// Invalidate cache from original line onwards (lines were inserted)
-			m.invalidateWrapCacheFrom(m.cursorY - len(pasteLines) + 1)
-
-			m.modified = true
-			m.adjustViewport()
-		}
+// insertPaste inserts pasted text as one edit, replacing the selection, and
+// remembers it for clipboard.paste_older
+func (m *model) insertPaste(text string) {
+	text = normalizePaste(text)
+	if text == "" {
+		return
+	}
+	m.deleteSelection()
+	y, x := m.cursorY, m.cursorX
+	m.insertText(text)
+	m.lastPaste = pastedText{y: y, x: x, text: text, ring: -1}
+	if len(m.killRing) > 0 && normalizePaste(m.killRing[0]) == text {
+		m.lastPaste.ring = 0
+	}
+	m.adjustViewport()
+}
 
-	default:
-		// Insert regular characters
-		if len(msg.Runes) == 1 {
-			r := msg.Runes[0]
-			if r >= 32 && r != 127 { // Printable characters
-				// Clear selection on typing
-				m.selectionActive = false
-				line := m.getCurrentLine()
-				m.lines[m.cursorY] = line[:m.cursorX] + string(r) + line[m.cursorX:]
-				m.cursorX++
-				m.modified = true
-				m.invalidateWrapCache(m.cursorY) // Invalidate modified line
-			}
+// normalizePaste turns "\r\n" and lone "\r" line endings into "\n" and drops
+// other control characters, such as escapes, apart from tabs
+func normalizePaste(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.Map(func(r rune) rune {
+		if r < 32 && r != '\n' && r != '\t' || r == 127 {
+			return -1
 		}
+		return r
+	}, text)
+}
+
+// insertText inserts text at the cursor, splitting it into lines at "\n", and
+// leaves the cursor after it
+func (m *model) insertText(text string) {
+	// Split inserted text into lines
+	pasteLines := strings.Split(text, "\n")
+
+	if len(pasteLines) == 1 {
+		// Single line - insert at cursor position
+		line := m.getCurrentLine()
+		m.lines[m.cursorY] = line[:m.cursorX] + text + line[m.cursorX:]
+		m.cursorX += len(text)
+		m.modified = true
+		m.invalidateWrapCache(m.cursorY)
+		return
+	}
+
+	// Multi-line insert
+	currentLine := m.getCurrentLine()
+	before := currentLine[:m.cursorX]
+	after := currentLine[m.cursorX:]
+
+	// First line: before + first inserted line
+	m.lines[m.cursorY] = before + pasteLines[0]
+
+	// Insert middle lines
+	newLines := make([]string, 0, len(pasteLines)-1)
+	for i := 1; i < len(pasteLines)-1; i++ {
+		newLines = append(newLines, pasteLines[i])
+	}
+
+	// Last line: last inserted line + after
+	lastPasteLine := pasteLines[len(pasteLines)-1]
+	newLines = append(newLines, lastPasteLine+after)
+
+	// Insert all new lines after current line
+	m.lines = append(m.lines[:m.cursorY+1], append(newLines, m.lines[m.cursorY+1:]...)...)
+
+	// Move cursor to end of inserted text
+	m.cursorY += len(pasteLines) - 1
+	m.cursorX = len(lastPasteLine)
+
+	// Invalidate cache from original line onwards (lines were inserted)
+	m.invalidateWrapCacheFrom(m.cursorY - len(pasteLines) + 1)
+
+	m.modified = true
+	m.adjustViewport()
+}
+
+// deleteSelection removes the selected text and puts the cursor where it
+// started. It reports whether there was anything to delete.
+func (m *model) deleteSelection() bool {
+	if !m.selectionActive {
+		return false
+	}
+	m.selectionActive = false
+	startY, startX, endY, endX := m.selectionBounds()
+	if startY == endY && startX == endX {
+		return false
+	}
+
+	m.lines[startY] = m.lines[startY][:startX] + m.lines[endY][endX:]
+	m.lines = append(m.lines[:startY+1], m.lines[endY+1:]...)
+	if endY > startY {
+		m.invalidateWrapCacheFrom(startY)
+	} else {
+		m.invalidateWrapCache(startY)
+	}
+
+	m.cursorY, m.cursorX = startY, startX
+	m.modified = true
+	m.adjustViewport()
+	return true
+}
+
+// cutSelection copies the selection to the clipboard and removes it
+func (m *model) cutSelection() {
+	if !m.selectionActive {
+		m.register = 0
+		m.setStatus("No text selected", "yellow")
+		return
+	}
+	// The kill ring always keeps the text, so cutting can't lose it
+	where, color := m.storeClipboard(m.selectedText())
+	m.deleteSelection()
+	m.setStatus("Cut to "+where, color)
+}
+
+// selectAll selects the whole document
+func (m *model) selectAll() {
+	m.selectionActive = true
+	m.selectionStartY, m.selectionStartX = 0, 0
+	m.moveToDocumentEnd()
+}
+
+// selectLine selects the current line, or extends a selection to the end of
+// the next line when the selection already ends at a line end
+func (m *model) selectLine() {
+	if !m.selectionActive {
+		m.selectionActive = true
+		m.selectionStartY, m.selectionStartX = m.cursorY, 0
+	} else if m.cursorX == len(m.getCurrentLine()) && m.cursorY < len(m.lines)-1 {
+		m.cursorY++
+	}
+	m.cursorX = len(m.getCurrentLine())
+	m.adjustViewport()
+}
+
+// selectionBounds returns the selection start and end, normalised so the start
+// comes first
+func (m model) selectionBounds() (startY, startX, endY, endX int) {
+	startY, startX = m.selectionStartY, m.selectionStartX
+	endY, endX = m.cursorY, m.cursorX
+
+	// Swap if selection is backwards
+	if startY > endY || (startY == endY && startX > endX) {
+		startY, endY = endY, startY
+		startX, endX = endX, startX
+	}
+	return startY, startX, endY, endX
+}
+
+// selectedText returns the currently selected text ("" when nothing is selected)
+func (m model) selectedText() string {
+	if !m.selectionActive {
+		return ""
+	}
+
+	startY, startX, endY, endX := m.selectionBounds()
+
+	if startY == endY {
+		// Single line selection
+		return m.lines[startY][startX:endX]
+	}
+
+	// Multi-line selection
+	var textBuilder strings.Builder
+	// First line: from startX to end
+	textBuilder.WriteString(m.lines[startY][startX:])
+	textBuilder.WriteString("\n")
+
+	// Middle lines: entire lines
+	for i := startY + 1; i < endY; i++ {
+		textBuilder.WriteString(m.lines[i])
+		textBuilder.WriteString("\n")
 	}
 
-	return m, nil
+	// Last line: from start to endX
+	textBuilder.WriteString(m.lines[endY][:endX])
+	return textBuilder.String()
 }
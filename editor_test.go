@@ -0,0 +1,205 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newEditorTestModel is in Edit mode with a selection from (startY, startX) to the cursor at (y, x)
+func newEditorTestModel(t *testing.T, lines []string, startY, startX, y, x int) model {
+	t.Helper()
+	m := newKeymapTestModel(t, defaultKeymapPreset)
+	m.mode = EditMode
+	m.lines = lines
+	m.selectionActive = true
+	m.selectionStartY, m.selectionStartX = startY, startX
+	m.cursorY, m.cursorX = y, x
+	return m
+}
+
+func TestDeleteSelection(t *testing.T) {
+	lines := func() []string { return []string{"The quick brown fox", "jumps over", "the lazy dog"} }
+
+	m := newEditorTestModel(t, lines(), 0, 4, 0, 10)
+	m = pressSpecial(t, m, tea.KeyBackspace)
+	if m.lines[0] != "The brown fox" || m.cursorX != 4 || m.selectionActive {
+		t.Errorf("backspace: %q cursor %d", m.lines[0], m.cursorX)
+	}
+
+	// A backwards selection across lines, removed with delete
+	m = newEditorTestModel(t, lines(), 2, 4, 0, 10)
+	m = pressSpecial(t, m, tea.KeyDelete)
+	if want := []string{"The quick lazy dog"}; !reflect.DeepEqual(m.lines, want) {
+		t.Errorf("delete: %q", m.lines)
+	}
+	if m.cursorY != 0 || m.cursorX != 10 || !m.modified {
+		t.Errorf("cursor %d,%d modified %v", m.cursorY, m.cursorX, m.modified)
+	}
+
+	// An empty selection deletes a character as usual
+	m = newEditorTestModel(t, lines(), 0, 3, 0, 3)
+	m = pressSpecial(t, m, tea.KeyBackspace)
+	if m.lines[0] != "Th quick brown fox" {
+		t.Errorf("empty selection: %q", m.lines[0])
+	}
+}
+
+func TestTypingReplacesSelection(t *testing.T) {
+	m := newEditorTestModel(t, []string{"The quick brown fox", "jumps"}, 0, 4, 1, 0)
+	m = pressKeys(t, m, "s", "l", "y", " ")
+	if want := []string{"The sly jumps"}; !reflect.DeepEqual(m.lines, want) {
+		t.Errorf("lines = %q", m.lines)
+	}
+
+	m = newEditorTestModel(t, []string{"one two"}, 0, 3, 0, 7)
+	m = pressSpecial(t, m, tea.KeyEnter)
+	if want := []string{"one", ""}; !reflect.DeepEqual(m.lines, want) {
+		t.Errorf("enter over a selection: %q", m.lines)
+	}
+
+	m = newEditorTestModel(t, []string{"one two three"}, 0, 4, 0, 7)
+	m.deleteSelection()
+	m.insertText("2\nand a half")
+	if want := []string{"one 2", "and a half three"}; !reflect.DeepEqual(m.lines, want) {
+		t.Errorf("insertText: %q", m.lines)
+	}
+	if m.cursorY != 1 || m.cursorX != len("and a half") {
+		t.Errorf("cursor %d,%d", m.cursorY, m.cursorX)
+	}
+}
+
+func TestSelectionExtension(t *testing.T) {
+	lines := []string{"First paragraph", "goes on.", "", "Second one.", "", "", "Third."}
+	m := newKeymapTestModel(t, defaultKeymapPreset)
+	m.mode = EditMode
+	m.lines = lines
+
+	m = pressSpecial(t, m, tea.KeyCtrlA)
+	if got, want := m.selectedText(), "First paragraph\ngoes on.\n\nSecond one.\n\n\nThird."; got != want {
+		t.Errorf("select all = %q", got)
+	}
+
+	m.selectionActive = false
+	m.cursorY, m.cursorX = 0, 6
+	m = pressSpecial(t, m, tea.KeyCtrlShiftDown)
+	if got := m.selectedText(); got != "paragraph\ngoes on." {
+		t.Errorf("to paragraph end = %q", got)
+	}
+	m = pressSpecial(t, m, tea.KeyCtrlShiftDown)
+	if got := m.selectedText(); got != "paragraph\ngoes on.\n\nSecond one." {
+		t.Errorf("to next paragraph end = %q", got)
+	}
+	m = pressSpecial(t, m, tea.KeyCtrlShiftUp, tea.KeyCtrlShiftUp)
+	if m.cursorY != 0 || m.cursorX != 0 {
+		t.Errorf("back up to %d,%d", m.cursorY, m.cursorX)
+	}
+
+	// Moving by paragraph skips runs of blank lines
+	m.selectionActive = false
+	m.cursorY, m.cursorX = 3, 11
+	m = pressSpecial(t, m, tea.KeyCtrlDown)
+	if m.cursorY != 6 || m.cursorX != len("Third.") || m.selectionActive {
+		t.Errorf("ctrl+down moved to %d,%d", m.cursorY, m.cursorX)
+	}
+
+	m.cursorY, m.cursorX = 0, 3
+	m = pressSpecial(t, m, tea.KeyCtrlShiftRight)
+	if got := m.selectedText(); got != "st " {
+		t.Errorf("word selection = %q", got)
+	}
+
+	m.selectionActive = false
+	m = pressSpecial(t, m, tea.KeyCtrlL, tea.KeyCtrlL)
+	if got := m.selectedText(); got != "First paragraph\ngoes on." {
+		t.Errorf("line selection = %q", got)
+	}
+}
+
+func TestUndoSteps(t *testing.T) {
+	m := newEditorTestModel(t, []string{""}, 0, 0, 0, 0)
+	m.selectionActive = false
+
+	// A run of typing is one step, each action another; no-op actions record nothing
+	m = pressKeys(t, m, "a", "b", "c")
+	m = pressSpecial(t, m, tea.KeyBackspace, tea.KeyLeft, tea.KeyLeft, tea.KeyLeft, tea.KeyBackspace)
+	m = pressKeys(t, m, "d")
+	if m.lines[0] != "dab" || len(m.undoStack) != 3 {
+		t.Fatalf("lines %q, %d undo steps", m.lines, len(m.undoStack))
+	}
+	for _, want := range []string{"ab", "abc", ""} {
+		m = pressSpecial(t, m, tea.KeyCtrlZ)
+		if m.lines[0] != want {
+			t.Errorf("undo: %q, want %q", m.lines[0], want)
+		}
+	}
+	if m = pressSpecial(t, m, tea.KeyCtrlZ); m.statusMsg.Text != "Nothing to undo" {
+		t.Errorf("status = %q", m.statusMsg.Text)
+	}
+
+	// Redo steps forward again until a new edit discards what's left
+	m = pressSpecial(t, m, tea.KeyCtrlY, tea.KeyCtrlY)
+	if m.lines[0] != "ab" {
+		t.Errorf("redo: %q", m.lines[0])
+	}
+	m = pressKeys(t, m, "x")
+	if len(m.redoStack) != 0 {
+		t.Error("a new edit should clear the redo history")
+	}
+}
+
+func TestBracketedPaste(t *testing.T) {
+	paste := func(m model, text string) model {
+		result, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text), Paste: true})
+		return result.(model)
+	}
+
+	// A multi-line paste replaces the selection in one piece, with its line endings normalised
+	m := newEditorTestModel(t, []string{"one two three"}, 0, 4, 0, 7)
+	m = paste(m, "2\r\nand\ra half\x1b[0m")
+	if want := []string{"one 2", "and", "a half[0m three"}; !reflect.DeepEqual(m.lines, want) {
+		t.Errorf("lines = %q", m.lines)
+	}
+	if m.cursorY != 2 || m.cursorX != len("a half[0m") || !m.modified || m.selectionActive {
+		t.Errorf("cursor %d,%d modified %v", m.cursorY, m.cursorX, m.modified)
+	}
+	if m.statusMsg.Text != "Pasted 3 lines" {
+		t.Errorf("status = %q", m.statusMsg.Text)
+	}
+
+	// Undo takes the whole paste back in one step, selection included
+	m = pressKeys(t, m, "x")
+	m = pressSpecial(t, m, tea.KeyCtrlZ)
+	if want := []string{"one 2", "and", "a half[0m three"}; !reflect.DeepEqual(m.lines, want) {
+		t.Errorf("after undoing typing: %q", m.lines)
+	}
+	m = pressSpecial(t, m, tea.KeyCtrlZ)
+	if want := []string{"one two three"}; !reflect.DeepEqual(m.lines, want) || m.cursorY != 0 || m.cursorX != 7 {
+		t.Errorf("after undoing the paste: %q, cursor %d,%d", m.lines, m.cursorY, m.cursorX)
+	}
+	m = pressSpecial(t, m, tea.KeyCtrlY)
+	if len(m.lines) != 3 || m.lines[2] != "a half[0m three" {
+		t.Errorf("after redo: %q", m.lines)
+	}
+
+	// A single pasted character is inserted like any other paste
+	m = newEditorTestModel(t, []string{""}, 0, 0, 0, 0)
+	m.selectionActive = false
+	m = paste(m, "q")
+	if m.lines[0] != "q" {
+		t.Errorf("single character paste: %q", m.lines)
+	}
+
+	// Read mode refuses, the command line takes the first line
+	m.mode = ReadMode
+	m = paste(m, "text")
+	if m.lines[0] != "q" || m.statusMsg.Text != "Switch to Edit mode to paste" {
+		t.Errorf("read mode: %q, status %q", m.lines, m.statusMsg.Text)
+	}
+	m.openCommandLine()
+	m = paste(m, "set tab_width\nq")
+	if m.commandBuffer != ":set tab_width" {
+		t.Errorf("command line = %q", m.commandBuffer)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// exportFormats renders a document's lines for "tuiwrite export"
+var exportFormats = map[string]func(lines []string, title string) string{
+	"html": exportHTML,
+	"txt":  exportText,
+}
+
+// Inline markup recognised by the exporters
+var (
+	strongPattern = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	emPattern     = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	headingPrefix = regexp.MustCompile(`^(#{1,6})\s+`)
+)
+
+// splitFrontMatter separates a leading YAML block from the body
+func splitFrontMatter(lines []string) (frontMatter []string, body []string) {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if t := strings.TrimSpace(lines[i]); t == "---" || t == "..." {
+			return lines[1:i], lines[i+1:]
+		}
+	}
+	return nil, lines
+}
+
+// documentTitle takes the front matter title, else the file name
+func documentTitle(path string, lines []string) string {
+	frontMatter, _ := splitFrontMatter(lines)
+	for _, line := range frontMatter {
+		key, value, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(key), "title") {
+			if title := strings.Trim(strings.TrimSpace(value), `"'`); title != "" {
+				return title
+			}
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// exportBody drops front matter, modelines and inline language markup,
+// leaving the text a reader should see
+func exportBody(lines []string) []string {
+	_, body := splitFrontMatter(lines)
+	out := make([]string, 0, len(body))
+	for i, line := range body {
+		nearEdge := i < modelineScanLines || i >= len(body)-modelineScanLines
+		if nearEdge && modelinePattern.MatchString(line) {
+			continue
+		}
+		out = append(out, langSpanPattern.ReplaceAllString(line, "$1"))
+	}
+
+	// Trim blank lines left at either end
+	for len(out) > 0 && strings.TrimSpace(out[0]) == "" {
+		out = out[1:]
+	}
+	for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// isSceneBreak reports whether a line separates scenes (***, * * *, ---, #)
+func isSceneBreak(line string) bool {
+	t := strings.ReplaceAll(strings.TrimSpace(line), " ", "")
+	if t == "#" {
+		return true
+	}
+	if len(t) < 3 {
+		return false
+	}
+	return strings.Trim(t, "*") == "" || strings.Trim(t, "-") == "" || strings.Trim(t, "_") == ""
+}
+
+// exportText renders the document as plain text
+func exportText(lines []string, title string) string {
+	var b strings.Builder
+	for _, line := range exportBody(lines) {
+		if isSceneBreak(line) {
+			b.WriteString("* * *\n")
+			continue
+		}
+		line = headingPrefix.ReplaceAllString(line, "")
+		line = strongPattern.ReplaceAllString(line, "$1$2")
+		line = emPattern.ReplaceAllString(line, "$1$2")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// inlineHTML escapes a line and converts emphasis
+func inlineHTML(line string) string {
+	line = html.EscapeString(line)
+	line = strongPattern.ReplaceAllString(line, "<strong>$1$2</strong>")
+	line = emPattern.ReplaceAllString(line, "<em>$1$2</em>")
+	return line
+}
+
+// exportHTML renders the document as a standalone HTML page
+func exportHTML(lines []string, title string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	b.WriteString("</head>\n<body>\n")
+
+	var paragraph []string
+	quote := false
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		text := strings.Join(paragraph, " ")
+		if quote {
+			b.WriteString("<blockquote><p>" + text + "</p></blockquote>\n")
+		} else {
+			b.WriteString("<p>" + text + "</p>\n")
+		}
+		paragraph = nil
+	}
+
+	for _, line := range exportBody(lines) {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case isSceneBreak(trimmed):
+			flush()
+			b.WriteString("<hr>\n")
+		case headingPrefix.MatchString(trimmed):
+			flush()
+			level := len(headingPrefix.FindStringSubmatch(trimmed)[1])
+			text := inlineHTML(headingPrefix.ReplaceAllString(trimmed, ""))
+			tag := "h" + string(rune('0'+level))
+			b.WriteString("<" + tag + ">" + text + "</" + tag + ">\n")
+		default:
+			isQuote := strings.HasPrefix(trimmed, ">")
+			if isQuote != quote {
+				flush()
+				quote = isQuote
+			}
+			if isQuote {
+				trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			}
+			paragraph = append(paragraph, inlineHTML(trimmed))
+		}
+	}
+	flush()
+
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
@@ -1,13 +1,22 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// errReadOnly is returned when saving a session started with --readonly
+var errReadOnly = errors.New("read-only session (--readonly)")
+
 // saveFile writes the document to disk
 func (m *model) saveFile() error {
+	if m.readOnly {
+		return errReadOnly
+	}
 	content := strings.Join(m.lines, "\n")
 	err := os.WriteFile(m.filename, []byte(content), 0644)
 	if err != nil {
@@ -16,6 +25,7 @@ func (m *model) saveFile() error {
 
 	m.modified = false
 	m.lastSave = time.Now()
+	m.noteTreeFileSaved()
 	return nil
 }
 
@@ -40,3 +50,100 @@ func loadFile(filename string) ([]string, error) {
 
 	return lines, nil
 }
+
+// normalizeOptions controls how normalizeText rewrites a file
+type normalizeOptions struct {
+	crlf         bool // write CRLF instead of LF
+	tabWidth     int  // expand tabs to this many columns (0 keeps tabs)
+	trimTrailing bool // remove trailing spaces and tabs
+}
+
+// normalizeText converts a file to the form the editor expects: UTF-8
+// without a byte-order mark and with consistent line endings. It returns
+// the new content and a description of each change made.
+func normalizeText(data []byte, opts normalizeOptions) ([]byte, []string) {
+	var changes []string
+
+	if bom := []byte("\xef\xbb\xbf"); bytes.HasPrefix(data, bom) {
+		data = data[len(bom):]
+		changes = append(changes, "removed byte-order mark")
+	}
+
+	text := string(data)
+	if !utf8.Valid(data) {
+		// Anything that isn't UTF-8 is treated as Latin-1, where every byte is a code point
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+		changes = append(changes, "converted Latin-1 to UTF-8")
+	}
+
+	lf := strings.ReplaceAll(text, "\r\n", "\n")
+	lf = strings.ReplaceAll(lf, "\r", "\n")
+	lines := strings.Split(lf, "\n")
+
+	if opts.tabWidth > 0 && strings.Contains(lf, "\t") {
+		for i, line := range lines {
+			lines[i] = expandTabs(line, opts.tabWidth)
+		}
+		changes = append(changes, "expanded tabs")
+	}
+
+	if opts.trimTrailing {
+		trimmed := false
+		for i, line := range lines {
+			if t := strings.TrimRight(line, " \t"); t != line {
+				lines[i] = t
+				trimmed = true
+			}
+		}
+		if trimmed {
+			changes = append(changes, "trimmed trailing whitespace")
+		}
+	}
+
+	eol := "\n"
+	if opts.crlf {
+		eol = "\r\n"
+	}
+	result := strings.Join(lines, eol)
+	if lineEndingsChanged(text, opts.crlf) {
+		if opts.crlf {
+			changes = append(changes, "converted line endings to CRLF")
+		} else {
+			changes = append(changes, "converted line endings to LF")
+		}
+	}
+	return []byte(result), changes
+}
+
+// lineEndingsChanged reports whether text has any line ending other than the wanted one
+func lineEndingsChanged(text string, crlf bool) bool {
+	crlfCount := strings.Count(text, "\r\n")
+	if crlf {
+		return strings.Count(text, "\n") != crlfCount || strings.Count(text, "\r") != crlfCount
+	}
+	return strings.Contains(text, "\r")
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop
+func expandTabs(line string, width int) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := width - col%width
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
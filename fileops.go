@@ -0,0 +1,508 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// trashDirName is the folder in the config directory that deleted files are moved to.
+// Each deletion is kept as trash/<stamp>/<name>, with its original path in trash/<stamp>.origin.
+const trashDirName = "trash"
+
+// trashPath returns the trash folder
+func trashPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, trashDirName), nil
+}
+
+// moveToTrash moves a file or folder into the trash and returns where it went
+func moveToTrash(path string) (string, error) {
+	trash, err := trashPath()
+	if err != nil {
+		return "", err
+	}
+	stamp := time.Now().Format("20060102-150405.000000000")
+	entry := filepath.Join(trash, stamp)
+	if err := os.MkdirAll(entry, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(entry+".origin", []byte(path+"\n"), 0644); err != nil {
+		return "", err
+	}
+	dest := filepath.Join(entry, filepath.Base(path))
+	if err := movePath(path, dest); err != nil {
+		os.Remove(entry + ".origin")
+		os.Remove(entry)
+		return "", err
+	}
+	return dest, nil
+}
+
+// restoreFromTrash moves the most recently deleted item back and returns its path
+func restoreFromTrash() (string, error) {
+	trash, err := trashPath()
+	if err != nil {
+		return "", err
+	}
+	origins, _ := filepath.Glob(filepath.Join(trash, "*.origin"))
+	if len(origins) == 0 {
+		return "", fmt.Errorf("the trash is empty")
+	}
+	sort.Strings(origins) // stamps sort by time
+	origin := origins[len(origins)-1]
+	entry := strings.TrimSuffix(origin, ".origin")
+
+	data, err := os.ReadFile(origin)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(string(data))
+	if _, err := os.Lstat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := movePath(filepath.Join(entry, filepath.Base(path)), path); err != nil {
+		return "", err
+	}
+	os.Remove(entry)
+	os.Remove(origin)
+	return path, nil
+}
+
+// movePath renames src to dst, copying when they are on different filesystems
+func movePath(src string, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	if _, statErr := os.Lstat(dst); statErr == nil {
+		return err
+	}
+	if copyErr := copyPath(src, dst); copyErr != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyPath copies a file, or a folder and everything in it
+func copyPath(src string, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if err := os.Mkdir(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// duplicateName picks a free "name copy.ext", "name copy 2.ext", ... next to path
+func duplicateName(path string) string {
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for i := 1; ; i++ {
+		name := stem + " copy" + ext
+		if i > 1 {
+			name = fmt.Sprintf("%s copy %d%s", stem, i, ext)
+		}
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// treeChildren finds the slice holding a folder's children and the depth they sit at.
+// It returns nil when the folder isn't in the tree or hasn't been read yet.
+func treeChildren(nodes *[]FileNode, root string, dir string) (*[]FileNode, int) {
+	if dir == root {
+		return nodes, 0
+	}
+	node := findTreeNode(*nodes, dir)
+	if node == nil || !node.IsDir || !node.Loaded {
+		return nil, 0
+	}
+	return &node.Children, node.Depth + 1
+}
+
+// insertTreeNode adds a node in tree order: folders first, then by name
+func insertTreeNode(nodes *[]FileNode, node FileNode) {
+	less := func(a, b FileNode) bool {
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	}
+	i := sort.Search(len(*nodes), func(i int) bool { return less(node, (*nodes)[i]) })
+	*nodes = append(*nodes, FileNode{})
+	copy((*nodes)[i+1:], (*nodes)[i:])
+	(*nodes)[i] = node
+}
+
+// removeTreeNode takes a node (and its children) out of the tree
+func removeTreeNode(nodes *[]FileNode, path string) (FileNode, bool) {
+	for i := range *nodes {
+		node := (*nodes)[i]
+		if node.Path == path {
+			*nodes = append((*nodes)[:i], (*nodes)[i+1:]...)
+			return node, true
+		}
+		if node.IsDir && strings.HasPrefix(path, node.Path+string(filepath.Separator)) {
+			return removeTreeNode(&(*nodes)[i].Children, path)
+		}
+	}
+	return FileNode{}, false
+}
+
+// rebaseTreeNode gives a moved node its new path and depth, and its children theirs
+func rebaseTreeNode(node *FileNode, path string, depth int) {
+	node.Path = path
+	node.Name = filepath.Base(path)
+	node.Depth = depth
+	for i := range node.Children {
+		child := &node.Children[i]
+		rebaseTreeNode(child, filepath.Join(path, child.Name), depth+1)
+	}
+}
+
+// addTreePath shows a new file or folder in the tree, if its folder is in the tree
+// and the tree would list it
+func (m *model) addTreePath(path string, node *FileNode) {
+	isDir := isDirPath(path)
+	if node != nil {
+		isDir = node.IsDir
+	}
+	filter := m.treeFilter()
+	if !filter.showsPath(path, isDir) {
+		return
+	}
+	siblings, depth := treeChildren(&m.fileTreeNodes, m.fileTreeRoot, filepath.Dir(path))
+	if siblings == nil {
+		return
+	}
+	// A new folder is read when it is first expanded
+	added := FileNode{Path: path, IsDir: isDir}
+	if node != nil {
+		added = *node
+	}
+	rebaseTreeNode(&added, path, depth)
+	if info, err := os.Stat(path); err == nil {
+		fillTreeNodeInfo(&added, info, filter.words)
+	}
+	insertTreeNode(siblings, added)
+	if filter.sortBy != treeSortName {
+		sortTreeNodes(*siblings, filepath.Dir(path), filter.sortBy)
+	}
+}
+
+// isDirPath reports whether path is an existing folder
+func isDirPath(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// selectTreePath expands the folders above path and moves the tree cursor to it
+func (m *model) selectTreePath(path string) {
+	rel, err := filepath.Rel(m.fileTreeRoot, path)
+	if err == nil && !strings.HasPrefix(rel, "..") {
+		dir := m.fileTreeRoot
+		parts := strings.Split(rel, string(filepath.Separator))
+		for _, part := range parts[:len(parts)-1] {
+			dir = filepath.Join(dir, part)
+			node := findTreeNode(m.fileTreeNodes, dir)
+			if node == nil {
+				break
+			}
+			m.loadTreeNodeNow(node)
+			node.Expanded = true
+		}
+	}
+	for i, node := range m.visibleTreeNodes() {
+		if node.Path == path {
+			m.fileTreeCursor = i
+			break
+		}
+	}
+	m.moveTreeCursor(0)
+	m.syncTreeWatches()
+}
+
+// fileOpTarget is the file or folder a file command works on: the tree
+// selection while the tree has focus, otherwise the open file
+func (m model) fileOpTarget() (string, bool) {
+	if m.fileTreeVisible && m.fileTreeFocused {
+		flatNodes := m.visibleTreeNodes()
+		if m.fileTreeCursor < len(flatNodes) {
+			return flatNodes[m.fileTreeCursor].Path, true
+		}
+		return "", false
+	}
+	if m.filename == "" {
+		return "", false
+	}
+	abs, err := filepath.Abs(m.filename)
+	return abs, err == nil
+}
+
+// resolveProjectPath makes a command argument absolute, relative to the tree root
+func (m model) resolveProjectPath(arg string) string {
+	if filepath.IsAbs(arg) {
+		return filepath.Clean(arg)
+	}
+	return filepath.Join(m.fileTreeRoot, arg)
+}
+
+// relativeToRoot shows a path relative to the tree root where possible
+func (m model) relativeToRoot(path string) string {
+	rel, err := filepath.Rel(m.fileTreeRoot, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// followMovedFile keeps the open file's name right when it, or a folder above it, moves
+func (m *model) followMovedFile(from string, to string) {
+	abs, err := filepath.Abs(m.filename)
+	if err != nil {
+		return
+	}
+	switch {
+	case abs == from:
+		m.filename = to
+	case strings.HasPrefix(abs, from+string(filepath.Separator)):
+		m.filename = to + strings.TrimPrefix(abs, from)
+	default:
+		return
+	}
+	LogInfof("Open file now at %s", m.filename)
+}
+
+// fileCommand runs :mkfile, :mkdir, :rename, :move, :duplicate, :delete and :undelete
+func (m model) fileCommand(name string, arg string) (tea.Model, tea.Cmd) {
+	if m.readOnly {
+		m.setStatus("Can't change files: "+errReadOnly.Error(), "yellow")
+		return m, nil
+	}
+
+	switch name {
+	case "mkfile", "mkdir":
+		if arg == "" {
+			m.setStatus("Usage: :"+name+" <path>", "yellow")
+			return m, nil
+		}
+		path := m.resolveProjectPath(arg)
+		if _, err := os.Lstat(path); err == nil {
+			m.setStatus(m.relativeToRoot(path)+" already exists", "red")
+			return m, nil
+		}
+		var err error
+		if name == "mkdir" {
+			err = os.MkdirAll(path, 0755)
+		} else if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			err = os.WriteFile(path, nil, 0644)
+		}
+		if err != nil {
+			m.setStatus("Create failed: "+err.Error(), "red")
+			return m, nil
+		}
+		// Folders created on the way are added with the new entry inside them
+		top := path
+		for dir := filepath.Dir(path); dir != m.fileTreeRoot && strings.HasPrefix(dir, m.fileTreeRoot); dir = filepath.Dir(dir) {
+			if findTreeNode(m.fileTreeNodes, dir) != nil {
+				break
+			}
+			top = dir
+		}
+		m.addTreePath(top, nil)
+		m.selectTreePath(path)
+		LogInfof("Created %s", path)
+		m.setStatus("Created "+m.relativeToRoot(path), "green")
+		return m, nil
+
+	case "undelete":
+		path, err := restoreFromTrash()
+		if err != nil {
+			m.setStatus("Undelete failed: "+err.Error(), "red")
+			return m, nil
+		}
+		m.addTreePath(path, nil)
+		m.selectTreePath(path)
+		LogInfof("Restored %s from the trash", path)
+		m.setStatus("Restored "+m.relativeToRoot(path), "green")
+		return m, nil
+	}
+
+	target, ok := m.fileOpTarget()
+	if name == "delete" && arg != "" {
+		// ":delete <path>" names what to delete
+		target, ok = m.resolveProjectPath(arg), true
+		if _, err := os.Lstat(target); err != nil {
+			m.setStatus("Delete failed: "+err.Error(), "red")
+			return m, nil
+		}
+	}
+	if !ok {
+		m.setStatus("Select a file in the tree first", "yellow")
+		return m, nil
+	}
+	if target == m.fileTreeRoot {
+		m.setStatus("Can't "+name+" the project folder", "yellow")
+		return m, nil
+	}
+
+	var dest string
+	switch name {
+	case "rename":
+		if arg == "" || strings.ContainsAny(arg, `/\`) {
+			m.setStatus("Usage: :rename <new name> (use :move to change folders)", "yellow")
+			return m, nil
+		}
+		dest = filepath.Join(filepath.Dir(target), arg)
+	case "move":
+		if arg == "" {
+			m.setStatus("Usage: :move <folder or new path>", "yellow")
+			return m, nil
+		}
+		dest = m.resolveProjectPath(arg)
+		if isDirPath(dest) {
+			dest = filepath.Join(dest, filepath.Base(target))
+		}
+		if dest == target || strings.HasPrefix(dest, target+string(filepath.Separator)) {
+			m.setStatus("Can't move a folder into itself", "red")
+			return m, nil
+		}
+	case "duplicate":
+		if arg == "" {
+			dest = duplicateName(target)
+		} else {
+			dest = filepath.Join(filepath.Dir(target), arg)
+		}
+	case "delete":
+		trashed, err := moveToTrash(target)
+		if err != nil {
+			m.setStatus("Delete failed: "+err.Error(), "red")
+			return m, nil
+		}
+		removeTreeNode(&m.fileTreeNodes, target)
+		m.moveTreeCursor(0)
+		LogInfof("Moved %s to %s", target, trashed)
+		status := "Deleted " + m.relativeToRoot(target) + " (:undelete restores it)"
+		if abs, _ := filepath.Abs(m.filename); abs == target || strings.HasPrefix(abs, target+string(filepath.Separator)) {
+			m.modified = true // :w writes the open file back
+			status = "Deleted " + m.relativeToRoot(target) + "; it is still open (:w keeps it, :undelete restores it)"
+		}
+		m.setStatus(status, "green")
+		return m, nil
+	}
+
+	if _, err := os.Lstat(dest); err == nil {
+		m.setStatus(m.relativeToRoot(dest)+" already exists", "red")
+		return m, nil
+	}
+
+	if name == "duplicate" {
+		if err := copyPath(target, dest); err != nil {
+			m.setStatus("Duplicate failed: "+err.Error(), "red")
+			return m, nil
+		}
+		m.addTreePath(dest, nil)
+		m.selectTreePath(dest)
+		LogInfof("Copied %s to %s", target, dest)
+		m.setStatus("Copied to "+m.relativeToRoot(dest), "green")
+		return m, nil
+	}
+
+	// Rename and move
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		m.setStatus("Move failed: "+err.Error(), "red")
+		return m, nil
+	}
+	if err := movePath(target, dest); err != nil {
+		m.setStatus("Move failed: "+err.Error(), "red")
+		return m, nil
+	}
+	if node, ok := removeTreeNode(&m.fileTreeNodes, target); ok {
+		m.addTreePath(dest, &node)
+	} else {
+		m.addTreePath(dest, nil)
+	}
+	m.selectTreePath(dest)
+	m.followMovedFile(target, dest)
+	LogInfof("Moved %s to %s", target, dest)
+	m.setStatus("Moved "+m.relativeToRoot(target)+" to "+m.relativeToRoot(dest), "green")
+	return m, nil
+}
+
+// promptFileCommand opens the command line with a file command ready to complete
+func (m *model) promptFileCommand(name string) {
+	target, ok := m.fileOpTarget()
+	folder := m.fileTreeRoot
+	if ok {
+		folder = filepath.Dir(target)
+		if isDirPath(target) && (name == "mkfile" || name == "mkdir") {
+			folder = target
+		}
+	}
+	prefix := ""
+	if rel := m.relativeToRoot(folder); rel != "." {
+		prefix = rel + string(filepath.Separator)
+	}
+
+	text := name + " "
+	switch name {
+	case "mkfile", "mkdir":
+		text += prefix
+	case "rename":
+		text += filepath.Base(target)
+	case "move":
+		text += prefix
+	case "duplicate":
+		text += filepath.Base(duplicateName(target))
+	case "delete":
+		text += m.relativeToRoot(target)
+	}
+	m.openCommandLine()
+	m.setCommandText(text)
+}
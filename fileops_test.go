@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newFileOpsTestModel focuses a tree over a small project, with the config directory in a temporary HOME
+func newFileOpsTestModel(t *testing.T) (model, string) {
+	t.Helper()
+	m := newCommandLineTestModel(t)
+	m = pressSpecial(t, m, tea.KeyEsc)
+	root := writeProject(t, map[string]string{
+		"chapters/one.md": "One",
+		"chapters/two.md": "Two",
+		"outline.md":      "Plan",
+	})
+	nodes, err := buildFileTree(root, treeFilter{root: root}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.fileTreeRoot = root
+	m.fileTreeNodes = nodes
+	m.fileTreeVisible = true
+	m.fileTreeFocused = true
+	return m, root
+}
+
+// treePaths lists the visible tree entries relative to root
+func treePaths(m model, root string) []string {
+	var paths []string
+	for _, node := range flattenFileTree(m.fileTreeNodes) {
+		rel, _ := filepath.Rel(root, node.Path)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+// runCommand submits a command line as if typed
+func runCommand(t *testing.T, m model, cmd string) model {
+	t.Helper()
+	result, _ := m.executeCommand(":" + cmd)
+	return result.(model)
+}
+
+func TestTreeCreateAndRename(t *testing.T) {
+	m, root := newFileOpsTestModel(t)
+
+	m = runCommand(t, m, "mkfile chapters/three.md")
+	if want := []string{"chapters", "chapters/one.md", "chapters/three.md", "chapters/two.md", "outline.md"}; !reflect.DeepEqual(treePaths(m, root), want) {
+		t.Fatalf("after mkfile: %q", treePaths(m, root))
+	}
+	if flattenFileTree(m.fileTreeNodes)[m.fileTreeCursor].Name != "three.md" {
+		t.Error("the new file should be selected")
+	}
+
+	m = runCommand(t, m, "mkdir notes/research")
+	if _, err := os.Stat(filepath.Join(root, "notes", "research")); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"chapters", "chapters/one.md", "chapters/three.md", "chapters/two.md", "notes", "notes/research", "outline.md"}; !reflect.DeepEqual(treePaths(m, root), want) {
+		t.Errorf("after mkdir: %q", treePaths(m, root))
+	}
+
+	// Renaming the open file's folder moves the buffer with it
+	m.filename = filepath.Join(root, "chapters", "one.md")
+	m.fileTreeCursor = 0
+	m = runCommand(t, m, "rename part-1")
+	if want := filepath.Join(root, "part-1", "one.md"); m.filename != want {
+		t.Errorf("filename = %q, want %q", m.filename, want)
+	}
+	if _, err := os.Stat(filepath.Join(root, "part-1", "two.md")); err != nil {
+		t.Error(err)
+	}
+	if paths := treePaths(m, root); !containsString(paths, "part-1/three.md") || containsString(paths, "chapters") {
+		t.Errorf("after rename: %q", paths)
+	}
+}
+
+func TestTreeMoveDuplicateDelete(t *testing.T) {
+	m, root := newFileOpsTestModel(t)
+	outline := filepath.Join(root, "outline.md")
+	m.selectTreePath(outline)
+
+	m = runCommand(t, m, "duplicate")
+	if data, err := os.ReadFile(filepath.Join(root, "outline copy.md")); err != nil || string(data) != "Plan" {
+		t.Fatalf("duplicate: %q, %v", data, err)
+	}
+
+	m.selectTreePath(outline)
+	m = runCommand(t, m, "move chapters")
+	moved := filepath.Join(root, "chapters", "outline.md")
+	if _, err := os.Stat(moved); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"chapters", "chapters/one.md", "chapters/outline.md", "chapters/two.md", "outline copy.md"}; !reflect.DeepEqual(treePaths(m, root), want) {
+		t.Errorf("after move: %q", treePaths(m, root))
+	}
+
+	m = runCommand(t, m, "delete chapters/outline.md")
+	if _, err := os.Stat(moved); !os.IsNotExist(err) {
+		t.Fatal("delete should remove the file")
+	}
+	if containsString(treePaths(m, root), "chapters/outline.md") {
+		t.Error("deleted file is still in the tree")
+	}
+
+	m = runCommand(t, m, "undelete")
+	if data, err := os.ReadFile(moved); err != nil || string(data) != "Plan" {
+		t.Fatalf("undelete: %q, %v", data, err)
+	}
+	if !containsString(treePaths(m, root), "chapters/outline.md") {
+		t.Error("restored file should be back in the tree")
+	}
+
+	m.readOnly = true
+	m = runCommand(t, m, "delete chapters/outline.md")
+	if _, err := os.Stat(moved); err != nil {
+		t.Error("read-only sessions must not delete files")
+	}
+}
+
+func TestTreeFileKeys(t *testing.T) {
+	m, root := newFileOpsTestModel(t)
+	m.selectTreePath(filepath.Join(root, "chapters", "one.md"))
+
+	m = pressKeys(t, m, "r")
+	if !m.commandMode || m.commandBuffer != ":rename one.md" {
+		t.Errorf("r: %q", m.commandBuffer)
+	}
+	m = pressSpecial(t, m, tea.KeyEsc)
+	m = pressKeys(t, m, "a")
+	if m.commandBuffer != ":mkfile chapters"+string(filepath.Separator) {
+		t.Errorf("a: %q", m.commandBuffer)
+	}
+}
@@ -3,93 +3,61 @@ package main
 import (
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// buildFileTree scans the directory and builds the file tree structure
-func buildFileTree(rootPath string) ([]FileNode, error) {
+// treeDirLoadedMsg delivers a folder's entries, read in the background
+type treeDirLoadedMsg struct {
+	root     string // tree root when the read started, to drop stale results
+	path     string
+	children []FileNode
+	hidden   int  // entries left out by the per-folder cap
+	refresh  bool // re-read after a change on disk, rather than a first expansion
+	err      error
+}
+
+// buildFileTree reads the top level of the tree. Folders are read when first expanded.
+func buildFileTree(rootPath string, filter treeFilter, limit int) ([]FileNode, error) {
 	// Get absolute path
 	absPath, err := filepath.Abs(rootPath)
 	if err != nil {
 		return nil, err
 	}
 
-	// Read root directory
-	entries, err := os.ReadDir(absPath)
+	filter.root = absPath
+	nodes, hidden, err := readTreeDir(absPath, 0, filter, limit)
 	if err != nil {
 		return nil, err
 	}
-
-	// Separate folders and files
-	var folders []FileNode
-	var files []FileNode
-
-	for _, entry := range entries {
-		// Skip hidden files and common non-document directories
-		name := entry.Name()
-		if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" {
-			continue
-		}
-
-		fullPath := filepath.Join(absPath, name)
-
-		node := FileNode{
-			Name:     name,
-			Path:     fullPath,
-			IsDir:    entry.IsDir(),
-			Expanded: false,
-			Depth:    0,
-		}
-
-		// For directories, recursively get children (but don't expand by default)
-		if entry.IsDir() {
-			children, _ := getDirectoryChildren(fullPath, 1)
-			node.Children = children
-			folders = append(folders, node)
-		} else {
-			// Only include text-like files
-			if isTextFile(name) {
-				files = append(files, node)
-			}
-		}
+	if hidden > 0 {
+		LogWarningf("File tree shows the first %d entries of %s (%d more)", limit, absPath, hidden)
 	}
-
-	// Sort folders and files alphabetically
-	sort.Slice(folders, func(i, j int) bool {
-		return strings.ToLower(folders[i].Name) < strings.ToLower(folders[j].Name)
-	})
-	sort.Slice(files, func(i, j int) bool {
-		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
-	})
-
-	// Combine: folders first, then files
-	result := append(folders, files...)
-
-	LogInfof("Built file tree with %d folders and %d files", len(folders), len(files))
-	return result, nil
+	LogInfof("Built file tree with %d entries", len(nodes))
+	return nodes, nil
 }
 
-// getDirectoryChildren recursively gets children for a directory
-func getDirectoryChildren(dirPath string, depth int) ([]FileNode, error) {
+// readTreeDir lists one folder's entries for the tree: folders first, then files,
+// each sorted by name. Only the first limit entries are kept; the number left out
+// is returned. Subfolders are not read.
+func readTreeDir(dirPath string, depth int, filter treeFilter, limit int) ([]FileNode, int, error) {
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	rules := loadIgnoreRules(filter.root, dirPath)
 
-	var folders []FileNode
-	var files []FileNode
+	var result []FileNode
 
 	for _, entry := range entries {
+		// Skip hidden, excluded and ignored entries, and files that aren't text or included
 		name := entry.Name()
-		if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" {
+		fullPath := filepath.Join(dirPath, name)
+		if !filter.shows(fullPath, entry.IsDir(), rules) {
 			continue
 		}
 
-		fullPath := filepath.Join(dirPath, name)
-
 		node := FileNode{
 			Name:     name,
 			Path:     fullPath,
@@ -97,28 +65,71 @@ func getDirectoryChildren(dirPath string, depth int) ([]FileNode, error) {
 			Expanded: false,
 			Depth:    depth,
 		}
-
-		if entry.IsDir() {
-			// Get children for subdirectories
-			children, _ := getDirectoryChildren(fullPath, depth+1)
-			node.Children = children
-			folders = append(folders, node)
-		} else {
-			if isTextFile(name) {
-				files = append(files, node)
-			}
+		if info, err := entry.Info(); err == nil {
+			fillTreeNodeInfo(&node, info, filter.words)
 		}
+		result = append(result, node)
 	}
 
-	// Sort folders and files
-	sort.Slice(folders, func(i, j int) bool {
-		return strings.ToLower(folders[i].Name) < strings.ToLower(folders[j].Name)
-	})
-	sort.Slice(files, func(i, j int) bool {
-		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
-	})
+	// Folders first, then files, in the tree_sort order
+	sortTreeNodes(result, dirPath, filter.sortBy)
+	hidden := 0
+	if limit > 0 && len(result) > limit {
+		hidden = len(result) - limit
+		result = result[:limit]
+	}
+	return result, hidden, nil
+}
 
-	return append(folders, files...), nil
+// loadTreeDir returns a command that reads a folder's entries in the background
+func loadTreeDir(root string, path string, depth int, filter treeFilter, limit int) tea.Cmd {
+	return func() tea.Msg {
+		children, hidden, err := readTreeDir(path, depth, filter, limit)
+		return treeDirLoadedMsg{root: root, path: path, children: children, hidden: hidden, err: err}
+	}
+}
+
+// handleTreeDirLoaded fills in a folder once its entries have been read
+func (m model) handleTreeDirLoaded(msg treeDirLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.root != m.fileTreeRoot {
+		return m, nil // the tree was rebuilt in the meantime
+	}
+	if msg.refresh {
+		m.applyTreeRefresh(msg)
+		return m, nil
+	}
+	node := findTreeNode(m.fileTreeNodes, msg.path)
+	if node == nil || !node.Loading {
+		return m, nil
+	}
+	node.Loading = false
+	if msg.err != nil {
+		LogWarningf("Failed to read %s: %v", msg.path, msg.err)
+		node.Expanded = false
+		m.setStatus("Can't open folder: "+msg.err.Error(), "red")
+		return m, nil
+	}
+	node.Children = msg.children
+	node.Hidden = msg.hidden
+	node.Loaded = true
+	LogDebugf("Loaded %d entries of %s", len(msg.children), msg.path)
+	m.moveTreeCursor(0)
+	m.syncTreeWatches()
+	return m, nil
+}
+
+// findTreeNode returns the node for path, or nil when the tree doesn't hold it
+func findTreeNode(nodes []FileNode, path string) *FileNode {
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Path == path {
+			return node
+		}
+		if node.IsDir && strings.HasPrefix(path, node.Path+string(filepath.Separator)) {
+			return findTreeNode(node.Children, path)
+		}
+	}
+	return nil
 }
 
 // isTextFile checks if a file is likely a text file we want to show
@@ -176,81 +187,113 @@ func (m model) toggleFileTree() (tea.Model, tea.Cmd) {
 		m.setStatus("File tree closed", "yellow")
 	}
 
+	// The editor column changed width
+	m.rewrapLines()
+
 	return m, nil
 }
 
-// handleFileTreeNavigation handles navigation within the file tree
-func (m model) handleFileTreeNavigation(key string) (tea.Model, tea.Cmd) {
+// moveTreeCursor moves the file tree selection by delta rows, scrolling to keep it visible
+func (m *model) moveTreeCursor(delta int) {
 	if !m.fileTreeVisible || !m.fileTreeFocused {
-		return m, nil
+		return
 	}
 
-	flatNodes := flattenFileTree(m.fileTreeNodes)
-	visibleHeight := m.height - 2 // Leave room for status bar
+	flatNodes := m.visibleTreeNodes()
+	visibleHeight := m.treeListHeight()
 
-	switch key {
-	case "up", "k":
-		if m.fileTreeCursor > 0 {
-			m.fileTreeCursor--
-			// Scroll up if cursor goes above viewport
-			if m.fileTreeCursor < m.fileTreeOffset {
-				m.fileTreeOffset = m.fileTreeCursor
-			}
-		}
+	m.fileTreeCursor += delta
+	if m.fileTreeCursor >= len(flatNodes) {
+		m.fileTreeCursor = len(flatNodes) - 1
+	}
+	if m.fileTreeCursor < 0 {
+		m.fileTreeCursor = 0
+	}
 
-	case "down", "j":
-		if m.fileTreeCursor < len(flatNodes)-1 {
-			m.fileTreeCursor++
-			// Scroll down if cursor goes below viewport
-			if m.fileTreeCursor >= m.fileTreeOffset+visibleHeight {
-				m.fileTreeOffset = m.fileTreeCursor - visibleHeight + 1
-			}
-		}
+	// Scroll up if cursor goes above viewport
+	if m.fileTreeCursor < m.fileTreeOffset {
+		m.fileTreeOffset = m.fileTreeCursor
+	}
+	// Scroll down if cursor goes below viewport
+	if m.fileTreeCursor >= m.fileTreeOffset+visibleHeight {
+		m.fileTreeOffset = m.fileTreeCursor - visibleHeight + 1
+	}
+}
+
+// openTreeSelection toggles the selected directory or opens the selected file
+func (m model) openTreeSelection() (tea.Model, tea.Cmd) {
+	if !m.fileTreeVisible || !m.fileTreeFocused {
+		return m, nil
+	}
 
-	case "enter":
-		// Toggle directory or open file
-		if m.fileTreeCursor < len(flatNodes) {
-			node := flatNodes[m.fileTreeCursor]
-
-			if node.IsDir {
-				// Toggle directory expansion
-				m.toggleDirectory(node.Path)
-				LogDebugf("Toggled directory: %s", node.Name)
-			} else {
-				// Open file for editing in current instance
-				LogInfof("Opening file: %s", node.Path)
-				return m.openFileInCurrentInstance(node.Path)
-			}
+	flatNodes := m.visibleTreeNodes()
+	if m.fileTreeCursor < len(flatNodes) {
+		node := flatNodes[m.fileTreeCursor]
+
+		if node.IsDir {
+			// Toggle directory expansion
+			LogDebugf("Toggled directory: %s", node.Name)
+			return m, m.toggleDirectory(node.Path)
+		} else {
+			// Open file for editing in current instance
+			LogInfof("Opening file: %s", node.Path)
+			return m.openFileInCurrentInstance(node.Path)
 		}
 	}
 
 	return m, nil
 }
 
-// toggleDirectory expands or collapses a directory in the tree
-func (m *model) toggleDirectory(path string) {
-	m.toggleDirectoryRecursive(&m.fileTreeNodes, path)
+// focusFileTree shows the file tree and gives it focus
+func (m *model) focusFileTree() {
+	if m.zenMode {
+		return
+	}
+	if !m.fileTreeVisible {
+		m.fileTreeVisible = true
+		m.rewrapLines()
+	}
+	m.fileTreeFocused = true
 }
 
-// toggleDirectoryRecursive recursively finds and toggles a directory
-func (m *model) toggleDirectoryRecursive(nodes *[]FileNode, path string) bool {
-	for i := range *nodes {
-		node := &(*nodes)[i]
-
-		if node.Path == path && node.IsDir {
-			node.Expanded = !node.Expanded
-			LogDebugf("Directory %s now expanded=%v", node.Name, node.Expanded)
-			return true
+// toggleDirectory expands or collapses a directory in the tree. Expanding a
+// folder for the first time reads it in the background.
+func (m *model) toggleDirectory(path string) tea.Cmd {
+	node := findTreeNode(m.fileTreeNodes, path)
+	if node == nil || !node.IsDir {
+		return nil
+	}
+	node.Expanded = !node.Expanded
+	LogDebugf("Directory %s now expanded=%v", node.Name, node.Expanded)
+	if node.Loading {
+		return nil
+	}
+	if !node.Expanded || node.Loaded {
+		// Collapsed folders aren't watched, so one shown again is read again
+		m.syncTreeWatches()
+		if node.Expanded {
+			return m.refreshTreeDir(node.Path)
 		}
+		return nil
+	}
+	node.Loading = true
+	return loadTreeDir(m.fileTreeRoot, node.Path, node.Depth+1, m.treeFilter(), m.config.TreeMaxEntries)
+}
 
-		// Search in children
-		if node.IsDir && len(node.Children) > 0 {
-			if m.toggleDirectoryRecursive(&node.Children, path) {
-				return true
-			}
-		}
+// loadTreeNodeNow reads a folder's entries straight away, for revealing a path inside it
+func (m *model) loadTreeNodeNow(node *FileNode) {
+	if node.Loaded {
+		return
+	}
+	children, hidden, err := readTreeDir(node.Path, node.Depth+1, m.treeFilter(), m.config.TreeMaxEntries)
+	if err != nil {
+		LogWarningf("Failed to read %s: %v", node.Path, err)
+		return
 	}
-	return false
+	node.Children = children
+	node.Hidden = hidden
+	node.Loaded = true
+	node.Loading = false // a background read still on its way is ignored
 }
 
 // initFileTree initializes the file tree for the current working directory
@@ -268,7 +311,7 @@ func (m *model) initFileTree() error {
 	m.fileTreeRoot = dir
 
 	// Build the file tree
-	nodes, err := buildFileTree(dir)
+	nodes, err := buildFileTree(dir, m.treeFilter(), m.config.TreeMaxEntries)
 	if err != nil {
 		LogErrorf("Failed to build file tree: %v", err)
 		return err
@@ -276,6 +319,7 @@ func (m *model) initFileTree() error {
 
 	m.fileTreeNodes = nodes
 	m.fileTreeCursor = 0
+	m.syncTreeWatches()
 
 	LogInfof("Initialized file tree for directory: %s", dir)
 	return nil
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLazyFileTree(t *testing.T) {
+	files := map[string]string{"outline.md": "", "chapters/part-1/one.md": ""}
+	for i := 0; i < 12; i++ {
+		files[fmt.Sprintf("notes/note-%02d.md", i)] = ""
+	}
+	root := writeProject(t, files)
+
+	nodes, err := buildFileTree(root, treeFilter{root: root}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{
+		config:          defaultConfig(),
+		width:           80,
+		height:          24,
+		fileTreeRoot:    root,
+		fileTreeNodes:   nodes,
+		fileTreeVisible: true,
+		fileTreeFocused: true,
+	}
+	m.config.TreeMaxEntries = 10
+	if got := treePaths(m, root); !reflect.DeepEqual(got, []string{"chapters", "notes", "outline.md"}) {
+		t.Fatalf("top level = %q", got)
+	}
+	if nodes[0].Loaded || len(nodes[0].Children) != 0 {
+		t.Error("folders should not be read before they are expanded")
+	}
+
+	// Expanding reads the folder in the background
+	notes := filepath.Join(root, "notes")
+	cmd := m.toggleDirectory(notes)
+	if cmd == nil {
+		t.Fatal("expanding an unread folder should start a read")
+	}
+	flat := flattenFileTree(m.fileTreeNodes)
+	if !strings.Contains(m.renderFileTreeRow(flat, 1, 40), "loading") {
+		t.Errorf("row = %q", m.renderFileTreeRow(flat, 1, 40))
+	}
+
+	result, _ := m.Update(cmd())
+	m = result.(model)
+	node := findTreeNode(m.fileTreeNodes, notes)
+	if node.Loading || !node.Loaded || len(node.Children) != 10 || node.Hidden != 2 {
+		t.Fatalf("notes: loaded %v, %d children, %d hidden", node.Loaded, len(node.Children), node.Hidden)
+	}
+	if node.Children[0].Depth != 1 {
+		t.Errorf("child depth = %d", node.Children[0].Depth)
+	}
+	flat = flattenFileTree(m.fileTreeNodes)
+	if !strings.Contains(m.renderFileTreeRow(flat, 1, 40), "+2 more") {
+		t.Errorf("row = %q", m.renderFileTreeRow(flat, 1, 40))
+	}
+
+	// Expanding it again refreshes the entries in place, without a loading marker
+	m.toggleDirectory(notes)
+	cmd = m.toggleDirectory(notes)
+	if cmd == nil || findTreeNode(m.fileTreeNodes, notes).Loading {
+		t.Fatal("a folder shown again should be refreshed in place")
+	}
+	if msg := cmd().(treeDirLoadedMsg); !msg.refresh {
+		t.Error("re-expanding should refresh, not load")
+	}
+
+	// A read that finishes after the tree was rebuilt is dropped
+	cmd = m.toggleDirectory(filepath.Join(root, "chapters"))
+	m.fileTreeRoot = t.TempDir()
+	result, _ = m.Update(cmd())
+	if findTreeNode(result.(model).fileTreeNodes, filepath.Join(root, "chapters")).Loaded {
+		t.Error("stale read was applied")
+	}
+}
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// recentFilesFile lists recently opened files, in the config directory
+const recentFilesFile = "recent_files"
+
+// recentFilesLimit is how many recently opened files are remembered
+const recentFilesLimit = 50
+
+// finderFileLimit caps the files the finder scans, so a huge directory can't stall it
+const finderFileLimit = 20000
+
+// finderRows is the most files listed at once
+const finderRows = 12
+
+// finderPreviewBytes is how much of the selected file is read for the preview
+const finderPreviewBytes = 16 * 1024
+
+// finderItem is a project file matching the finder's query
+type finderItem struct {
+	path      string // relative to the tree root
+	positions []int  // matched byte offsets in path, for highlighting
+}
+
+// finderFilesMsg delivers the result of scanning the project in the background
+type finderFilesMsg struct {
+	root      string
+	files     []string
+	truncated bool
+	err       error
+}
+
+// scanProjectFiles lists the files the tree would show under root, relative to it.
+// It stops after limit files and reports whether it did.
+func scanProjectFiles(root string, filter treeFilter, limit int) ([]string, bool, error) {
+	var files []string
+	truncated := false
+	filter.root = root
+	rules := make(map[string][]ignoreRule) // per folder
+	rulesFor := func(dir string) []ignoreRule {
+		if _, ok := rules[dir]; !ok {
+			rules[dir] = loadIgnoreRules(root, dir)
+		}
+		return rules[dir]
+	}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable folders are skipped, as in the tree
+			if d != nil && d.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		if !filter.shows(path, d.IsDir(), rulesFor(filepath.Dir(path))) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if len(files) >= limit {
+			truncated = true
+			return fs.SkipAll
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(files)
+	return files, truncated, err
+}
+
+// scanFinderFiles returns a command that scans the project for the finder
+func scanFinderFiles(root string, filter treeFilter) tea.Cmd {
+	return func() tea.Msg {
+		files, truncated, err := scanProjectFiles(root, filter, finderFileLimit)
+		return finderFilesMsg{root: root, files: files, truncated: truncated, err: err}
+	}
+}
+
+// rankFiles orders the files matching a query. A match in the file name beats
+// one spread across folders, and recently opened files (recent is oldest first,
+// holding paths relative to the root) rank higher. Without a query the recent
+// files come first, newest first.
+func rankFiles(files []string, query string, recent []string) []finderItem {
+	recency := make(map[string]int)
+	for i, path := range recent {
+		recency[path] = i + 1
+	}
+
+	type scored struct {
+		item  finderItem
+		score int
+	}
+	var matches []scored
+	for _, path := range files {
+		score, positions, ok := fuzzyMatch(query, path)
+		if !ok {
+			continue
+		}
+		if query != "" {
+			base := path[strings.LastIndex(path, "/")+1:]
+			if baseScore, _, ok := fuzzyMatch(query, base); ok {
+				score += baseScore
+			}
+		}
+		if r, ok := recency[path]; ok {
+			if query == "" {
+				score = 1000 + r
+			} else {
+				score += 5 + r*5/len(recent)
+			}
+		}
+		matches = append(matches, scored{finderItem{path: path, positions: positions}, score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].item.path) < len(matches[j].item.path)
+	})
+	items := make([]finderItem, len(matches))
+	for i, s := range matches {
+		items[i] = s.item
+	}
+	return items
+}
+
+// recentFilesPath returns where recently opened files are stored
+func recentFilesPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, recentFilesFile), nil
+}
+
+// loadRecentFiles reads recently opened files, oldest first
+func loadRecentFiles() []string {
+	path, err := recentFilesPath()
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var recent []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			recent = append(recent, line)
+		}
+	}
+	return recent
+}
+
+// rememberRecentFile records an opened file (an absolute path) as the newest
+func rememberRecentFile(path string) {
+	recent := []string{}
+	for _, p := range loadRecentFiles() {
+		if p != path {
+			recent = append(recent, p)
+		}
+	}
+	recent = append(recent, path)
+	if len(recent) > recentFilesLimit {
+		recent = recent[len(recent)-recentFilesLimit:]
+	}
+
+	file, err := recentFilesPath()
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(file), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(file, []byte(strings.Join(recent, "\n")+"\n"), 0644)
+	}
+	if err != nil {
+		LogWarningf("Failed to save recent files: %v", err)
+	}
+}
+
+// openFinder shows the file finder and starts scanning the project
+func (m *model) openFinder() tea.Cmd {
+	if m.fileTreeRoot == "" {
+		m.setStatus("No project folder to search", "yellow")
+		return nil
+	}
+	m.finderVisible = true
+	m.finderQuery = ""
+	m.finderLoading = true
+	m.finderFiles = nil
+	m.finderItems = nil
+	m.finderCursor = 0
+	m.finderOffset = 0
+	m.finderPreviewPath = ""
+	m.finderRecent = m.recentFilesUnderRoot()
+	m.adjustViewport()
+	return scanFinderFiles(m.fileTreeRoot, m.treeFilter())
+}
+
+// closeFinder hides the file finder
+func (m *model) closeFinder() {
+	m.finderVisible = false
+	m.finderFiles = nil
+	m.finderItems = nil
+	m.finderPreview = nil
+	m.adjustViewport()
+}
+
+// handleFinderFiles fills the finder once the scan finishes
+func (m model) handleFinderFiles(msg finderFilesMsg) (tea.Model, tea.Cmd) {
+	if !m.finderVisible || msg.root != m.fileTreeRoot {
+		return m, nil // closed, or a different project, in the meantime
+	}
+	m.finderLoading = false
+	if msg.err != nil {
+		LogWarningf("File finder scan of %s: %v", msg.root, msg.err)
+	}
+	if msg.truncated {
+		m.setStatus(formatCount(finderFileLimit)+" files shown; the folder has more", "yellow")
+	}
+	m.finderFiles = msg.files
+	m.refreshFinder()
+	return m, nil
+}
+
+// recentFilesUnderRoot returns recently opened files under the tree root, relative to it
+func (m model) recentFilesUnderRoot() []string {
+	var recent []string
+	for _, path := range loadRecentFiles() {
+		rel, err := filepath.Rel(m.fileTreeRoot, path)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			recent = append(recent, filepath.ToSlash(rel))
+		}
+	}
+	return recent
+}
+
+// refreshFinder re-ranks the files after the query changes
+func (m *model) refreshFinder() {
+	m.finderItems = rankFiles(m.finderFiles, m.finderQuery, m.finderRecent)
+	m.finderCursor = 0
+	m.finderOffset = 0
+	m.loadFinderPreview()
+}
+
+// moveFinderCursor moves the selection, scrolling to keep it visible
+func (m *model) moveFinderCursor(step int) {
+	if len(m.finderItems) == 0 {
+		return
+	}
+	m.finderCursor = max(0, min(len(m.finderItems)-1, m.finderCursor+step))
+	rows := m.finderListRows()
+	if m.finderCursor < m.finderOffset {
+		m.finderOffset = m.finderCursor
+	}
+	if m.finderCursor >= m.finderOffset+rows {
+		m.finderOffset = m.finderCursor - rows + 1
+	}
+	m.loadFinderPreview()
+}
+
+// loadFinderPreview reads the first lines of the selected file
+func (m *model) loadFinderPreview() {
+	if len(m.finderItems) == 0 {
+		m.finderPreview = nil
+		m.finderPreviewPath = ""
+		return
+	}
+	path := filepath.Join(m.fileTreeRoot, filepath.FromSlash(m.finderItems[m.finderCursor].path))
+	if path == m.finderPreviewPath {
+		return
+	}
+	m.finderPreviewPath = path
+	m.finderPreview = readPreview(path, m.finderListRows())
+}
+
+// readPreview returns up to n lines from the start of a file, tabs expanded
+func readPreview(path string, n int) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return []string{"(" + err.Error() + ")"}
+	}
+	defer f.Close()
+
+	buf := make([]byte, finderPreviewBytes)
+	count, _ := f.Read(buf)
+	text := string(buf[:count])
+	if strings.IndexByte(text, 0) >= 0 || !utf8.ValidString(text) && !utf8.ValidString(text[:max(0, len(text)-utf8.UTFMax)]) {
+		return []string{"(binary file)"}
+	}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(line, "\t", "    ")
+	}
+	return lines
+}
+
+// handleFinderKeys edits the query, moves the selection and opens the chosen file
+func (m model) handleFinderKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c", "ctrl+g":
+		m.closeFinder()
+	case "enter":
+		return m.openFinderSelection()
+	case "up", "ctrl+p", "ctrl+k", "shift+tab":
+		m.moveFinderCursor(-1)
+	case "down", "ctrl+n", "ctrl+j", "tab":
+		m.moveFinderCursor(1)
+	case "pgup":
+		m.moveFinderCursor(-m.finderListRows())
+	case "pgdown":
+		m.moveFinderCursor(m.finderListRows())
+	case "backspace":
+		if m.finderQuery != "" {
+			_, size := utf8.DecodeLastRuneInString(m.finderQuery)
+			m.finderQuery = m.finderQuery[:len(m.finderQuery)-size]
+			m.refreshFinder()
+		}
+	case "ctrl+u":
+		m.finderQuery = ""
+		m.refreshFinder()
+	case "ctrl+w":
+		m.finderQuery = strings.TrimRight(m.finderQuery, " /")
+		if i := strings.LastIndexAny(m.finderQuery, " /._-"); i >= 0 {
+			m.finderQuery = m.finderQuery[:i+1]
+		} else {
+			m.finderQuery = ""
+		}
+		m.refreshFinder()
+	default:
+		if msg.Type == tea.KeyRunes && !msg.Alt || msg.Type == tea.KeySpace {
+			m.finderQuery += string(msg.Runes)
+			m.refreshFinder()
+		}
+	}
+	return m, nil
+}
+
+// openFinderSelection closes the finder and opens the selected file
+func (m model) openFinderSelection() (tea.Model, tea.Cmd) {
+	if len(m.finderItems) == 0 {
+		return m, nil
+	}
+	path := filepath.Join(m.fileTreeRoot, filepath.FromSlash(m.finderItems[m.finderCursor].path))
+	m.closeFinder()
+	if path == m.filename {
+		return m, nil
+	}
+	if m.modified && !m.readOnly {
+		m.setStatus("Unsaved changes (:w first)", "yellow")
+		return m, nil
+	}
+	LogInfof("Opening file from the finder: %s", path)
+	return m.openFileInCurrentInstance(path)
+}
+
+// finderListRows returns how many files fit in the finder
+func (m model) finderListRows() int {
+	return max(1, min(finderRows, m.height/2-1))
+}
+
+// finderPaneHeight returns the rows taken by the finder (0 when hidden)
+func (m model) finderPaneHeight() int {
+	if !m.finderVisible {
+		return 0
+	}
+	return m.finderListRows() + 1 // +1 for the query row
+}
+
+// renderFinder draws the query row, the matching files and a preview of the selected one
+func (m model) renderFinder() string {
+	titleStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.title.bg")).
+		Foreground(m.themeColor("pane.title.fg")).
+		Bold(monochrome())
+	rowStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.bg")).
+		Foreground(m.themeColor("pane.fg"))
+	selectedStyle := rowStyle.
+		Background(m.themeColor("pane.selected.bg")).
+		Foreground(m.themeColor("pane.selected.fg")).
+		Reverse(monochrome())
+
+	// Query row, with the match count on the right
+	prompt := " Open: " + m.finderQuery + "▏"
+	count := formatCount(len(m.finderItems)) + " of " + formatCount(len(m.finderFiles)) + " files | enter esc "
+	if m.finderLoading {
+		count = "scanning... "
+	}
+	gap := max(1, m.width-lipgloss.Width(prompt)-lipgloss.Width(count))
+	rows := []string{titleStyle.Render(truncateRunes(prompt+strings.Repeat(" ", gap)+count, m.width))}
+
+	// The preview takes the right half of wide terminals
+	listWidth := m.width
+	previewWidth := 0
+	if m.width >= 60 {
+		listWidth = m.width * 2 / 5
+		previewWidth = m.width - listWidth - 1
+	}
+
+	for i := 0; i < m.finderListRows(); i++ {
+		var b strings.Builder
+		idx := m.finderOffset + i
+		if idx < len(m.finderItems) {
+			style := rowStyle
+			if idx == m.finderCursor {
+				style = selectedStyle
+			}
+			item := m.finderItems[idx]
+			path := item.path
+			positions := item.positions
+			// Long paths keep their end, where the file name is
+			if room := listWidth - 2; utf8.RuneCountInString(path) > room && room > 1 {
+				cut := len(path) - len(string([]rune(path)[utf8.RuneCountInString(path)-room+1:]))
+				path = "…" + path[cut:]
+				var shifted []int
+				for _, p := range positions {
+					if p >= cut {
+						shifted = append(shifted, p-cut+len("…"))
+					}
+				}
+				positions = shifted
+			}
+			b.WriteString(style.Render(" "))
+			b.WriteString(renderMatched(path, positions, style))
+			b.WriteString(style.Render(strings.Repeat(" ", max(0, listWidth-1-utf8.RuneCountInString(path)))))
+		} else {
+			b.WriteString(rowStyle.Width(listWidth).Render(""))
+		}
+
+		if previewWidth > 0 {
+			line := ""
+			if i < len(m.finderPreview) {
+				line = truncateRunes(m.finderPreview[i], previewWidth-1)
+			}
+			b.WriteString(rowStyle.Foreground(m.themeColor("pane.title.bg")).Render("│"))
+			b.WriteString(rowStyle.Width(previewWidth).Render(" " + line))
+		}
+		rows = append(rows, b.String())
+	}
+	return strings.Join(rows, "\n")
+}
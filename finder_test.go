@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// writeProject creates files (with parent folders) under a new temporary directory
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestScanProjectFiles(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"draft.md":                "",
+		"chapters/one.md":         "",
+		"chapters/cover.png":      "",
+		".git/config":             "",
+		"vendor/lib/readme.md":    "",
+		"notes/research/links.md": "",
+	})
+
+	files, truncated, err := scanProjectFiles(root, treeFilter{root: root, excludeDirs: []string{"vendor"}}, 100)
+	if err != nil || truncated {
+		t.Fatalf("scan: %v, truncated %v", err, truncated)
+	}
+	if want := []string{"chapters/one.md", "draft.md", "notes/research/links.md"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %q, want %q", files, want)
+	}
+
+	if files, truncated, _ := scanProjectFiles(root, treeFilter{root: root}, 2); len(files) != 2 || !truncated {
+		t.Errorf("limit: %q, truncated %v", files, truncated)
+	}
+}
+
+func TestRankFiles(t *testing.T) {
+	files := []string{"chapters/one.md", "chapters/two.md", "notes/chapter-ideas.md", "outline.md"}
+	paths := func(items []finderItem) []string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item.path)
+		}
+		return out
+	}
+
+	// A match in the file name beats one spread over the folder name
+	if got := paths(rankFiles(files, "chap", nil)); got[0] != "notes/chapter-ideas.md" {
+		t.Errorf("chap = %q", got)
+	}
+	if got := paths(rankFiles(files, "two", nil)); !reflect.DeepEqual(got, []string{"chapters/two.md"}) {
+		t.Errorf("two = %q", got)
+	}
+
+	// Recently opened files come first, newest first
+	recent := []string{"outline.md", "chapters/two.md"}
+	if got := paths(rankFiles(files, "", recent)); !reflect.DeepEqual(got[:2], []string{"chapters/two.md", "outline.md"}) {
+		t.Errorf("recent first = %q", got)
+	}
+	if got := paths(rankFiles(files, "md", recent)); !reflect.DeepEqual(got[:2], []string{"chapters/two.md", "outline.md"}) {
+		t.Errorf("recent files should break ties, got %q", got)
+	}
+}
+
+func TestFinderOpensFile(t *testing.T) {
+	m := newCommandLineTestModel(t)
+	m = pressSpecial(t, m, tea.KeyEsc)
+	root := writeProject(t, map[string]string{
+		"chapters/one.md": "It was a dark night.\nSecond line.\n",
+		"outline.md":      "Plan\n",
+	})
+	m.fileTreeRoot = root
+
+	result, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = result.(model)
+	if !m.finderVisible || !m.finderLoading || cmd == nil {
+		t.Fatal("ctrl+o should open the finder and start a scan")
+	}
+	result, _ = m.Update(cmd())
+	m = result.(model)
+	if len(m.finderItems) != 2 {
+		t.Fatalf("items = %+v", m.finderItems)
+	}
+
+	m = pressKeys(t, m, "o", "n", "e")
+	if m.finderItems[0].path != "chapters/one.md" || !reflect.DeepEqual(m.finderPreview[:2], []string{"It was a dark night.", "Second line."}) {
+		t.Fatalf("selection %q, preview %q", m.finderItems[0].path, m.finderPreview)
+	}
+
+	m = pressSpecial(t, m, tea.KeyEnter)
+	want := filepath.Join(root, "chapters", "one.md")
+	if m.finderVisible || m.filename != want || m.lines[0] != "It was a dark night." {
+		t.Errorf("opened %q (finder visible %v)", m.filename, m.finderVisible)
+	}
+	if recent := loadRecentFiles(); len(recent) == 0 || recent[len(recent)-1] != want {
+		t.Errorf("recent files = %q", recent)
+	}
+}
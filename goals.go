@@ -0,0 +1,451 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Files in the config directory
+const (
+	historyFile = "history.jsonl" // one writing session per line
+	goalsFile   = "goals.json"    // word-count goals per project and file
+)
+
+// chartDays is how many days the :goals chart covers
+const chartDays = 14
+
+// dayFormat keys history totals by calendar day
+const dayFormat = "2006-01-02"
+
+// writingSession records the words added and removed while a file was open
+type writingSession struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	File    string    `json:"file"`
+	Project string    `json:"project"`
+	Added   int       `json:"added"`
+	Removed int       `json:"removed"`
+	Kind    string    `json:"kind,omitempty"` // "" for editing sessions
+}
+
+// net returns the words gained during the session
+func (s writingSession) net() int {
+	return s.Added - s.Removed
+}
+
+// sessionTracker follows the word count of the open file
+type sessionTracker struct {
+	session    writingSession
+	lastCount  int // word count when last observed
+	priorToday int // net words written earlier today in this project
+}
+
+// projectGoals are the targets for one project
+type projectGoals struct {
+	Daily int            `json:"daily,omitempty"` // words per day
+	Files map[string]int `json:"files,omitempty"` // file path -> total word target
+}
+
+// newSessionTracker starts tracking a file with the given current word count
+func newSessionTracker(file string, project string, words int) *sessionTracker {
+	return &sessionTracker{
+		session: writingSession{
+			Start:   time.Now(),
+			File:    file,
+			Project: project,
+		},
+		lastCount: words,
+	}
+}
+
+// observe records the change since the last observed word count
+func (t *sessionTracker) observe(words int) {
+	delta := words - t.lastCount
+	if delta > 0 {
+		t.session.Added += delta
+	} else if delta < 0 {
+		t.session.Removed -= delta
+	}
+	t.lastCount = words
+}
+
+// today returns the net words written today in the project, including this session
+func (t *sessionTracker) today() int {
+	return t.priorToday + t.session.net()
+}
+
+// configFilePath returns the path of a file in the config directory, creating the directory
+func configFilePath(name string) (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, name), nil
+}
+
+// appendHistory adds a session to the history file
+func appendHistory(path string, session writingSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// loadHistory reads every session from the history file (empty if missing)
+func loadHistory(path string) ([]writingSession, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var sessions []writingSession
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var s writingSession
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			LogWarningf("Skipping bad history entry: %v", err)
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, scanner.Err()
+}
+
+// dailyTotals sums net words per day for a project
+func dailyTotals(sessions []writingSession, project string) map[string]int {
+	totals := make(map[string]int)
+	for _, s := range sessions {
+		if s.Project != project || s.Kind != "" {
+			continue // sprints overlap editing sessions, so they're not added again
+		}
+		totals[s.Start.Format(dayFormat)] += s.net()
+	}
+	return totals
+}
+
+// dayMet reports whether a day's total counts towards a streak
+func dayMet(total int, goal int) bool {
+	if goal > 0 {
+		return total >= goal
+	}
+	return total > 0
+}
+
+// computeStreaks returns the current streak (ending today, or yesterday if
+// today's goal isn't met yet) and the longest streak, in days
+func computeStreaks(totals map[string]int, goal int, today time.Time) (current int, longest int) {
+	if len(totals) == 0 {
+		return 0, 0
+	}
+
+	// Current streak
+	day := today
+	if !dayMet(totals[day.Format(dayFormat)], goal) {
+		day = day.AddDate(0, 0, -1)
+	}
+	for dayMet(totals[day.Format(dayFormat)], goal) {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	// Longest streak: walk from the earliest recorded day to today
+	earliest := today
+	for key := range totals {
+		if t, err := time.ParseInLocation(dayFormat, key, today.Location()); err == nil && t.Before(earliest) {
+			earliest = t
+		}
+	}
+	run := 0
+	for d := earliest; !d.After(today); d = d.AddDate(0, 0, 1) {
+		if dayMet(totals[d.Format(dayFormat)], goal) {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+
+	return current, longest
+}
+
+// progressBar renders a fixed-width bar such as "▰▰▰▱▱"
+func progressBar(done int, target int, width int) string {
+	if target <= 0 || width <= 0 {
+		return ""
+	}
+	filled := done * width / target
+	if filled > width {
+		filled = width
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	return strings.Repeat("▰", filled) + strings.Repeat("▱", width-filled)
+}
+
+// loadAllGoals reads the goals file (empty if missing)
+func loadAllGoals(path string) (map[string]projectGoals, error) {
+	goals := make(map[string]projectGoals)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return goals, nil
+		}
+		return goals, err
+	}
+	if err := json.Unmarshal(data, &goals); err != nil {
+		return make(map[string]projectGoals), fmt.Errorf("%s: %w", goalsFile, err)
+	}
+	return goals, nil
+}
+
+// saveProjectGoals stores the goals for one project
+func saveProjectGoals(path string, project string, goals projectGoals) error {
+	all, err := loadAllGoals(path)
+	if err != nil {
+		return err
+	}
+	all[project] = goals
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// startSession begins tracking the open file and loads the project's goals
+func (m *model) startSession() {
+	project := projectKey(m.projectDir())
+	words := m.wordCounter.count(m.lines)
+	m.session = newSessionTracker(m.filename, project, words)
+	if m.sprint != nil {
+		m.sprint.tracker.lastCount = words // a sprint continues across file switches
+	}
+
+	if path, err := configFilePath(historyFile); err == nil {
+		if sessions, err := loadHistory(path); err == nil {
+			m.session.priorToday = dailyTotals(sessions, project)[time.Now().Format(dayFormat)]
+		} else {
+			LogWarningf("Failed to read writing history: %v", err)
+		}
+	}
+
+	m.goals = projectGoals{}
+	if path, err := configFilePath(goalsFile); err == nil {
+		if all, err := loadAllGoals(path); err == nil {
+			m.goals = all[project]
+		} else {
+			LogWarningf("Failed to read goals: %v", err)
+		}
+	}
+}
+
+// endSession writes the current session to the history file if anything changed
+func (m *model) endSession() {
+	if m.session == nil {
+		return
+	}
+	m.trackWords()
+
+	s := m.session.session
+	m.session = nil
+	if s.Added == 0 && s.Removed == 0 {
+		return
+	}
+	s.End = time.Now()
+
+	path, err := configFilePath(historyFile)
+	if err == nil {
+		err = appendHistory(path, s)
+	}
+	if err != nil {
+		LogErrorf("Failed to record writing session: %v", err)
+		return
+	}
+	LogInfof("Recorded session for %s: +%d -%d words", filepath.Base(s.File), s.Added, s.Removed)
+}
+
+// trackWords updates the session with the document's current word count
+func (m *model) trackWords() {
+	if m.session == nil && m.sprint == nil {
+		return
+	}
+	words := m.wordCounter.count(m.lines)
+	if m.session != nil {
+		m.session.observe(words)
+	}
+	if m.sprint != nil {
+		m.sprint.tracker.observe(words)
+	}
+}
+
+// fileGoal returns the word target for the open file (0 if none)
+func (m model) fileGoal() int {
+	if m.goals.Files == nil {
+		return 0
+	}
+	return m.goals.Files[projectKey(m.filename)]
+}
+
+// goalStatus returns the goal progress shown in the status bar ("" without goals)
+func (m model) goalStatus() string {
+	var parts []string
+	if m.session != nil && m.goals.Daily > 0 {
+		today := m.session.today()
+		parts = append(parts, fmt.Sprintf("Today %s %s/%s", progressBar(today, m.goals.Daily, 10), formatCount(today), formatCount(m.goals.Daily)))
+	}
+	if goal := m.fileGoal(); goal > 0 {
+		words := m.wordCounter.count(m.lines)
+		parts = append(parts, fmt.Sprintf("File %s %d%%", progressBar(words, goal, 5), words*100/goal))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// setGoal handles ":goal [daily|file] <words|off>"
+func (m *model) setGoal(args []string) {
+	if len(args) == 0 {
+		m.setStatus(m.describeGoals(), "green")
+		return
+	}
+
+	kind := "daily"
+	if args[0] == "daily" || args[0] == "file" {
+		kind = args[0]
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		m.setStatus("Usage: :goal [daily|file] <words|off>", "yellow")
+		return
+	}
+
+	target := 0
+	if args[0] != "off" {
+		n, err := strconv.Atoi(strings.ReplaceAll(args[0], ",", ""))
+		if err != nil || n <= 0 {
+			m.setStatus("Goal must be a positive number of words", "red")
+			return
+		}
+		target = n
+	}
+
+	if kind == "daily" {
+		m.goals.Daily = target
+	} else {
+		if m.goals.Files == nil {
+			m.goals.Files = make(map[string]int)
+		}
+		if target == 0 {
+			delete(m.goals.Files, projectKey(m.filename))
+		} else {
+			m.goals.Files[projectKey(m.filename)] = target
+		}
+	}
+
+	path, err := configFilePath(goalsFile)
+	if err == nil {
+		err = saveProjectGoals(path, projectKey(m.projectDir()), m.goals)
+	}
+	if err != nil {
+		m.setStatus("Failed to save goal: "+err.Error(), "red")
+		return
+	}
+	m.setStatus(m.describeGoals(), "green")
+}
+
+// describeGoals summarises the active goals for the status line
+func (m model) describeGoals() string {
+	var parts []string
+	if m.goals.Daily > 0 {
+		parts = append(parts, "daily "+formatCount(m.goals.Daily)+" words")
+	}
+	if goal := m.fileGoal(); goal > 0 {
+		parts = append(parts, "this file "+formatCount(goal)+" words")
+	}
+	if len(parts) == 0 {
+		return "No goals set (use :goal <words> or :goal file <words>)"
+	}
+	return "Goals: " + strings.Join(parts, ", ")
+}
+
+// showGoalsReport opens the info pane with streaks and a day-by-day chart
+func (m *model) showGoalsReport() {
+	m.trackWords()
+	project := projectKey(m.projectDir())
+
+	var sessions []writingSession
+	if path, err := configFilePath(historyFile); err == nil {
+		sessions, err = loadHistory(path)
+		if err != nil {
+			m.setStatus("Failed to read writing history: "+err.Error(), "red")
+			return
+		}
+	}
+	if m.session != nil {
+		sessions = append(sessions, m.session.session)
+	}
+
+	now := time.Now()
+	totals := dailyTotals(sessions, project)
+	current, longest := computeStreaks(totals, m.goals.Daily, now)
+
+	lines := []string{
+		m.describeGoals(),
+		fmt.Sprintf("Current streak: %d days   Longest streak: %d days", current, longest),
+		sprintSummary(sessions, project),
+	}
+	if goal := m.fileGoal(); goal > 0 {
+		words := m.wordCounter.count(m.lines)
+		lines = append(lines, fmt.Sprintf("%s: %s %s/%s words", filepath.Base(m.filename), progressBar(words, goal, 20), formatCount(words), formatCount(goal)))
+	}
+	lines = append(lines, "")
+
+	// Scale bars to the goal, or to the best day when there is no goal
+	scale := m.goals.Daily
+	for i := 0; i < chartDays; i++ {
+		if t := totals[now.AddDate(0, 0, -i).Format(dayFormat)]; t > scale {
+			scale = t
+		}
+	}
+
+	const barWidth = 30
+	for i := chartDays - 1; i >= 0; i-- {
+		day := now.AddDate(0, 0, -i)
+		total := totals[day.Format(dayFormat)]
+		mark := " "
+		if m.goals.Daily > 0 && total >= m.goals.Daily {
+			mark = "✓"
+		}
+		bar := ""
+		if scale > 0 && total > 0 {
+			bar = strings.Repeat("█", (total*barWidth+scale-1)/scale)
+		}
+		lines = append(lines, fmt.Sprintf("%s %-*s %6s %s", day.Format("Mon 02 Jan"), barWidth, bar, formatCount(total), mark))
+	}
+
+	m.openInfoPane("Writing goals", lines)
+}
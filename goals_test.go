@@ -0,0 +1,147 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSessionTrackerObserve(t *testing.T) {
+	tracker := newSessionTracker("draft.md", "/proj", 100)
+	tracker.observe(120)
+	tracker.observe(115)
+	tracker.observe(130)
+
+	if tracker.session.Added != 35 || tracker.session.Removed != 5 {
+		t.Errorf("got +%d -%d, want +35 -5", tracker.session.Added, tracker.session.Removed)
+	}
+	tracker.priorToday = 200
+	if got := tracker.today(); got != 230 {
+		t.Errorf("today() = %d, want 230", got)
+	}
+}
+
+func TestWordsCountedOnlyAfterEdits(t *testing.T) {
+	m := newKeymapTestModel(t, defaultKeymapPreset)
+	m.mode = EditMode
+	m.wordCounter = newWordCounter()
+	m.session = newSessionTracker("draft.md", "/proj", m.wordCounter.count(m.lines))
+	press := func(msg tea.KeyMsg) {
+		result, _ := m.Update(msg)
+		m = result.(model)
+	}
+
+	// Moving the cursor doesn't recount, so a change made behind its back goes unseen
+	m.lines = append(m.lines, "two more")
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	if m.session.session.Added != 0 {
+		t.Errorf("cursor key recounted: +%d", m.session.session.Added)
+	}
+
+	// An edit recounts the whole document
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.session.session.Added != 2 {
+		t.Errorf("after an edit: +%d, want +2", m.session.session.Added)
+	}
+}
+
+func TestHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFile)
+
+	// A missing file is an empty history
+	sessions, err := loadHistory(path)
+	if err != nil || len(sessions) != 0 {
+		t.Fatalf("loadHistory on missing file = %v, %v", sessions, err)
+	}
+
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	for _, s := range []writingSession{
+		{Start: day, Project: "/a", Added: 300, Removed: 50},
+		{Start: day.Add(3 * time.Hour), Project: "/a", Added: 100},
+		{Start: day, Project: "/b", Added: 999},
+	} {
+		if err := appendHistory(path, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sessions, err = loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("loaded %d sessions, want 3", len(sessions))
+	}
+
+	totals := dailyTotals(sessions, "/a")
+	if got := totals["2024-03-01"]; got != 350 {
+		t.Errorf("daily total = %d, want 350", got)
+	}
+}
+
+func TestComputeStreaks(t *testing.T) {
+	today := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	totals := map[string]int{
+		"2024-03-01": 600,
+		"2024-03-02": 700,
+		"2024-03-03": 800,
+		"2024-03-04": 100, // below goal
+		"2024-03-08": 500,
+		"2024-03-09": 550,
+		"2024-03-10": 200, // today, not met yet
+	}
+
+	current, longest := computeStreaks(totals, 500, today)
+	if current != 2 || longest != 3 {
+		t.Errorf("with goal: current=%d longest=%d, want 2 and 3", current, longest)
+	}
+
+	// Without a goal any writing counts
+	current, longest = computeStreaks(totals, 0, today)
+	if current != 3 || longest != 4 {
+		t.Errorf("without goal: current=%d longest=%d, want 3 and 4", current, longest)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		done, target int
+		want         string
+	}{
+		{0, 100, "▱▱▱▱▱"},
+		{50, 100, "▰▰▱▱▱"},
+		{100, 100, "▰▰▰▰▰"},
+		{250, 100, "▰▰▰▰▰"},
+		{-10, 100, "▱▱▱▱▱"},
+		{10, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := progressBar(tt.done, tt.target, 5); got != tt.want {
+			t.Errorf("progressBar(%d, %d) = %q, want %q", tt.done, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestSaveProjectGoals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), goalsFile)
+
+	if err := saveProjectGoals(path, "/a", projectGoals{Daily: 500}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveProjectGoals(path, "/b", projectGoals{Files: map[string]int{"/b/novel.md": 80000}}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := loadAllGoals(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all["/a"].Daily != 500 {
+		t.Errorf("project /a daily = %d, want 500", all["/a"].Daily)
+	}
+	if all["/b"].Files["/b/novel.md"] != 80000 {
+		t.Errorf("project /b file goal = %v", all["/b"].Files)
+	}
+}
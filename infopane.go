@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openInfoPane shows read-only text (reports, charts, listings) above the status bar
+func (m *model) openInfoPane(title string, lines []string) {
+	m.infoTitle = title
+	m.infoLines = lines
+	m.infoOffset = 0
+	m.infoVisible = true
+	m.adjustViewport()
+}
+
+// closeInfoPane hides the info pane
+func (m *model) closeInfoPane() {
+	m.infoVisible = false
+	m.adjustViewport()
+}
+
+// infoPaneHeight returns the rows taken by the info pane (0 when hidden).
+// The pane grows with its content up to half the screen.
+func (m model) infoPaneHeight() int {
+	if !m.infoVisible {
+		return 0
+	}
+	height := len(m.infoLines) + 1 // +1 for the title row
+	if max := m.height / 2; height > max {
+		height = max
+	}
+	if height < 2 {
+		height = 2
+	}
+	return height
+}
+
+// handleInfoPaneKeys scrolls or closes the info pane
+func (m model) handleInfoPaneKeys(key string) (tea.Model, tea.Cmd) {
+	listHeight := m.infoPaneHeight() - 1
+
+	switch key {
+	case "up", "k":
+		if m.infoOffset > 0 {
+			m.infoOffset--
+		}
+	case "down", "j":
+		if m.infoOffset < len(m.infoLines)-listHeight {
+			m.infoOffset++
+		}
+	case "q", "enter":
+		m.closeInfoPane()
+	}
+
+	return m, nil
+}
+
+// renderInfoPane renders the info pane (title row plus text rows)
+func (m model) renderInfoPane() string {
+	titleStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.title.bg")).
+		Foreground(m.themeColor("pane.title.fg")).
+		Bold(monochrome()).
+		Width(m.width)
+
+	textStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.bg")).
+		Foreground(m.themeColor("pane.fg")).
+		Width(m.width)
+
+	var rows []string
+	rows = append(rows, titleStyle.Render(truncateRunes(" "+m.infoTitle+" | ↑↓: scroll | q: close", m.width)))
+
+	listHeight := m.infoPaneHeight() - 1
+	for i := 0; i < listHeight; i++ {
+		idx := m.infoOffset + i
+		text := ""
+		if idx < len(m.infoLines) {
+			text = truncateRunes(" "+m.infoLines[idx], m.width)
+		}
+		rows = append(rows, textStyle.Render(text))
+	}
+
+	return strings.Join(rows, "\n")
+}
@@ -18,6 +18,9 @@ func (m model) openFileInCurrentInstance(filename string) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Close the writing session for the previous file
+	m.endSession()
+
 	// Check if file exists, create if it doesn't
 	if _, err := os.Stat(absPath); os.IsNotExist(err) {
 		m.setStatus("Creating new file: "+filepath.Base(absPath), "yellow")
@@ -30,6 +33,7 @@ func (m model) openFileInCurrentInstance(filename string) (tea.Model, tea.Cmd) {
 		lines, err := loadFile(absPath)
 		if err != nil {
 			m.setStatus("Error loading file: "+err.Error(), "red")
+			m.startSession()
 			return m, nil
 		}
 		m.lines = lines
@@ -38,7 +42,8 @@ func (m model) openFileInCurrentInstance(filename string) (tea.Model, tea.Cmd) {
 		m.saved = true
 	}
 
-	// Reset cursor and viewport
+	// Reset cursor, viewport and edit history
+	m.clearUndo()
 	m.cursorX = 0
 	m.cursorY = 0
 	m.offsetX = 0
@@ -50,11 +55,22 @@ func (m model) openFileInCurrentInstance(filename string) (tea.Model, tea.Cmd) {
 	// Invalidate wrap cache
 	m.wrapCache = make(map[int][]wrappedLine)
 
+	// Pick spell-check languages for the new document
+	missing := m.applyDocumentLanguages()
+
+	// Start a writing session for the new file
+	m.startSession()
+	rememberRecentFile(absPath)
+
 	// Hide file tree and return focus to editor
 	m.fileTreeFocused = false
 	m.fileTreeVisible = false
 
-	m.setStatus("Opened "+filepath.Base(absPath), "green")
+	if len(missing) > 0 {
+		m.setStatus(missingDictionaryMessage(missing), "yellow")
+	} else {
+		m.setStatus("Opened "+filepath.Base(absPath), "green")
+	}
 	return m, nil
 }
 
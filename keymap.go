@@ -0,0 +1,858 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Keymap modes. Global bindings apply in every mode except command mode.
+const (
+	keymapGlobal  = "global"
+	keymapRead    = "read"
+	keymapEdit    = "edit"
+	keymapCommand = "command"
+	keymapTree    = "tree"
+)
+
+// keymapModes lists the modes in the order :keys shows them
+var keymapModes = []string{keymapGlobal, keymapRead, keymapEdit, keymapCommand, keymapTree}
+
+// defaultKeymapPreset is used when the config doesn't choose one
+const defaultKeymapPreset = "default"
+
+// keySeparator joins the keys of a sequence internally (key names never contain it)
+const keySeparator = "\x1f"
+
+// keyAction is a named command that keys can be bound to
+type keyAction struct {
+	name        string
+	description string
+	run         func(m model) (tea.Model, tea.Cmd)
+}
+
+// keyActions is the registry of bindable actions, keyed by name
+var keyActions = map[string]keyAction{}
+
+// registerKeyAction adds an action to the registry
+func registerKeyAction(name string, description string, run func(m model) (tea.Model, tea.Cmd)) {
+	keyActions[name] = keyAction{name: name, description: description, run: run}
+}
+
+// modelAction adapts an in-place model update to an action
+func modelAction(update func(m *model)) func(m model) (tea.Model, tea.Cmd) {
+	return func(m model) (tea.Model, tea.Cmd) {
+		update(&m)
+		return m, nil
+	}
+}
+
+// keyActionNames returns every registered action name, sorted
+func keyActionNames() []string {
+	names := make([]string, 0, len(keyActions))
+	for name := range keyActions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	// Application
+	registerKeyAction("app.quit", "Quit (refused during a blocking sprint)", func(m model) (tea.Model, tea.Cmd) {
+		if m.quitBlocked() {
+			return m, nil
+		}
+		return m, tea.Quit
+	})
+	registerKeyAction("file.save", "Save the document", modelAction(func(m *model) {
+		if err := m.saveFile(); err != nil {
+			m.setStatus("Error saving: "+err.Error(), "red")
+		} else {
+			m.setStatus("Saved "+m.filename, "green")
+		}
+	}))
+
+	registerKeyAction("sprint.stop", "End the writing sprint early", func(m model) (tea.Model, tea.Cmd) {
+		return m.handleSprintCommand([]string{"stop"})
+	})
+
+	// Layout
+	registerKeyAction("tree.toggle", "Show or hide the file tree", func(m model) (tea.Model, tea.Cmd) {
+		if m.zenMode {
+			return m, nil // hidden in zen mode
+		}
+		return m.toggleFileTree()
+	})
+	registerKeyAction("tree.focus", "Show the file tree and focus it", modelAction(func(m *model) { m.focusFileTree() }))
+	registerKeyAction("editor.focus", "Return focus to the editor", modelAction(func(m *model) { m.fileTreeFocused = false }))
+	registerKeyAction("stats.toggle", "Show or hide the statistics panel", modelAction(func(m *model) {
+		if !m.zenMode {
+			m.toggleStatsPanel()
+		}
+	}))
+	registerKeyAction("zen.toggle", "Enter or leave zen mode", func(m model) (tea.Model, tea.Cmd) { return m.toggleZenMode() })
+	registerKeyAction("thesaurus.lookup", "Look up the word under the cursor", modelAction(func(m *model) { m.openThesaurus() }))
+
+	// Modes
+	registerKeyAction("mode.toggle", "Switch between Read and Edit mode", modelAction(func(m *model) {
+		if m.fileTreeFocused || m.sprintLocked() {
+			return
+		}
+		if m.mode == ReadMode {
+			m.setMode(EditMode)
+		} else {
+			m.setMode(ReadMode)
+		}
+	}))
+	registerKeyAction("mode.edit", "Enter Edit mode", modelAction(func(m *model) {
+		if !m.fileTreeFocused {
+			m.setMode(EditMode)
+		}
+	}))
+	registerKeyAction("mode.read", "Enter Read mode", modelAction(func(m *model) {
+		if !m.sprintLocked() {
+			m.setMode(ReadMode)
+		}
+	}))
+	registerKeyAction("mode.escape", "Close the open pane, or return to Read mode", modelAction(func(m *model) {
+		switch {
+		case m.infoVisible:
+			m.closeInfoPane()
+		case m.thesaurusVisible:
+			m.thesaurusVisible = false
+			m.adjustViewport()
+		case m.quickfixVisible:
+			m.closeQuickfix()
+		case m.mode == EditMode && !m.sprintLocked():
+			m.setMode(ReadMode)
+		}
+	}))
+
+	// Command line
+	registerKeyAction("command.open", "Open the command line", modelAction(func(m *model) { m.openCommandLine() }))
+	registerKeyAction("finder.open", "Find and open a project file", func(m model) (tea.Model, tea.Cmd) {
+		cmd := m.openFinder()
+		return m, cmd
+	})
+	registerKeyAction("palette.open", "Search and run any command or action", modelAction(func(m *model) { m.openPalette() }))
+	registerKeyAction("command.execute", "Run the typed command", func(m model) (tea.Model, tea.Cmd) { return m.submitCommandLine() })
+	registerKeyAction("command.cancel", "Leave the command line", modelAction(func(m *model) { m.cancelCommandLine() }))
+	registerKeyAction("command.backspace", "Delete the command character before the cursor", modelAction(func(m *model) { m.commandBackspace() }))
+	registerKeyAction("command.delete", "Delete the command character under the cursor", modelAction(func(m *model) { m.commandDeleteForward() }))
+	registerKeyAction("command.delete_word", "Delete the command word before the cursor", modelAction(func(m *model) { m.commandDeleteWord() }))
+	registerKeyAction("command.delete_to_start", "Delete the command text before the cursor", modelAction(func(m *model) { m.commandDeleteToStart() }))
+	registerKeyAction("command.left", "Move the command cursor left", modelAction(func(m *model) { m.moveCommandCursor(-1) }))
+	registerKeyAction("command.right", "Move the command cursor right", modelAction(func(m *model) { m.moveCommandCursor(1) }))
+	registerKeyAction("command.word_left", "Move the command cursor to the previous word", modelAction(func(m *model) { m.moveCommandWord(-1) }))
+	registerKeyAction("command.word_right", "Move the command cursor to the next word", modelAction(func(m *model) { m.moveCommandWord(1) }))
+	registerKeyAction("command.start", "Move the command cursor to the start", modelAction(func(m *model) { m.moveCommandCursorTo(false) }))
+	registerKeyAction("command.end", "Move the command cursor to the end", modelAction(func(m *model) { m.moveCommandCursorTo(true) }))
+	registerKeyAction("command.history_prev", "Recall an older command (matching what is typed)", modelAction(func(m *model) { m.browseCommandHistory(-1) }))
+	registerKeyAction("command.history_next", "Recall a newer command (matching what is typed)", modelAction(func(m *model) { m.browseCommandHistory(1) }))
+	registerKeyAction("command.complete", "Complete the command, file, language, theme or option", modelAction(func(m *model) { m.completeCommand(1) }))
+	registerKeyAction("command.complete_prev", "Select the previous completion", modelAction(func(m *model) { m.completeCommand(-1) }))
+
+	// Cursor movement (clears the selection) and selection extension
+	movements := []struct {
+		name        string
+		description string
+		move        func(m *model)
+	}{
+		{"up", "up a line", (*model).moveCursorUp},
+		{"down", "down a line", (*model).moveCursorDown},
+		{"left", "left a character", (*model).moveCursorLeft},
+		{"right", "right a character", (*model).moveCursorRight},
+		{"word_next", "to the next word", (*model).moveWordForward},
+		{"word_prev", "to the previous word", (*model).moveWordBackward},
+		{"line_start", "to the start of the line", (*model).moveToLineStart},
+		{"line_end", "to the end of the line", (*model).moveToLineEnd},
+		{"doc_start", "to the start of the document", (*model).moveToDocumentStart},
+		{"doc_end", "to the end of the document", (*model).moveToDocumentEnd},
+		{"page_up", "up a screen", func(m *model) { m.movePage(-1) }},
+		{"page_down", "down a screen", func(m *model) { m.movePage(1) }},
+		{"paragraph_next", "to the end of the paragraph", (*model).moveParagraphForward},
+		{"paragraph_prev", "to the start of the paragraph", (*model).moveParagraphBackward},
+	}
+	for _, mv := range movements {
+		move := mv.move
+		registerKeyAction("cursor."+mv.name, "Move "+mv.description, modelAction(func(m *model) {
+			m.selectionActive = false
+			move(m)
+		}))
+		registerKeyAction("select."+mv.name, "Extend the selection "+mv.description, modelAction(func(m *model) {
+			m.startSelection()
+			move(m)
+		}))
+	}
+
+	// Editing
+	registerKeyAction("edit.newline", "Split the line at the cursor", modelAction((*model).insertNewline))
+	registerKeyAction("edit.backspace", "Delete the character before the cursor", modelAction((*model).deleteBackward))
+	registerKeyAction("edit.delete", "Delete the character at the cursor", modelAction((*model).deleteForward))
+	registerKeyAction("edit.tab", "Insert spaces to the tab width", modelAction((*model).insertTab))
+	registerKeyAction("edit.delete_word_back", "Delete the word before the cursor", modelAction((*model).deleteWordBackward))
+	registerKeyAction("edit.delete_to_line_end", "Delete to the end of the line", modelAction((*model).deleteToLineEnd))
+	registerKeyAction("edit.delete_line", "Delete the current line", modelAction((*model).deleteLine))
+	registerKeyAction("edit.undo", "Undo the last edit", modelAction((*model).undo))
+	registerKeyAction("edit.redo", "Redo the last edit undone", modelAction((*model).redo))
+	registerKeyAction("select.all", "Select the whole document", modelAction((*model).selectAll))
+	registerKeyAction("select.line", "Select the line, or extend the selection by a line", modelAction((*model).selectLine))
+	registerKeyAction("clipboard.cut", "Cut the selection", modelAction((*model).cutSelection))
+	registerKeyAction("clipboard.copy", "Copy the selection", modelAction((*model).copySelection))
+	registerKeyAction("clipboard.paste", "Paste from the clipboard", modelAction((*model).pasteClipboard))
+	registerKeyAction("clipboard.paste_older", "Replace the text just pasted with an older kill ring entry", modelAction((*model).pasteOlder))
+	registerKeyAction("register.select", "Choose a register for the next copy, cut or paste", modelAction((*model).selectRegister))
+
+	// Command-line files
+	registerKeyAction("file.next", "Open the next file from the command line", func(m model) (tea.Model, tea.Cmd) { return m.openArgFile(m.argIndex + 1) })
+	registerKeyAction("file.prev", "Open the previous file from the command line", func(m model) (tea.Model, tea.Cmd) { return m.openArgFile(m.argIndex - 1) })
+
+	// File tree
+	registerKeyAction("tree.up", "Select the previous tree entry", modelAction(func(m *model) { m.moveTreeCursor(-1) }))
+	registerKeyAction("tree.down", "Select the next tree entry", modelAction(func(m *model) { m.moveTreeCursor(1) }))
+	registerKeyAction("tree.open", "Open the file or expand the folder", func(m model) (tea.Model, tea.Cmd) { return m.openTreeSelection() })
+	registerKeyAction("tree.filter", "Filter the tree by name", modelAction(func(m *model) { m.openTreeFilter() }))
+	registerKeyAction("tree.toggle_hidden", "Show or hide hidden files in the tree", modelAction(func(m *model) { m.toggleHiddenFiles() }))
+	registerKeyAction("tree.sort", "Sort the tree by name, modification time or manual order", modelAction(func(m *model) { m.cycleTreeSort() }))
+	registerKeyAction("tree.move_up", "Move the selection up (manual order)", modelAction(func(m *model) { m.moveTreeEntry(-1) }))
+	registerKeyAction("tree.move_down", "Move the selection down (manual order)", modelAction(func(m *model) { m.moveTreeEntry(1) }))
+	registerKeyAction("tree.wider", "Widen the file tree", modelAction(func(m *model) { m.resizeFileTree(2) }))
+	registerKeyAction("tree.narrower", "Narrow the file tree", modelAction(func(m *model) { m.resizeFileTree(-2) }))
+	registerKeyAction("tree.new_file", "Create a file next to the selection (:mkfile)", modelAction(func(m *model) { m.promptFileCommand("mkfile") }))
+	registerKeyAction("tree.new_folder", "Create a folder next to the selection (:mkdir)", modelAction(func(m *model) { m.promptFileCommand("mkdir") }))
+	registerKeyAction("tree.rename", "Rename the selection (:rename)", modelAction(func(m *model) { m.promptFileCommand("rename") }))
+	registerKeyAction("tree.move", "Move the selection to another folder (:move)", modelAction(func(m *model) { m.promptFileCommand("move") }))
+	registerKeyAction("tree.duplicate", "Copy the selection (:duplicate)", modelAction(func(m *model) { m.promptFileCommand("duplicate") }))
+	registerKeyAction("tree.delete", "Move the selection to the trash (:delete)", modelAction(func(m *model) { m.promptFileCommand("delete") }))
+}
+
+// defaultBindings are the built-in keys, per mode
+var defaultBindings = map[string]map[string]string{
+	keymapGlobal: {
+		"ctrl+q": "app.quit",
+		"ctrl+s": "file.save",
+		"f1":     "tree.toggle",
+		"f2":     "stats.toggle",
+		"f11":    "zen.toggle",
+		"f12":    "sprint.stop",
+		"insert": "mode.toggle",
+		"esc":    "mode.escape",
+		"ctrl+o": "finder.open",
+		"ctrl+p": "palette.open",
+	},
+	keymapRead: {
+		"up": "cursor.up", "k": "cursor.up",
+		"down": "cursor.down", "j": "cursor.down",
+		"left": "cursor.left", "h": "cursor.left",
+		"right": "cursor.right", "l": "cursor.right",
+		"pgup":      "cursor.page_up",
+		"pgdown":    "cursor.page_down",
+		"ctrl+home": "cursor.doc_start", "g": "cursor.doc_start",
+		"ctrl+end": "cursor.doc_end", "G": "cursor.doc_end",
+		"home": "cursor.line_start",
+		"end":  "cursor.line_end",
+		"t":    "thesaurus.lookup",
+		":":    "command.open",
+	},
+	keymapEdit: {
+		"up":               "cursor.up",
+		"down":             "cursor.down",
+		"left":             "cursor.left",
+		"right":            "cursor.right",
+		"home":             "cursor.line_start",
+		"end":              "cursor.line_end",
+		"shift+up":         "select.up",
+		"shift+down":       "select.down",
+		"shift+left":       "select.left",
+		"shift+right":      "select.right",
+		"shift+home":       "select.line_start",
+		"shift+end":        "select.line_end",
+		"ctrl+up":          "cursor.paragraph_prev",
+		"ctrl+down":        "cursor.paragraph_next",
+		"ctrl+shift+left":  "select.word_prev",
+		"ctrl+shift+right": "select.word_next",
+		"ctrl+shift+up":    "select.paragraph_prev",
+		"ctrl+shift+down":  "select.paragraph_next",
+		"ctrl+shift+home":  "select.doc_start",
+		"ctrl+shift+end":   "select.doc_end",
+		"ctrl+a":           "select.all",
+		"ctrl+l":           "select.line",
+		"tab":              "edit.tab",
+		"enter":            "edit.newline",
+		"backspace":        "edit.backspace",
+		"delete":           "edit.delete",
+		"ctrl+x":           "clipboard.cut",
+		"ctrl+c":           "clipboard.copy",
+		"ctrl+v":           "clipboard.paste",
+		"ctrl+r":           "register.select",
+		"ctrl+z":           "edit.undo",
+		"ctrl+y":           "edit.redo",
+	},
+	keymapCommand: {
+		"esc":        "command.cancel",
+		"enter":      "command.execute",
+		"backspace":  "command.backspace",
+		"delete":     "command.delete",
+		"ctrl+w":     "command.delete_word",
+		"ctrl+u":     "command.delete_to_start",
+		"left":       "command.left",
+		"right":      "command.right",
+		"ctrl+left":  "command.word_left",
+		"ctrl+right": "command.word_right",
+		"alt+b":      "command.word_left",
+		"alt+f":      "command.word_right",
+		"home":       "command.start",
+		"ctrl+a":     "command.start",
+		"end":        "command.end",
+		"ctrl+e":     "command.end",
+		"up":         "command.history_prev",
+		"down":       "command.history_next",
+		"tab":        "command.complete",
+		"shift+tab":  "command.complete_prev",
+	},
+	keymapTree: {
+		"up": "tree.up", "k": "tree.up",
+		"down": "tree.down", "j": "tree.down",
+		"enter":      "tree.open",
+		"a":          "tree.new_file",
+		"A":          "tree.new_folder",
+		"r":          "tree.rename",
+		"m":          "tree.move",
+		"c":          "tree.duplicate",
+		"d":          "tree.delete",
+		"delete":     "tree.delete",
+		":":          "command.open",
+		"/":          "tree.filter",
+		".":          "tree.toggle_hidden",
+		"s":          "tree.sort",
+		"K":          "tree.move_up",
+		"shift+up":   "tree.move_up",
+		"J":          "tree.move_down",
+		"shift+down": "tree.move_down",
+		">":          "tree.wider",
+		"<":          "tree.narrower",
+	},
+}
+
+// keymapPresets are layered over the default bindings. An empty action unbinds a key.
+var keymapPresets = map[string]map[string]map[string]string{
+	defaultKeymapPreset: {},
+	"vim": {
+		keymapGlobal: {
+			"ctrl+w h": "tree.focus",
+			"ctrl+w l": "editor.focus",
+			"ctrl+w t": "tree.toggle",
+		},
+		keymapRead: {
+			"g":      "",
+			"g g":    "cursor.doc_start",
+			"w":      "cursor.word_next",
+			"b":      "cursor.word_prev",
+			"0":      "cursor.line_start",
+			"$":      "cursor.line_end",
+			"i":      "mode.edit",
+			"x":      "edit.delete",
+			"d d":    "edit.delete_line",
+			"D":      "edit.delete_to_line_end",
+			"p":      "clipboard.paste",
+			"y":      "clipboard.copy",
+			"\"":     "register.select",
+			"u":      "edit.undo",
+			"ctrl+r": "edit.redo",
+			"z z":    "zen.toggle",
+		},
+		keymapTree: {
+			"l": "tree.open",
+			"q": "tree.toggle",
+		},
+	},
+	"emacs": {
+		keymapGlobal: {
+			"ctrl+x ctrl+s": "file.save",
+			"ctrl+x ctrl+c": "app.quit",
+			"ctrl+x ctrl+f": "tree.focus",
+			"ctrl+x o":      "editor.focus",
+			"alt+x":         "command.open",
+			"ctrl+g":        "mode.escape",
+		},
+		keymapEdit: {
+			"ctrl+f":        "cursor.right",
+			"ctrl+b":        "cursor.left",
+			"ctrl+n":        "cursor.down",
+			"ctrl+p":        "cursor.up",
+			"ctrl+a":        "cursor.line_start",
+			"ctrl+e":        "cursor.line_end",
+			"alt+f":         "cursor.word_next",
+			"alt+b":         "cursor.word_prev",
+			"alt+<":         "cursor.doc_start",
+			"alt+>":         "cursor.doc_end",
+			"ctrl+v":        "cursor.page_down",
+			"alt+v":         "cursor.page_up",
+			"ctrl+d":        "edit.delete",
+			"ctrl+k":        "edit.delete_to_line_end",
+			"alt+backspace": "edit.delete_word_back",
+			"alt+{":         "cursor.paragraph_prev",
+			"alt+}":         "cursor.paragraph_next",
+			"ctrl+x":        "",
+			"ctrl+x h":      "select.all",
+			"ctrl+w":        "clipboard.cut",
+			"alt+w":         "clipboard.copy",
+			"ctrl+y":        "clipboard.paste",
+			"alt+y":         "clipboard.paste_older",
+			"ctrl+_":        "edit.undo",
+			"ctrl+x u":      "edit.undo",
+		},
+		keymapCommand: {
+			"ctrl+g": "command.cancel",
+			"ctrl+b": "command.left",
+			"ctrl+f": "command.right",
+			"ctrl+p": "command.history_prev",
+			"ctrl+n": "command.history_next",
+			"ctrl+d": "command.delete",
+		},
+		keymapTree: {
+			"ctrl+p": "tree.up",
+			"ctrl+n": "tree.down",
+		},
+	},
+	"wordprocessor": {
+		keymapGlobal: {
+			"ctrl+w": "app.quit",
+		},
+		keymapEdit: {
+			"ctrl+left":      "cursor.word_prev",
+			"ctrl+right":     "cursor.word_next",
+			"ctrl+home":      "cursor.doc_start",
+			"ctrl+end":       "cursor.doc_end",
+			"pgup":           "cursor.page_up",
+			"pgdown":         "cursor.page_down",
+			"ctrl+backspace": "edit.delete_word_back",
+			"ctrl+t":         "thesaurus.lookup",
+			"ctrl+e":         "command.open",
+		},
+	},
+}
+
+// presetStartsInEdit lists presets for people who expect to type straight away
+var presetStartsInEdit = map[string]bool{
+	"emacs":         true,
+	"wordprocessor": true,
+}
+
+// namedKeys are multi-character key names that must not be split into letters
+var namedKeys = map[string]bool{
+	"up": true, "down": true, "left": true, "right": true,
+	"home": true, "end": true, "pgup": true, "pgdown": true,
+	"enter": true, "esc": true, "tab": true, "backspace": true,
+	"delete": true, "insert": true, "space": true,
+}
+
+// parseKeySequence turns "g g", "gg", "ctrl+w v" or "ctrl+x ctrl+s" into key names
+func parseKeySequence(s string) ([]string, error) {
+	var keys []string
+	for _, token := range strings.Fields(s) {
+		switch {
+		case token == "space":
+			keys = append(keys, " ")
+		case strings.Contains(token, "+") && len(token) > 1, namedKeys[token], isFunctionKey(token):
+			keys = append(keys, token)
+		default:
+			// "gg" is two presses of g
+			for _, r := range token {
+				keys = append(keys, string(r))
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("empty key sequence")
+	}
+	return keys, nil
+}
+
+// isFunctionKey reports whether token names a function key such as "f5"
+func isFunctionKey(token string) bool {
+	if len(token) < 2 || token[0] != 'f' {
+		return false
+	}
+	for _, r := range token[1:] {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatKeySequence renders key names for display ("g g", "ctrl+x ctrl+s")
+func formatKeySequence(keys []string) string {
+	display := make([]string, len(keys))
+	for i, k := range keys {
+		if k == " " {
+			k = "space"
+		}
+		display[i] = k
+	}
+	return strings.Join(display, " ")
+}
+
+// Keymap maps key sequences to actions for each mode
+type Keymap struct {
+	preset   string
+	bindings map[string]map[string]string // mode -> joined key sequence -> action
+	prefixes map[string]map[string]bool   // mode -> joined proper prefixes of bindings
+}
+
+// newKeymap builds a keymap from a preset and per-mode overrides
+func newKeymap(preset string, overrides map[string]map[string]string) (*Keymap, error) {
+	if preset == "" {
+		preset = defaultKeymapPreset
+	}
+	overlay, ok := keymapPresets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown keymap %q (choose from %s)", preset, strings.Join(keymapPresetNames(), ", "))
+	}
+
+	k := &Keymap{preset: preset, bindings: make(map[string]map[string]string)}
+	for _, layer := range []map[string]map[string]string{defaultBindings, overlay, overrides} {
+		for mode, keys := range layer {
+			for seq, action := range keys {
+				if err := k.bind(mode, seq, action); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	k.index()
+	return k, nil
+}
+
+// keymapPresetNames returns the preset names, sorted
+func keymapPresetNames() []string {
+	names := make([]string, 0, len(keymapPresets))
+	for name := range keymapPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// bind sets (or, with an empty action, removes) a binding. Call index afterwards.
+func (k *Keymap) bind(mode string, sequence string, action string) error {
+	if !containsString(keymapModes, mode) {
+		return fmt.Errorf("unknown keymap mode %q (choose from %s)", mode, strings.Join(keymapModes, ", "))
+	}
+	if _, ok := keyActions[action]; !ok && action != "" {
+		return fmt.Errorf("unknown action %q", action)
+	}
+	keys, err := parseKeySequence(sequence)
+	if err != nil {
+		return err
+	}
+
+	if k.bindings[mode] == nil {
+		k.bindings[mode] = make(map[string]string)
+	}
+	joined := strings.Join(keys, keySeparator)
+	if action == "" {
+		delete(k.bindings[mode], joined)
+	} else {
+		k.bindings[mode][joined] = action
+	}
+	return nil
+}
+
+// index records every proper prefix of multi-key bindings
+func (k *Keymap) index() {
+	k.prefixes = make(map[string]map[string]bool)
+	for mode, keys := range k.bindings {
+		k.prefixes[mode] = make(map[string]bool)
+		for joined := range keys {
+			parts := strings.Split(joined, keySeparator)
+			for i := 1; i < len(parts); i++ {
+				k.prefixes[mode][strings.Join(parts[:i], keySeparator)] = true
+			}
+		}
+	}
+}
+
+// exact returns the action bound to a single key in one mode
+func (k *Keymap) exact(mode string, key string) (string, bool) {
+	action, ok := k.bindings[mode][key]
+	return action, ok
+}
+
+// resolve looks up a key sequence in the given modes (earlier modes win).
+// prefix is true when a longer binding starts with the sequence.
+func (k *Keymap) resolve(modes []string, keys []string) (action string, exact bool, prefix bool) {
+	joined := strings.Join(keys, keySeparator)
+	for _, mode := range modes {
+		if !exact {
+			action, exact = k.bindings[mode][joined]
+		}
+		if k.prefixes[mode][joined] {
+			prefix = true
+		}
+	}
+	return action, exact, prefix
+}
+
+// describe lists a mode's bindings as "keys  action  description" lines
+func (k *Keymap) describe(mode string) []string {
+	type row struct{ keys, action string }
+	var rows []row
+	width := 0
+	for joined, action := range k.bindings[mode] {
+		keys := formatKeySequence(strings.Split(joined, keySeparator))
+		rows = append(rows, row{keys, action})
+		if len(keys) > width {
+			width = len(keys)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].action != rows[j].action {
+			return rows[i].action < rows[j].action
+		}
+		return rows[i].keys < rows[j].keys
+	})
+
+	lines := make([]string, 0, len(rows))
+	for _, r := range rows {
+		lines = append(lines, fmt.Sprintf("%-*s  %-24s %s", width, r.keys, r.action, keyActions[r.action].description))
+	}
+	return lines
+}
+
+// fallbackKeymap is used by models built without a config (e.g. in tests)
+var fallbackKeymap *Keymap
+
+// activeKeymap returns the configured keymap, or the default one
+func (m model) activeKeymap() *Keymap {
+	if m.keymap != nil {
+		return m.keymap
+	}
+	if fallbackKeymap == nil {
+		fallbackKeymap, _ = newKeymap(defaultKeymapPreset, nil)
+	}
+	return fallbackKeymap
+}
+
+// keymapMode returns the keymap mode for the current editor state
+func (m model) keymapMode() string {
+	switch {
+	case m.commandMode:
+		return keymapCommand
+	case m.fileTreeFocused:
+		return keymapTree
+	case m.mode == EditMode:
+		return keymapEdit
+	default:
+		return keymapRead
+	}
+}
+
+// setMode switches between Read and Edit mode and says so in the status bar
+func (m *model) setMode(mode Mode) {
+	if m.mode == mode {
+		return
+	}
+	if mode == EditMode && m.readOnly {
+		m.setStatus("Can't edit: "+errReadOnly.Error(), "yellow")
+		return
+	}
+	m.mode = mode
+	if mode == EditMode {
+		m.setStatus("-- EDIT MODE --", "green")
+	} else {
+		m.setStatus("-- READ MODE --", "green")
+	}
+}
+
+// runAction runs a registered action by name
+func (m model) runAction(name string) (tea.Model, tea.Cmd) {
+	action, ok := keyActions[name]
+	if !ok {
+		m.setStatus("Unknown action: "+name, "red")
+		return m, nil
+	}
+	if m.readOnly && modifiesDocument(name) {
+		m.setStatus("Can't edit: "+errReadOnly.Error(), "yellow")
+		return m, nil
+	}
+	m.lastEdit = "" // any action ends a run of typing
+	if !modifiesDocument(name) {
+		return action.run(m)
+	}
+	m.wordsStale = true
+	if name == "edit.undo" || name == "edit.redo" {
+		return action.run(m)
+	}
+	m.saveUndo(undoEdit)
+	result, cmd := action.run(m)
+	if next, ok := result.(model); ok {
+		next.dropUnchangedUndo()
+		return next, cmd
+	}
+	return result, cmd
+}
+
+// modifiesDocument reports whether an action changes the text (refused when read-only)
+func modifiesDocument(name string) bool {
+	return strings.HasPrefix(name, "edit.") || name == "clipboard.paste" || name == "clipboard.paste_older" || name == "clipboard.cut"
+}
+
+// dispatchKey resolves a key press, collecting multi-key sequences, and runs its action
+func (m model) dispatchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	mode := m.keymapMode()
+	modes := []string{mode, keymapGlobal}
+	if mode == keymapCommand {
+		modes = []string{keymapCommand} // typing a command ignores global keys
+	}
+	km := m.activeKeymap()
+
+	// Esc abandons a half-typed sequence
+	if key == "esc" && len(m.pendingKeys) > 0 {
+		m.pendingKeys = nil
+		return m, nil
+	}
+
+	keys := append(append([]string(nil), m.pendingKeys...), key)
+	action, exact, prefix := km.resolve(modes, keys)
+	if prefix {
+		m.pendingKeys = keys // wait for the rest of the sequence
+		return m, nil
+	}
+	m.pendingKeys = nil
+	if exact {
+		return m.runAction(action)
+	}
+
+	if len(keys) > 1 {
+		// The sequence broke off: finish the pending keys on their own,
+		// then handle this key afresh
+		pending := keys[:len(keys)-1]
+		var cmd tea.Cmd
+		if action, ok, _ := km.resolve(modes, pending); ok {
+			var result tea.Model
+			result, cmd = m.runAction(action)
+			next, ok := result.(model)
+			if !ok {
+				return result, cmd
+			}
+			m = next
+		} else {
+			for _, k := range pending {
+				if utf8.RuneCountInString(k) == 1 {
+					m.typeRunes(mode, []rune(k))
+				}
+			}
+		}
+		result, next := m.dispatchKey(msg)
+		return result, tea.Batch(cmd, next)
+	}
+
+	if !msg.Alt {
+		m.typeRunes(mode, msg.Runes)
+	}
+	return m, nil
+}
+
+// typeRunes handles an unbound printable key: text in Edit mode, the command line in command mode
+func (m *model) typeRunes(mode string, runes []rune) {
+	if len(runes) != 1 {
+		return
+	}
+	r := runes[0]
+	switch mode {
+	case keymapEdit:
+		if r >= 32 && r != 127 { // Printable characters
+			m.saveUndo(undoTyping)
+			m.insertRune(r)
+			m.wordsStale = true
+		}
+	case keymapCommand:
+		m.insertCommandText(string(r))
+	}
+}
+
+// pendingKeysStatus shows a half-typed key sequence, e.g. "ctrl+x …"
+func (m model) pendingKeysStatus() string {
+	if len(m.pendingKeys) == 0 {
+		return ""
+	}
+	return formatKeySequence(m.pendingKeys) + " …"
+}
+
+// handleMapCommand handles ":map <mode> <keys...> <action>"
+func (m model) handleMapCommand(args []string) (tea.Model, tea.Cmd) {
+	if len(args) < 3 {
+		m.setStatus("Usage: :map <mode> <keys> <action>", "yellow")
+		return m, nil
+	}
+	return m.setKeyBinding(args[0], strings.Join(args[1:len(args)-1], " "), args[len(args)-1])
+}
+
+// handleUnmapCommand handles ":unmap <mode> <keys...>"
+func (m model) handleUnmapCommand(args []string) (tea.Model, tea.Cmd) {
+	if len(args) < 2 {
+		m.setStatus("Usage: :unmap <mode> <keys>", "yellow")
+		return m, nil
+	}
+	return m.setKeyBinding(args[0], strings.Join(args[1:], " "), "")
+}
+
+// setKeyBinding changes one binding until the config is next loaded
+func (m model) setKeyBinding(mode string, sequence string, action string) (tea.Model, tea.Cmd) {
+	cfg := m.config
+	cfg.Keys = make(map[string]map[string]string)
+	for md, keys := range m.config.Keys {
+		cfg.Keys[md] = make(map[string]string)
+		for seq, act := range keys {
+			cfg.Keys[md][seq] = act
+		}
+	}
+	if cfg.Keys[mode] == nil {
+		cfg.Keys[mode] = make(map[string]string)
+	}
+	cfg.Keys[mode][sequence] = action
+
+	if err := cfg.validate(); err != nil {
+		m.setStatus(err.Error(), "red")
+		return m, nil
+	}
+	cmd := m.applyConfig(cfg)
+
+	if action == "" {
+		m.setStatus("Unmapped "+sequence+" in "+mode+" mode", "green")
+	} else {
+		m.setStatus("Mapped "+sequence+" to "+action+" in "+mode+" mode", "green")
+	}
+	return m, cmd
+}
+
+// showKeymap lists the bindings of one mode (or all modes) in the info pane
+func (m *model) showKeymap(mode string) {
+	modes := keymapModes
+	if mode != "" {
+		if !containsString(keymapModes, mode) {
+			m.setStatus("Unknown mode: "+mode+" ("+strings.Join(keymapModes, ", ")+")", "red")
+			return
+		}
+		modes = []string{mode}
+	}
+
+	km := m.activeKeymap()
+	var lines []string
+	for _, md := range modes {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+md+"]")
+		lines = append(lines, km.describe(md)...)
+	}
+	m.openInfoPane("Keymap: "+km.preset, lines)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyMsg builds the key message bubbletea sends for a key name
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "ctrl+w":
+		return tea.KeyMsg{Type: tea.KeyCtrlW}
+	case "ctrl+x":
+		return tea.KeyMsg{Type: tea.KeyCtrlX}
+	case "ctrl+s":
+		return tea.KeyMsg{Type: tea.KeyCtrlS}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// pressKeys feeds keys to the model one at a time
+func pressKeys(t *testing.T, m model, keys ...string) model {
+	t.Helper()
+	for _, k := range keys {
+		result, _ := m.handleKeyPress(keyMsg(k))
+		m = result.(model)
+	}
+	return m
+}
+
+func newKeymapTestModel(t *testing.T, preset string) model {
+	t.Helper()
+	km, err := newKeymap(preset, nil)
+	if err != nil {
+		t.Fatalf("newKeymap(%q): %v", preset, err)
+	}
+	return model{
+		lines:     []string{"first line", "second line", "third line"},
+		wrapCache: make(map[int][]wrappedLine),
+		config:    defaultConfig(),
+		keymap:    km,
+		width:     80,
+		height:    20,
+	}
+}
+
+func TestParseKeySequence(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"gg", []string{"g", "g"}},
+		{"g g", []string{"g", "g"}},
+		{"ctrl+w v", []string{"ctrl+w", "v"}},
+		{"ctrl+x ctrl+s", []string{"ctrl+x", "ctrl+s"}},
+		{"pgdown", []string{"pgdown"}},
+		{"f11", []string{"f11"}},
+		{"space", []string{" "}},
+		{"+", []string{"+"}},
+	}
+	for _, tt := range tests {
+		got, err := parseKeySequence(tt.in)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseKeySequence(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := parseKeySequence("  "); err == nil {
+		t.Error("an empty sequence should be rejected")
+	}
+}
+
+func TestPresetsReferenceRegisteredActions(t *testing.T) {
+	for _, name := range keymapPresetNames() {
+		if _, err := newKeymap(name, nil); err != nil {
+			t.Errorf("preset %s: %v", name, err)
+		}
+	}
+	if _, err := newKeymap("nano", nil); err == nil {
+		t.Error("an unknown preset should be rejected")
+	}
+}
+
+func TestKeymapOverrides(t *testing.T) {
+	km, err := newKeymap("default", map[string]map[string]string{
+		keymapRead: {"q": "app.quit", "j": ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action, ok := km.exact(keymapRead, "q"); !ok || action != "app.quit" {
+		t.Errorf("q = %q, %v; want app.quit", action, ok)
+	}
+	if _, ok := km.exact(keymapRead, "j"); ok {
+		t.Error("an empty action should unbind j")
+	}
+	if _, ok := km.exact(keymapRead, "down"); !ok {
+		t.Error("other bindings should be kept")
+	}
+
+	bad := []map[string]map[string]string{
+		{"visual": {"v": "cursor.down"}},
+		{keymapRead: {"v": "cursor.sideways"}},
+	}
+	for _, overrides := range bad {
+		if _, err := newKeymap("default", overrides); err == nil {
+			t.Errorf("%v should be rejected", overrides)
+		}
+	}
+}
+
+func TestDefaultKeymapReadMode(t *testing.T) {
+	m := newKeymapTestModel(t, "default")
+	m = pressKeys(t, m, "j", "j", "l")
+	if m.cursorY != 2 || m.cursorX != 1 {
+		t.Errorf("cursor at %d:%d, want 2:1", m.cursorY, m.cursorX)
+	}
+	m = pressKeys(t, m, "g")
+	if m.cursorY != 0 || m.cursorX != 0 {
+		t.Errorf("g should go to the top, cursor at %d:%d", m.cursorY, m.cursorX)
+	}
+	m = pressKeys(t, m, ":", "s", "e", "t")
+	if !m.commandMode || m.commandBuffer != ":set" {
+		t.Errorf("command line = %q (mode %v), want :set", m.commandBuffer, m.commandMode)
+	}
+}
+
+func TestVimSequences(t *testing.T) {
+	m := newKeymapTestModel(t, "vim")
+	m = pressKeys(t, m, "G")
+	if m.cursorY != 2 {
+		t.Fatalf("G should go to the last line, at %d", m.cursorY)
+	}
+
+	m = pressKeys(t, m, "g")
+	if m.cursorY != 2 || !reflect.DeepEqual(m.pendingKeys, []string{"g"}) {
+		t.Fatalf("g should wait for the rest of the sequence, pending %q", m.pendingKeys)
+	}
+	if !strings.Contains(m.pendingKeysStatus(), "g") {
+		t.Errorf("pending status = %q", m.pendingKeysStatus())
+	}
+	m = pressKeys(t, m, "g")
+	if m.cursorY != 0 || len(m.pendingKeys) != 0 {
+		t.Errorf("g g should go to the top, at %d, pending %q", m.cursorY, m.pendingKeys)
+	}
+
+	m = pressKeys(t, m, "d", "d")
+	if len(m.lines) != 2 || m.lines[0] != "second line" {
+		t.Errorf("d d should delete the line, got %q", m.lines)
+	}
+
+	// A broken sequence drops the unbound prefix and handles the new key
+	m = pressKeys(t, m, "g", "j")
+	if m.cursorY != 1 || len(m.pendingKeys) != 0 {
+		t.Errorf("g j should move down, at %d, pending %q", m.cursorY, m.pendingKeys)
+	}
+
+	// Esc abandons a sequence
+	m = pressKeys(t, m, "d", "esc", "d")
+	if len(m.lines) != 2 || !reflect.DeepEqual(m.pendingKeys, []string{"d"}) {
+		t.Errorf("esc should cancel the pending d, lines %q, pending %q", m.lines, m.pendingKeys)
+	}
+
+	m.pendingKeys = nil
+	m = pressKeys(t, m, "ctrl+w", "h")
+	if !m.fileTreeFocused || m.keymapMode() != keymapTree {
+		t.Error("ctrl+w h should focus the file tree")
+	}
+	m = pressKeys(t, m, "ctrl+w", "l")
+	if m.fileTreeFocused {
+		t.Error("ctrl+w l should return to the editor")
+	}
+
+	m = pressKeys(t, m, "i", "x", "y")
+	if m.mode != EditMode || !strings.HasPrefix(m.lines[m.cursorY], "xy") {
+		t.Errorf("i should enter Edit mode and type, mode %v, line %q", m.mode, m.lines[m.cursorY])
+	}
+}
+
+func TestEmacsPrefixInEditMode(t *testing.T) {
+	m := newKeymapTestModel(t, "emacs")
+	m.mode = EditMode
+
+	m = pressKeys(t, m, "ctrl+x")
+	if !reflect.DeepEqual(m.pendingKeys, []string{"ctrl+x"}) {
+		t.Fatalf("ctrl+x should wait, pending %q", m.pendingKeys)
+	}
+	// An unbound follow-up key is typed as text
+	m = pressKeys(t, m, "a")
+	if m.lines[0] != "afirst line" || len(m.pendingKeys) != 0 {
+		t.Errorf("line = %q, pending %q", m.lines[0], m.pendingKeys)
+	}
+}
+
+func TestCommandModeIgnoresGlobalKeys(t *testing.T) {
+	m := newKeymapTestModel(t, "vim")
+	m = pressKeys(t, m, ":", "g", "g")
+	if m.commandBuffer != ":gg" || len(m.pendingKeys) != 0 {
+		t.Errorf("command line = %q, pending %q", m.commandBuffer, m.pendingKeys)
+	}
+	m = pressKeys(t, m, "esc")
+	if m.commandMode {
+		t.Error("esc should leave the command line")
+	}
+}
+
+func TestMapCommand(t *testing.T) {
+	m := newKeymapTestModel(t, "default")
+
+	result, _ := m.executeCommand(":map read z z cursor.doc_end")
+	m = result.(model)
+	if action, ok := m.activeKeymap().exact(keymapRead, "z"+keySeparator+"z"); !ok || action != "cursor.doc_end" {
+		t.Fatalf("z z = %q, %v (status %q)", action, ok, m.statusMsg.Text)
+	}
+	m = pressKeys(t, m, "z", "z")
+	if m.cursorY != 2 {
+		t.Errorf("z z should go to the end, at %d", m.cursorY)
+	}
+
+	result, _ = m.executeCommand(":map read q cursor.nowhere")
+	m = result.(model)
+	if _, ok := m.activeKeymap().exact(keymapRead, "q"); ok {
+		t.Error("an unknown action should not be bound")
+	}
+
+	result, _ = m.executeCommand(":unmap read j")
+	m = result.(model)
+	if _, ok := m.activeKeymap().exact(keymapRead, "j"); ok {
+		t.Error(":unmap should remove the binding")
+	}
+	if _, ok := m.activeKeymap().exact(keymapRead, "z"+keySeparator+"z"); !ok {
+		t.Error("earlier :map bindings should survive")
+	}
+}
@@ -0,0 +1,540 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lintConfigFile is the per-project rule configuration, stored in the project root
+const lintConfigFile = ".tuiwritelint"
+
+// Linter flags style and grammar issues in prose
+type Linter struct {
+	enabled bool
+	config  LintConfig
+}
+
+// LintConfig controls which rules run and their thresholds
+type LintConfig struct {
+	Disabled         map[string]bool // rule name -> disabled
+	MaxSentenceWords int             // sentences longer than this are flagged
+	AdverbDensity    int             // percentage of -ly words in a paragraph before flagging
+}
+
+// LintFinding is a single issue reported by a rule
+type LintFinding struct {
+	Line    int // source line index
+	Start   int // byte offset of the start of the match
+	End     int // byte offset of the end of the match
+	Rule    string
+	Message string
+}
+
+// lintMatch is a match within a single line, before the line number is known
+type lintMatch struct {
+	start   int
+	end     int
+	message string
+}
+
+// lintContext is passed to every rule
+type lintContext struct {
+	language string // primary spell-check language
+	config   LintConfig
+}
+
+// lintRule is a named prose check. New rules are added with registerLintRule.
+type lintRule struct {
+	name        string
+	description string
+	check       func(line string, ctx lintContext) []lintMatch
+}
+
+// lintRules holds every registered rule, in reporting order
+var lintRules []lintRule
+
+// registerLintRule adds a rule to the linter
+func registerLintRule(rule lintRule) {
+	lintRules = append(lintRules, rule)
+}
+
+func init() {
+	registerLintRule(lintRule{"repeated-word", "Repeated words such as \"the the\"", checkRepeatedWords})
+	registerLintRule(lintRule{"passive", "Passive voice", checkPassiveVoice})
+	registerLintRule(lintRule{"adverbs", "High density of -ly adverbs", checkAdverbDensity})
+	registerLintRule(lintRule{"filler", "Filler words", checkFillerWords})
+	registerLintRule(lintRule{"cliche", "Clichés", checkCliches})
+	registerLintRule(lintRule{"long-sentence", "Overly long sentences", checkLongSentences})
+	registerLintRule(lintRule{"spelling-variant", "Spelling variants that don't match the spell-check language", checkSpellingVariants})
+}
+
+// defaultLintConfig returns the configuration used when a project has none
+func defaultLintConfig() LintConfig {
+	return LintConfig{
+		Disabled:         make(map[string]bool),
+		MaxSentenceWords: 35,
+		AdverbDensity:    5,
+	}
+}
+
+// newLinter creates a disabled linter with default rules
+func newLinter() *Linter {
+	return &Linter{
+		enabled: false,
+		config:  defaultLintConfig(),
+	}
+}
+
+// toggle enables or disables the linter
+func (l *Linter) toggle() {
+	l.enabled = !l.enabled
+}
+
+// lintLine runs every enabled rule against a single source line
+func (l *Linter) lintLine(line string, lineY int, language string) []LintFinding {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+
+	ctx := lintContext{language: language, config: l.config}
+	var findings []LintFinding
+	for _, rule := range lintRules {
+		if l.config.Disabled[rule.name] {
+			continue
+		}
+		for _, match := range rule.check(line, ctx) {
+			findings = append(findings, LintFinding{
+				Line:    lineY,
+				Start:   match.start,
+				End:     match.end,
+				Rule:    rule.name,
+				Message: match.message,
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Start < findings[j].Start
+	})
+	return findings
+}
+
+// lintDocument runs the linter over every line of a document
+func (l *Linter) lintDocument(lines []string, language string) []LintFinding {
+	var findings []LintFinding
+	for y, line := range lines {
+		findings = append(findings, l.lintLine(line, y, language)...)
+	}
+	return findings
+}
+
+// findingAt returns the finding covering a byte offset, if any
+func findingAt(findings []LintFinding, pos int) (LintFinding, bool) {
+	for _, f := range findings {
+		if pos >= f.Start && pos < f.End {
+			return f, true
+		}
+	}
+	return LintFinding{}, false
+}
+
+// loadLintConfig reads rule settings from the project's .tuiwritelint file.
+// Each line is "rule = on|off" or "option = value"; # starts a comment.
+func loadLintConfig(projectDir string) (LintConfig, error) {
+	config := defaultLintConfig()
+
+	file, err := os.Open(filepath.Join(projectDir, lintConfigFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return config, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return config, fmt.Errorf("%s:%d: expected key = value", lintConfigFile, lineNum)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		if err := config.set(key, value); err != nil {
+			return config, fmt.Errorf("%s:%d: %w", lintConfigFile, lineNum, err)
+		}
+	}
+
+	return config, scanner.Err()
+}
+
+// set applies a single "key = value" setting
+func (c *LintConfig) set(key string, value string) error {
+	switch key {
+	case "max-sentence-words":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("max-sentence-words must be a positive number")
+		}
+		c.MaxSentenceWords = n
+		return nil
+	case "adverb-density":
+		n, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || n < 0 || n > 100 {
+			return fmt.Errorf("adverb-density must be a percentage")
+		}
+		c.AdverbDensity = n
+		return nil
+	}
+
+	if !isLintRule(key) {
+		return fmt.Errorf("unknown lint rule or option %q", key)
+	}
+	switch strings.ToLower(value) {
+	case "on", "true", "yes":
+		delete(c.Disabled, key)
+	case "off", "false", "no":
+		c.Disabled[key] = true
+	default:
+		return fmt.Errorf("%s must be on or off", key)
+	}
+	return nil
+}
+
+// isLintRule reports whether name is a registered rule
+func isLintRule(name string) bool {
+	for _, rule := range lintRules {
+		if rule.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Rules ---
+
+// sentencePattern splits prose into sentences
+var sentencePattern = regexp.MustCompile(`[^.!?]+[.!?]*["'”’)]*`)
+
+// checkRepeatedWords flags the same word twice in a row ("the the")
+func checkRepeatedWords(line string, ctx lintContext) []lintMatch {
+	var matches []lintMatch
+	words := getWordsInLine(line)
+	for i := 1; i < len(words); i++ {
+		prev, cur := words[i-1], words[i]
+		if !strings.EqualFold(prev.word, cur.word) || strings.Trim(prev.word, "'-") == "" {
+			continue
+		}
+		// Only whitespace may separate the two words
+		if strings.TrimSpace(line[prev.end:cur.start]) != "" {
+			continue
+		}
+		matches = append(matches, lintMatch{prev.start, cur.end, fmt.Sprintf("Repeated word %q", cur.word)})
+	}
+	return matches
+}
+
+// passivePattern matches a form of "to be" followed by a past participle
+var passivePattern = regexp.MustCompile(`(?i)\b(am|is|are|was|were|be|been|being)\s+(\w+ed|\w+en|made|done|seen|known|given|taken|shown|built|found|held|kept|left|lost|paid|said|sent|sold|told|thought|brought|bought|caught|taught|felt|heard|led|meant|met|put|set|shot|spent|struck|understood|won|born|chosen|driven|eaten|forgotten|hidden|ridden|spoken|stolen|thrown|worn)\b`)
+
+// notParticiples are common -ed/-en words that aren't past participles
+var notParticiples = map[string]bool{
+	"often": true, "even": true, "open": true, "seven": true, "eleven": true,
+	"heaven": true, "garden": true, "children": true, "ten": true, "then": true,
+	"when": true, "need": true, "indeed": true,
+	"speed": true, "red": true, "bed": true, "shed": true, "wed": true,
+	"sacred": true, "naked": true, "wicked": true, "ragged": true, "hundred": true,
+	"kitchen": true, "golden": true, "wooden": true, "sudden": true, "token": true,
+}
+
+// checkPassiveVoice flags "was taken", "is known" and similar constructions
+func checkPassiveVoice(line string, ctx lintContext) []lintMatch {
+	if !isEnglishLanguage(ctx.language) {
+		return nil
+	}
+
+	var matches []lintMatch
+	for _, loc := range passivePattern.FindAllStringSubmatchIndex(line, -1) {
+		participle := strings.ToLower(line[loc[4]:loc[5]])
+		if notParticiples[participle] {
+			continue
+		}
+		matches = append(matches, lintMatch{loc[0], loc[1], "Passive voice: " + line[loc[0]:loc[1]]})
+	}
+	return matches
+}
+
+// notAdverbs are common words ending in -ly that aren't adverbs
+var notAdverbs = map[string]bool{
+	"only": true, "family": true, "reply": true, "early": true, "daily": true,
+	"friendly": true, "lovely": true, "ugly": true, "holy": true, "belly": true,
+	"jelly": true, "rally": true, "supply": true, "apply": true, "fly": true,
+	"july": true, "italy": true, "lily": true, "ally": true, "bully": true,
+	"silly": true, "lonely": true, "likely": true, "elderly": true, "costly": true,
+	"curly": true, "chilly": true, "folly": true, "jolly": true, "homely": true,
+	"lively": true, "deadly": true, "assembly": true, "monopoly": true, "anomaly": true,
+}
+
+// checkAdverbDensity flags -ly adverbs when a paragraph uses too many of them
+func checkAdverbDensity(line string, ctx lintContext) []lintMatch {
+	if !isEnglishLanguage(ctx.language) {
+		return nil
+	}
+
+	words := getWordsInLine(line)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var adverbs []wordPos
+	for _, w := range words {
+		lower := strings.ToLower(w.word)
+		if len(lower) > 4 && strings.HasSuffix(lower, "ly") && !notAdverbs[lower] {
+			adverbs = append(adverbs, w)
+		}
+	}
+
+	density := len(adverbs) * 100 / len(words)
+	if len(adverbs) < 2 || density <= ctx.config.AdverbDensity {
+		return nil
+	}
+
+	var matches []lintMatch
+	for _, w := range adverbs {
+		matches = append(matches, lintMatch{w.start, w.end, fmt.Sprintf("Adverb %q (%d%% of words in paragraph)", w.word, density)})
+	}
+	return matches
+}
+
+// fillerWords weaken prose without adding meaning
+var fillerWords = map[string]bool{
+	"just": true, "really": true, "very": true, "actually": true, "basically": true,
+	"literally": true, "quite": true, "somewhat": true, "totally": true, "simply": true,
+	"definitely": true, "certainly": true, "honestly": true, "seriously": true,
+}
+
+// checkFillerWords flags filler words such as "really" and "very"
+func checkFillerWords(line string, ctx lintContext) []lintMatch {
+	if !isEnglishLanguage(ctx.language) {
+		return nil
+	}
+
+	var matches []lintMatch
+	for _, w := range getWordsInLine(line) {
+		if fillerWords[strings.ToLower(w.word)] {
+			matches = append(matches, lintMatch{w.start, w.end, fmt.Sprintf("Filler word %q", w.word)})
+		}
+	}
+	return matches
+}
+
+// cliches is a list of overused phrases (lowercase)
+var cliches = []string{
+	"at the end of the day", "avoid it like the plague", "better late than never",
+	"crystal clear", "dead as a doornail", "easier said than done", "every cloud has a silver lining",
+	"fit as a fiddle", "in the nick of time", "last but not least", "only time will tell",
+	"scared to death", "sick as a dog", "slept like a log", "the calm before the storm",
+	"think outside the box", "time will tell", "under the weather", "when all is said and done",
+	"without further ado", "heart of gold", "cold as ice", "quiet as a mouse", "white as a sheet",
+	"all of a sudden", "in the blink of an eye", "a blessing in disguise", "the tip of the iceberg",
+	"hit the ground running", "needle in a haystack", "read between the lines", "blood ran cold",
+}
+
+// clichePattern matches any cliché as whole words, ignoring case. Matching the
+// line itself keeps byte offsets right where lowercasing would change lengths.
+var clichePattern = func() *regexp.Regexp {
+	phrases := make([]string, len(cliches))
+	for i, phrase := range cliches {
+		phrases[i] = regexp.QuoteMeta(phrase)
+	}
+	// Longer phrases first, so "only time will tell" wins over "time will tell"
+	sort.SliceStable(phrases, func(i, j int) bool { return len(phrases[i]) > len(phrases[j]) })
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(phrases, "|") + `)\b`)
+}()
+
+// checkCliches flags well-worn phrases
+func checkCliches(line string, ctx lintContext) []lintMatch {
+	if !isEnglishLanguage(ctx.language) {
+		return nil
+	}
+
+	var matches []lintMatch
+	for _, loc := range clichePattern.FindAllStringIndex(line, -1) {
+		start, end := loc[0], loc[1]
+		matches = append(matches, lintMatch{start, end, fmt.Sprintf("Cliché %q", line[start:end])})
+	}
+	return matches
+}
+
+// checkLongSentences flags sentences with more words than the configured limit
+func checkLongSentences(line string, ctx lintContext) []lintMatch {
+	var matches []lintMatch
+	for _, loc := range sentencePattern.FindAllStringIndex(line, -1) {
+		sentence := line[loc[0]:loc[1]]
+		count := len(strings.Fields(sentence))
+		if count > ctx.config.MaxSentenceWords {
+			// Don't underline leading whitespace
+			start := loc[0] + len(sentence) - len(strings.TrimLeft(sentence, " \t"))
+			matches = append(matches, lintMatch{start, loc[1], fmt.Sprintf("Long sentence (%d words, limit %d)", count, ctx.config.MaxSentenceWords)})
+		}
+	}
+	return matches
+}
+
+// variantPair links a British stem to its American equivalent. Stems that
+// aren't whole words (realis-, travell-) only match with a suffix.
+type variantPair struct {
+	uk   string
+	us   string
+	word bool // true if the stems are words on their own
+}
+
+// spellingVariants lists common British/American spelling differences
+var spellingVariants = []variantPair{
+	{"colour", "color", true}, {"favour", "favor", true}, {"honour", "honor", true}, {"labour", "labor", true},
+	{"neighbour", "neighbor", true}, {"behaviour", "behavior", true}, {"flavour", "flavor", true}, {"humour", "humor", true},
+	{"rumour", "rumor", true}, {"harbour", "harbor", true}, {"armour", "armor", true}, {"vapour", "vapor", true},
+	{"centre", "center", true}, {"theatre", "theater", true}, {"fibre", "fiber", true}, {"sombre", "somber", true},
+	{"realis", "realiz", false}, {"organis", "organiz", false}, {"recognis", "recogniz", false}, {"apologis", "apologiz", false},
+	{"criticis", "criticiz", false}, {"emphasis", "emphasiz", false}, {"analys", "analyz", false}, {"paralys", "paralyz", false},
+	{"travell", "travel", false}, {"cancell", "cancel", false}, {"defence", "defense", true}, {"offence", "offense", true},
+	{"grey", "gray", true}, {"aluminium", "aluminum", true}, {"catalogue", "catalog", true}, {"jewellery", "jewelry", true},
+	{"plough", "plow", true}, {"mould", "mold", true}, {"sceptic", "skeptic", true}, {"manoeuvre", "maneuver", true},
+}
+
+// variantSuffixes are the inflections accepted after a variant stem
+var variantSuffixes = []string{"", "s", "d", "ed", "ing", "er", "ers", "ful", "able", "ation", "ations", "e", "es", "ite"}
+
+// stemSuffixes replace variantSuffixes for pairs, by British stem, whose other
+// inflections are different words (analyses, emphases)
+var stemSuffixes = map[string][]string{
+	"analys":   {"e", "ed", "es", "ing", "er"},
+	"paralys":  {"e", "ed", "es", "ing", "er"},
+	"emphasis": {"e", "ed", "ing"},
+}
+
+// sharedSpellings match a British stem but are also American words, such as
+// the plurals of analysis and paralysis
+var sharedSpellings = map[string]bool{"analyses": true, "paralyses": true}
+
+// checkSpellingVariants flags UK spellings in US documents and vice versa
+func checkSpellingVariants(line string, ctx lintContext) []lintMatch {
+	var wantUK bool
+	switch ctx.language {
+	case "uk", "gb", "au":
+		wantUK = true
+	case "us":
+		wantUK = false
+	default:
+		return nil // Canadian English mixes both; other languages don't apply
+	}
+
+	var matches []lintMatch
+	for _, w := range getWordsInLine(line) {
+		lower := strings.ToLower(w.word)
+		for _, pair := range spellingVariants {
+			wrong, right := pair.uk, pair.us
+			if wantUK {
+				wrong, right = pair.us, pair.uk
+			}
+			suffixes, ok := stemSuffixes[pair.uk]
+			if !ok {
+				suffixes = variantSuffixes
+			}
+			suffix, ok := strings.CutPrefix(lower, wrong)
+			if !ok || !containsString(suffixes, suffix) || !wantUK && sharedSpellings[lower] {
+				continue
+			}
+			// Partial stems need an inflection: "travel" is fine, "traveled" isn't
+			if !pair.word && (suffix == "" || suffix == "s") {
+				continue
+			}
+			matches = append(matches, lintMatch{w.start, w.end,
+				fmt.Sprintf("%q is a %s spelling; %s uses %q", w.word, variantName(!wantUK), variantName(wantUK), right+suffix)})
+			break
+		}
+	}
+	return matches
+}
+
+// variantName names a spelling convention
+func variantName(uk bool) string {
+	if uk {
+		return "British"
+	}
+	return "American"
+}
+
+// isEnglishLanguage reports whether a spell-check language is an English variant
+func isEnglishLanguage(lang string) bool {
+	switch lang {
+	case "uk", "gb", "us", "ca", "au", "":
+		return true
+	}
+	return false
+}
+
+// spellLanguage returns the primary spell-check language (used by language-aware rules)
+func (m model) spellLanguage() string {
+	if m.spellChecker == nil {
+		return ""
+	}
+	return m.spellChecker.language
+}
+
+// lintFindingsForLine returns the lint findings for a source line (nil when disabled)
+func (m model) lintFindingsForLine(lineY int) []LintFinding {
+	if m.linter == nil || !m.linter.enabled || lineY < 0 || lineY >= len(m.lines) {
+		return nil
+	}
+	return m.linter.lintLine(m.lines[lineY], lineY, m.spellLanguage())
+}
+
+// showLintFindings lints the whole document and lists the findings in the quickfix pane
+func (m *model) showLintFindings() {
+	m.linter.enabled = true
+	findings := m.linter.lintDocument(m.lines, m.spellLanguage())
+
+	items := make([]quickfixItem, len(findings))
+	for i, f := range findings {
+		items[i] = quickfixItem{line: f.Line, col: f.Start, text: "[" + f.Rule + "] " + f.Message}
+	}
+	m.openQuickfix("Lint", items)
+}
+
+// reloadLintConfig reads the project's lint rules, keeping the current rules on error
+func (m *model) reloadLintConfig() error {
+	config, err := loadLintConfig(m.projectDir())
+	if err != nil {
+		return err
+	}
+	m.linter.config = config
+	return nil
+}
+
+// lintRuleSummary lists the rules with their on/off state, e.g. "passive, adverbs (off)"
+func (l *Linter) lintRuleSummary() string {
+	var names []string
+	for _, rule := range lintRules {
+		if l.config.Disabled[rule.name] {
+			names = append(names, rule.name+" (off)")
+		} else {
+			names = append(names, rule.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lintRulesHit returns the names of the rules reporting on a line
+func lintRulesHit(l *Linter, line string, language string) map[string]int {
+	hits := make(map[string]int)
+	for _, f := range l.lintLine(line, 0, language) {
+		hits[f.Rule]++
+	}
+	return hits
+}
+
+// TestLintRules verifies each built-in rule fires on a typical example
+func TestLintRules(t *testing.T) {
+	l := newLinter()
+
+	cases := []struct {
+		rule string
+		line string
+		lang string
+	}{
+		{"repeated-word", "She walked to the the door.", "uk"},
+		{"passive", "The letter was written by her brother.", "uk"},
+		{"adverbs", "He quickly and quietly and carefully left.", "uk"},
+		{"filler", "It was really cold.", "uk"},
+		{"cliche", "At the end of the day, nobody came.", "uk"},
+		{"spelling-variant", "The color of the sky.", "uk"},
+		{"spelling-variant", "The colour of the sky.", "us"},
+		{"spelling-variant", "She analysed the results.", "us"},
+		{"spelling-variant", "He analyzes the results.", "uk"},
+		{"spelling-variant", "They emphasised the point.", "us"},
+		{"spelling-variant", "It paralyzes the town.", "uk"},
+	}
+
+	for _, c := range cases {
+		if hits := lintRulesHit(l, c.line, c.lang); hits[c.rule] == 0 {
+			t.Errorf("%s: expected a finding for %q, got %v", c.rule, c.line, hits)
+		}
+	}
+}
+
+// TestLintNoFalsePositives verifies common words don't trigger rules
+func TestLintNoFalsePositives(t *testing.T) {
+	l := newLinter()
+
+	cases := []struct {
+		rule string
+		line string
+		lang string
+	}{
+		{"passive", "The door was open and it was often cold.", "uk"},
+		{"spelling-variant", "They travel by train.", "uk"},
+		{"spelling-variant", "They travel by train.", "us"},
+		{"spelling-variant", "The colour of the sky.", "ca"},
+		{"spelling-variant", "Both analyses agree.", "us"},
+		{"spelling-variant", "The emphases differ.", "us"},
+		{"spelling-variant", "Sleep paralyses are common.", "us"},
+		{"spelling-variant", "She stressed the emphasis.", "us"},
+		{"repeated-word", "Yes. Yes, she said.", "uk"},
+		{"adverbs", "She smiled only once.", "uk"},
+	}
+
+	for _, c := range cases {
+		if hits := lintRulesHit(l, c.line, c.lang); hits[c.rule] != 0 {
+			t.Errorf("%s: unexpected finding for %q", c.rule, c.line)
+		}
+	}
+}
+
+// TestLintClicheOffsets verifies cliché spans stay on the original text when
+// earlier characters change byte length under lowercasing
+func TestLintClicheOffsets(t *testing.T) {
+	line := strings.Repeat("Ⱥ", 10) + " İs it Crystal Clear? Only time will tell."
+	matches := checkCliches(line, lintContext{language: "uk"})
+	var got []string
+	for _, match := range matches {
+		got = append(got, line[match.start:match.end])
+	}
+	if want := []string{"Crystal Clear", "Only time will tell"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("matched %q, want %q", got, want)
+	}
+	if hits := lintRulesHit(newLinter(), "clearcrystal clear", "uk"); hits["cliche"] != 0 {
+		t.Error("a cliché inside a word should not match")
+	}
+}
+
+// TestLintLongSentence verifies the configurable sentence limit
+func TestLintLongSentence(t *testing.T) {
+	l := newLinter()
+	l.config.MaxSentenceWords = 5
+
+	hits := lintRulesHit(l, "Short one. This sentence has far too many words in it.", "uk")
+	if hits["long-sentence"] != 1 {
+		t.Errorf("expected exactly one long sentence, got %d", hits["long-sentence"])
+	}
+}
+
+// TestLoadLintConfig verifies .tuiwritelint parsing and validation
+func TestLoadLintConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, lintConfigFile)
+
+	content := "# house style\npassive = off\nmax-sentence-words = 25\nadverb-density = 10%\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadLintConfig(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !config.Disabled["passive"] || config.MaxSentenceWords != 25 || config.AdverbDensity != 10 {
+		t.Errorf("config not applied: %+v", config)
+	}
+
+	if err := os.WriteFile(path, []byte("no-such-rule = off\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadLintConfig(dir); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
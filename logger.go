@@ -5,21 +5,45 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 )
 
 // Logger handles debug logging to file
 type Logger struct {
-	file    *os.File
-	enabled bool
+	file     *os.File
+	enabled  bool
+	minLevel int // entries below this severity are dropped
+}
+
+// logLevels orders the severities; other entries (sessions, events) are always written
+var logLevels = map[string]int{
+	"DEBUG":   0,
+	"INFO":    1,
+	"WARNING": 2,
+	"ERROR":   3,
 }
 
 var globalLogger *Logger
 
-// initLogger initializes the debug logger
-func initLogger() error {
+// parseLogLevel accepts debug, info, warning (or warn) and error
+func parseLogLevel(level string) (int, error) {
+	name := strings.ToUpper(level)
+	if name == "WARN" {
+		name = "WARNING"
+	}
+	severity, ok := logLevels[name]
+	if !ok {
+		return 0, fmt.Errorf("invalid log level %q (use debug, info, warning or error)", level)
+	}
+	return severity, nil
+}
+
+// initLogger initializes the debug logger. A disabled logger drops every entry.
+func initLogger(enabled bool, minLevel int) error {
 	logger := &Logger{
-		enabled: true, // Can be toggled via command-line flag later
+		enabled:  enabled,
+		minLevel: minLevel,
 	}
 
 	if !logger.enabled {
@@ -82,6 +106,9 @@ func (l *Logger) log(level string, message string) {
 	if !l.enabled || l.file == nil {
 		return
 	}
+	if severity, ok := logLevels[level]; ok && severity < l.minLevel {
+		return
+	}
 
 	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
 	logLine := fmt.Sprintf("[%s] %s: %s\n", timestamp, level, message)
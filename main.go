@@ -4,31 +4,43 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // Init initializes the model
 func (m model) Init() tea.Cmd {
 	return tea.Batch(
 		tea.EnterAltScreen,
-		tickAutoSave(),
-		tickCursorBlink(),
+		tickAutoSave(m.config.AutoSaveInterval.Duration),
+		tickCursorBlink(m.config.CursorBlink.Duration),
+		tickConfigCheck(),
+		m.treeWatchCmds(),
+		m.treeGitCmd(false),
 	)
 }
 
-// tickAutoSave returns a command that sends autoSaveMsg every 30 minutes
-func tickAutoSave() tea.Cmd {
-	return tea.Tick(30*time.Minute, func(t time.Time) tea.Msg {
+// tickAutoSave returns a command that sends autoSaveMsg after the auto-save interval (nil when disabled)
+func tickAutoSave(interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
 		return autoSaveMsg(t)
 	})
 }
 
-// tickCursorBlink returns a command that sends cursorBlinkMsg every 500ms
-func tickCursorBlink() tea.Cmd {
-	return tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
+// tickCursorBlink returns a command that sends cursorBlinkMsg after the blink interval (nil when disabled)
+func tickCursorBlink(interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
 		return cursorBlinkMsg(t)
 	})
 }
@@ -40,13 +52,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Reset cursor to visible when user types
 		m.cursorVisible = true
 		m.lastCursorBlink = time.Now()
-		return m.handleKeyPress(msg)
+		newModel, cmd := m.handleKeyPress(msg)
+		if nm, ok := newModel.(model); ok {
+			// Recount only after an edit; cursor keys leave the text as it was
+			if nm.wordsStale || nm.modified != m.modified {
+				nm.wordsStale = false
+				nm.trackWords()
+			}
+			return nm, cmd
+		}
+		return newModel, cmd
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		// Recalculate word wrapping when window size changes
 		m.rewrapLines()
+		// Keep a cursor placed by +LINE or file:line on screen
+		m.adjustViewport()
 		return m, nil
 
 	case autoSaveMsg:
@@ -56,13 +79,56 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.setStatus("Auto-saved", "green")
 			}
 		}
-		return m, tickAutoSave()
+		cmd := tickAutoSave(m.config.AutoSaveInterval.Duration)
+		m.autoSaveRunning = cmd != nil
+		return m, cmd
+
+	case configCheckMsg:
+		// Reload settings when a config file is edited
+		if configStamp(configPaths(m.projectDir())) != m.configStamp {
+			cmd, err := m.reloadConfig()
+			if err != nil {
+				m.setStatus("Config error: "+err.Error(), "red")
+			} else {
+				m.setStatus("Reloaded config", "green")
+			}
+			return m, tea.Batch(cmd, tickConfigCheck())
+		}
+		return m, tickConfigCheck()
+
+	case sprintTickMsg:
+		return m.handleSprintTick(msg)
+
+	case finderFilesMsg:
+		return m.handleFinderFiles(msg)
+
+	case treeDirLoadedMsg:
+		return m.handleTreeDirLoaded(msg)
+
+	case treeChangedMsg:
+		// Folders changed on disk: read them again and keep listening
+		gitCmd := m.treeGitCmd(true)
+		return m, tea.Batch(m.handleTreeChanged(msg.dirs), m.treeWatcher.wait(), gitCmd)
+
+	case treePollMsg:
+		if m.treeWatcher == nil {
+			return m, nil
+		}
+		changed := m.treeWatcher.poll()
+		gitCmd := m.treeGitCmd(len(changed) > 0)
+		return m, tea.Batch(m.handleTreeChanged(changed), tickTreePoll(), gitCmd)
+
+	case treeGitMsg:
+		m.handleTreeGit(msg)
+		return m, nil
 
 	case cursorBlinkMsg:
-		// Toggle cursor visibility
-		m.cursorVisible = !m.cursorVisible
+		// Toggle cursor visibility (a steady cursor when blinking is off)
+		cmd := tickCursorBlink(m.config.CursorBlink.Duration)
+		m.cursorBlinkRunning = cmd != nil
+		m.cursorVisible = !m.cursorVisible || cmd == nil
 		m.lastCursorBlink = time.Now()
-		return m, tickCursorBlink()
+		return m, cmd
 
 	case tea.MouseMsg:
 		return m.handleMouse(msg)
@@ -71,109 +137,127 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// handleKeyPress processes keyboard input
+// handleKeyPress processes keyboard input through the keymap
 func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Handle command mode separately
-	if m.commandMode {
-		return m.handleCommandMode(msg)
-	}
+	key := msg.String()
 
-	// Global keybindings (work in both modes)
-	switch msg.String() {
-	case "ctrl+q":
-		return m, tea.Quit
+	// The command palette and the file finder take every key while open
+	if m.paletteVisible {
+		return m.handlePaletteKeys(msg)
+	}
+	if m.finderVisible {
+		return m.handleFinderKeys(msg)
+	}
+	if m.treeFilterTyping && m.fileTreeFocused && !m.commandMode {
+		return m.handleTreeFilterKeys(msg)
+	}
+	if msg.Paste {
+		return m.handlePaste(msg)
+	}
+	if m.awaitingRegister {
+		return m.handleRegisterKey(msg)
+	}
 
-	case "ctrl+s":
-		err := m.saveFile()
-		if err != nil {
-			m.setStatus("Error saving: "+err.Error(), "red")
-		} else {
-			m.setStatus("Saved "+m.filename, "green")
+	// Open panes take every key that isn't a global binding
+	if !m.commandMode && len(m.pendingKeys) == 0 {
+		if m.infoVisible || m.thesaurusVisible || m.quickfixFocused {
+			if action, ok := m.activeKeymap().exact(keymapGlobal, key); ok {
+				return m.runAction(action)
+			}
 		}
-		return m, nil
 
-	case "f1":
-		// Toggle file tree sidebar
-		return m.toggleFileTree()
-
-	case "insert":
-		// Toggle between Read and Edit mode (only if file tree not focused)
-		if m.fileTreeFocused {
-			return m, nil
+		// The info pane takes keys while it is open
+		if m.infoVisible {
+			return m.handleInfoPaneKeys(key)
 		}
-		if m.mode == ReadMode {
-			m.mode = EditMode
-			m.setStatus("-- EDIT MODE --", "green")
-		} else {
-			m.mode = ReadMode
-			m.setStatus("-- READ MODE --", "green")
-		}
-		return m, nil
 
-	case "esc":
-		if m.mode == EditMode {
-			m.mode = ReadMode
-			m.setStatus("-- READ MODE --", "green")
+		// The thesaurus pane takes keys while it is open
+		if m.thesaurusVisible {
+			return m.handleThesaurusKeys(key)
 		}
-		return m, nil
 
-	case ":":
-		// Enter command mode (only in Read mode and file tree not focused)
-		if m.mode == ReadMode && !m.fileTreeFocused {
-			m.commandMode = true
-			m.commandBuffer = ":"
-			return m, nil
+		// If the quickfix pane is focused, navigate its list
+		if m.quickfixFocused {
+			return m.handleQuickfixNavigation(key)
 		}
-		// If in Edit mode, fall through to normal edit handling
 	}
 
-	// If file tree is focused, handle file tree navigation
-	if m.fileTreeFocused {
-		return m.handleFileTreeNavigation(msg.String())
-	}
+	return m.dispatchKey(msg)
+}
 
-	// Mode-specific keybindings
-	if m.mode == ReadMode {
-		return m.handleReadMode(msg)
-	} else {
-		return m.handleEditMode(msg)
+// handlePaste inserts text pasted through the terminal (a bracketed paste) in
+// one piece: into the document in Edit mode, or into the command line
+func (m model) handlePaste(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.pendingKeys = nil
+	m.awaitingRegister = false
+	text := normalizePaste(string(msg.Runes))
+	if m.infoVisible || m.thesaurusVisible || m.quickfixFocused {
+		return m, nil // the panes take no text
 	}
+	switch m.keymapMode() {
+	case keymapEdit:
+		m.saveUndo(undoEdit) // the whole paste is one undo step
+		m.insertPaste(text)
+		m.dropUnchangedUndo()
+		m.wordsStale = true
+		if lines := strings.Count(text, "\n") + 1; lines > 1 {
+			m.setStatus(fmt.Sprintf("Pasted %d lines", lines), "green")
+		}
+	case keymapCommand:
+		// The command line holds a single line
+		line, _, _ := strings.Cut(text, "\n")
+		m.insertCommandText(line)
+	case keymapRead:
+		m.setStatus("Switch to Edit mode to paste", "yellow")
+	}
+	return m, nil
 }
 
-// handleCommandMode processes keys when in command mode
-func (m model) handleCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
-		// Exit command mode
-		m.commandMode = false
-		m.commandBuffer = ""
-		return m, nil
+// openCommandLine enters command mode with an empty ":" prompt
+func (m *model) openCommandLine() {
+	m.commandMode = true
+	m.commandBuffer = ":"
+	m.commandCursor = 1
+	m.historyIndex = -1
+	m.closeCompletions()
+	if !m.historyLoaded {
+		m.commandHistory = loadCommandHistory()
+		m.historyLoaded = true
+	}
+}
 
-	case "enter":
-		// Execute command
-		cmd := m.commandBuffer
-		m.commandMode = false
-		m.commandBuffer = ""
-		return m.executeCommand(cmd)
-
-	case "backspace":
-		// Delete character from command buffer
-		if len(m.commandBuffer) > 1 {
-			m.commandBuffer = m.commandBuffer[:len(m.commandBuffer)-1]
-		} else {
-			// If only ":" left, exit command mode
-			m.commandMode = false
-			m.commandBuffer = ""
-		}
-		return m, nil
+// cancelCommandLine leaves command mode without running anything. With the
+// completion menu open it only closes the menu.
+func (m *model) cancelCommandLine() {
+	if len(m.completions) > 0 {
+		m.closeCompletions()
+		return
+	}
+	m.commandMode = false
+	m.commandBuffer = ""
+}
 
-	default:
-		// Add character to command buffer
-		if len(msg.Runes) == 1 {
-			m.commandBuffer += string(msg.Runes[0])
-		}
-		return m, nil
+// commandBackspace deletes the character before the cursor, leaving command mode at the bare ":"
+func (m *model) commandBackspace() {
+	m.clampCommandCursor()
+	if len(m.commandBuffer) <= 1 {
+		m.cancelCommandLine()
+		return
 	}
+	if m.commandCursor > 1 {
+		_, size := utf8.DecodeLastRuneInString(m.commandBuffer[:m.commandCursor])
+		m.deleteCommandRange(m.commandCursor-size, m.commandCursor)
+	}
+}
+
+// submitCommandLine runs the typed command and remembers it
+func (m model) submitCommandLine() (tea.Model, tea.Cmd) {
+	cmd := m.commandBuffer
+	m.commandMode = false
+	m.commandBuffer = ""
+	m.closeCompletions()
+	m.addCommandHistory(strings.TrimPrefix(cmd, ":"))
+	return m.executeCommand(cmd)
 }
 
 // executeCommand executes a command entered in command mode
@@ -196,31 +280,140 @@ func (m model) executeCommand(cmd string) (tea.Model, tea.Cmd) {
 			// Toggle spell checking
 			m.spellChecker.toggle()
 			if m.spellChecker.enabled {
-				m.setStatus("Spell checking enabled ("+m.spellChecker.language+")", "green")
+				if err := m.spellChecker.ensureLoaded(); err != nil {
+					m.spellChecker.enabled = false
+					m.setStatus("Failed to load dictionary: "+err.Error(), "red")
+					return m, nil
+				}
+				m.setStatus("Spell checking enabled ("+m.spellChecker.languageLabel()+")", "green")
 			} else {
 				m.setStatus("Spell checking disabled", "yellow")
 			}
-		} else if len(parts) == 2 {
-			// Set language
-			lang := strings.TrimPrefix(parts[1], "-")
-			lang = strings.ToLower(lang)
-
-			// Check if dictionary needs downloading
-			if !m.spellChecker.hasDictionary(lang) {
-				m.setStatus("Downloading "+strings.ToUpper(lang)+" dictionary...", "yellow")
+		} else {
+			// Set one or more languages, e.g. ":spell uk es"
+			var langs []string
+			for _, arg := range parts[1:] {
+				lang := strings.ToLower(strings.TrimPrefix(arg, "-"))
+				if normalized := normalizeLangCode(lang); normalized != "" {
+					lang = normalized
+				}
+				langs = append(langs, lang)
+
+				// Check if dictionary needs downloading
+				if !m.spellChecker.hasDictionary(lang) {
+					m.setStatus("Downloading "+strings.ToUpper(lang)+" dictionary...", "yellow")
+				}
 			}
 
-			// Set the language (will download if needed)
-			err := m.spellChecker.setLanguage(lang)
+			// Set the languages (will download if needed)
+			err := m.spellChecker.setLanguages(langs)
 			if err != nil {
 				m.setStatus("Failed to load dictionary: "+err.Error(), "red")
 			} else {
-				m.setStatus("Spell-check enabled ("+strings.ToUpper(lang)+")", "green")
+				m.setStatus("Spell-check enabled ("+m.spellChecker.languageLabel()+")", "green")
+				if err := saveProjectLanguages(m.projectDir(), m.spellChecker.languages); err != nil {
+					LogWarningf("Failed to remember project languages: %v", err)
+				}
 			}
 		}
 		return m, nil
 
+	case "lint":
+		sub := ""
+		if len(parts) > 1 {
+			sub = parts[1]
+		}
+		switch sub {
+		case "":
+			m.linter.toggle()
+			if m.linter.enabled {
+				m.setStatus("Prose linting enabled (:lint list to show findings)", "green")
+			} else {
+				m.setStatus("Prose linting disabled", "yellow")
+			}
+		case "on":
+			m.linter.enabled = true
+			m.setStatus("Prose linting enabled", "green")
+		case "off":
+			m.linter.enabled = false
+			m.setStatus("Prose linting disabled", "yellow")
+		case "list":
+			m.showLintFindings()
+		case "rules":
+			m.setStatus("Lint rules: "+m.linter.lintRuleSummary(), "green")
+		case "reload":
+			if err := m.reloadLintConfig(); err != nil {
+				m.setStatus("Lint config error: "+err.Error(), "red")
+			} else {
+				m.setStatus("Reloaded lint rules from "+lintConfigFile, "green")
+			}
+		default:
+			m.setStatus("Usage: :lint [on|off|list|rules|reload]", "yellow")
+		}
+		return m, nil
+
+	case "stats", "statistics":
+		m.toggleStatsPanel()
+		return m, nil
+
+	case "thesaurus", "thes":
+		m.openThesaurus()
+		return m, nil
+
+	case "goal":
+		m.setGoal(parts[1:])
+		return m, nil
+
+	case "goals":
+		m.showGoalsReport()
+		return m, nil
+
+	case "registers", "reg", "display":
+		m.showRegisters()
+		return m, nil
+
+	case "put", "pu":
+		return m.handlePutCommand(parts[1:])
+
+	case "set":
+		return m.handleSetCommand(parts[1:])
+
+	case "source":
+		cmd, err := m.reloadConfig()
+		if err != nil {
+			m.setStatus("Config error: "+err.Error(), "red")
+			return m, nil
+		}
+		m.setStatus("Reloaded config", "green")
+		return m, cmd
+
+	case "theme":
+		return m.handleThemeCommand(parts[1:])
+
+	case "map":
+		return m.handleMapCommand(parts[1:])
+
+	case "unmap":
+		return m.handleUnmapCommand(parts[1:])
+
+	case "keys":
+		mode := ""
+		if len(parts) > 1 {
+			mode = parts[1]
+		}
+		m.showKeymap(mode)
+		return m, nil
+
+	case "zen":
+		return m.handleZenCommand(parts[1:])
+
+	case "sprint":
+		return m.handleSprintCommand(parts[1:])
+
 	case "q", "quit":
+		if m.quitBlocked() {
+			return m, nil
+		}
 		return m, tea.Quit
 
 	case "w", "write":
@@ -238,6 +431,9 @@ func (m model) executeCommand(cmd string) (tea.Model, tea.Cmd) {
 			m.setStatus("Error saving: "+err.Error(), "red")
 			return m, nil
 		}
+		if m.quitBlocked() {
+			return m, nil
+		}
 		return m, tea.Quit
 
 	case "e", "edit", "open":
@@ -248,6 +444,16 @@ func (m model) executeCommand(cmd string) (tea.Model, tea.Cmd) {
 		}
 		return m.openFileInCurrentInstance(parts[1])
 
+	case "next", "n":
+		return m.openArgFile(m.argIndex + 1)
+
+	case "prev", "previous", "N":
+		return m.openArgFile(m.argIndex - 1)
+
+	case "args":
+		m.showArgFiles()
+		return m, nil
+
 	case "new", "vnew", "split":
 		// Open file in new instance (tmux/screen split if available)
 		if len(parts) < 2 {
@@ -256,9 +462,18 @@ func (m model) executeCommand(cmd string) (tea.Model, tea.Cmd) {
 		}
 		return m.openFileInNewInstance(parts[1], parts[0])
 
+	case "mkfile", "mkdir", "rename", "move", "duplicate", "delete", "undelete":
+		// File operations, on the tree selection or the open file
+		arg := strings.TrimSpace(strings.TrimPrefix(cmd, parts[0]))
+		return m.fileCommand(parts[0], arg)
+
 	case "help", "h":
-		// Show command help
-		return m.showMultiplexerHelp()
+		// ":help split" explains the split commands; plain ":help" lists everything
+		if len(parts) > 1 && containsString([]string{"new", "vnew", "split"}, parts[1]) {
+			return m.showMultiplexerHelp()
+		}
+		m.openPalette()
+		return m, nil
 
 	default:
 		m.setStatus("Unknown command: "+parts[0], "red")
@@ -266,76 +481,6 @@ func (m model) executeCommand(cmd string) (tea.Model, tea.Cmd) {
 	}
 }
 
-// handleMouse processes mouse events for text selection
-func (m model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	// Only handle mouse in edit mode
-	if m.mode != EditMode {
-		return m, nil
-	}
-
-	// Skip if file tree is focused
-	if m.fileTreeFocused {
-		return m, nil
-	}
-
-	switch msg.Button {
-	case tea.MouseButtonLeft:
-		if msg.Action == tea.MouseActionPress {
-			// Mouse down - start selection
-			// Convert screen coordinates to document coordinates
-			// msg.Y is screen row, need to account for status bars and viewport offset
-			// First 2 lines are status, so document starts at line 2
-			screenY := msg.Y - 2 // Account for status bars at top
-			if screenY < 0 {
-				return m, nil // Click was in status area
-			}
-
-			// Convert wrapped line position to source line
-			wrappedIdx := m.offsetY + screenY
-			sourceY, offsetInLine := m.getSourceLineFromWrappedIndex(wrappedIdx)
-
-			// Calculate X position (accounting for horizontal scroll)
-			clickX := msg.X + m.offsetX + offsetInLine
-
-			// Clamp to line bounds
-			if sourceY >= 0 && sourceY < len(m.lines) {
-				lineLen := len(m.lines[sourceY])
-				if clickX > lineLen {
-					clickX = lineLen
-				}
-
-				m.cursorY = sourceY
-				m.cursorX = clickX
-				m.selectionActive = true
-				m.selectionStartX = clickX
-				m.selectionStartY = sourceY
-			}
-		} else if msg.Action == tea.MouseActionMotion && m.selectionActive {
-			// Mouse drag - extend selection
-			screenY := msg.Y - 2
-			if screenY < 0 {
-				return m, nil
-			}
-
-			wrappedIdx := m.offsetY + screenY
-			sourceY, offsetInLine := m.getSourceLineFromWrappedIndex(wrappedIdx)
-			clickX := msg.X + m.offsetX + offsetInLine
-
-			if sourceY >= 0 && sourceY < len(m.lines) {
-				lineLen := len(m.lines[sourceY])
-				if clickX > lineLen {
-					clickX = lineLen
-				}
-
-				m.cursorY = sourceY
-				m.cursorX = clickX
-			}
-		}
-	}
-
-	return m, nil
-}
-
 // getUntitledFilename generates a unique untitled document filename
 func getUntitledFilename() string {
 	// Find the next available untitled-document-N
@@ -350,23 +495,97 @@ func getUntitledFilename() string {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if sub, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(sub.run(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+	os.Exit(runEditor(os.Args[1:]))
+}
+
+// runEditor parses the editor's flags and runs the TUI, returning the exit code
+func runEditor(argv []string) int {
+	fs := flag.NewFlagSet("tuiwrite", flag.ContinueOnError)
+	modeFlag := fs.String("mode", "story", "Document mode: story or script")
+	colorFlag := fs.String("color", colorModeAuto, "Use colors: never, auto or always")
+	colorTestFlag := fs.Bool("color-test", false, "Show the theme colors as this terminal renders them, then exit")
+	readOnlyFlag := fs.Bool("readonly", false, "Open files for reading only (no edits or saves)")
+	fs.StringVar(&userConfigOverride, "config", "", "Use this config file instead of the user config.toml")
+	logLevelFlag := fs.String("log-level", "debug", "Lowest level written to the log: debug, info, warning or error")
+	noLogFlag := fs.Bool("no-log", false, "Don't write a log file")
+	versionFlag := fs.Bool("version", false, "Print the version and exit")
+	fs.Usage = func() { printUsage(fs, os.Stderr) }
+
+	// Flags may come before or after file names
+	positional, err := parseInterleaved(fs, argv)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *versionFlag {
+		fmt.Printf("tuiwrite %s\n", version)
+		return exitOK
+	}
+
+	targets, err := parseFileArgs(positional)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tuiwrite: %v\n", err)
+		return exitUsage
+	}
+	if userConfigOverride != "" {
+		if _, err := os.Stat(userConfigOverride); err != nil {
+			fmt.Fprintf(os.Stderr, "tuiwrite: --config: %v\n", err)
+			return exitUsage
+		}
+	}
+	minLevel, err := parseLogLevel(*logLevelFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tuiwrite: --log-level: %v\n", err)
+		return exitUsage
+	}
+	if *modeFlag != "story" && *modeFlag != "script" {
+		fmt.Fprintf(os.Stderr, "tuiwrite: --mode must be story or script, not %q\n", *modeFlag)
+		return exitUsage
+	}
+
+	// Pick the color level before anything is rendered
+	support, err := colorSupportForMode(*colorFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	setColorSupport(support)
+
+	// "theme = auto" follows the terminal's background
+	terminalDarkBackground = lipgloss.HasDarkBackground()
+
+	if *colorTestFlag {
+		cfg, err := loadConfig(configPaths("."))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Config error: %v\n", err)
+		}
+		theme, err := loadTheme(cfg.themeName())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		printColorTest(theme, support)
+		return exitOK
+	}
+
 	// Initialize logger
-	if err := initLogger(); err != nil {
+	if err := initLogger(!*noLogFlag, minLevel); err != nil {
 		fmt.Printf("Warning: Failed to initialize logger: %v\n", err)
 	}
 	defer CloseLogger()
 
-	// Parse command line arguments
-	modeFlag := flag.String("mode", "story", "Document mode: story or script")
-	flag.Parse()
-
-	args := flag.Args()
-
 	var filename string
 	var lines []string
 	var isUntitled bool
 
-	if len(args) == 0 {
+	if len(targets) == 0 {
 		// No filename provided - create untitled document
 		filename = getUntitledFilename()
 		lines = []string{""}
@@ -374,7 +593,7 @@ func main() {
 		LogInfo("Starting with untitled document: " + filename)
 	} else {
 		// Filename provided
-		filename = args[0]
+		filename = targets[0].path
 		isUntitled = false
 
 		// Load or create file
@@ -382,7 +601,7 @@ func main() {
 		lines, err = loadFile(filename)
 		if err != nil {
 			fmt.Printf("Error loading file: %v\n", err)
-			os.Exit(1)
+			return exitFailure
 		}
 		LogInfo("Loaded file: " + filename)
 	}
@@ -402,9 +621,9 @@ func main() {
 		cursorY:           0,
 		offsetY:           0,
 		offsetX:           0,
-		mode:              ReadMode,    // Start in read mode
-		saved:             !isUntitled, // Untitled docs start as unsaved
-		modified:          isUntitled,  // Untitled docs start as modified
+		mode:              ReadMode,                     // Start in read mode
+		saved:             !isUntitled,                  // Untitled docs start as unsaved
+		modified:          isUntitled && !*readOnlyFlag, // Untitled docs start as modified
 		lastSave:          time.Now(),
 		wrapCache:         make(map[int][]wrappedLine),
 		wrapWidth:         0,
@@ -414,24 +633,77 @@ func main() {
 		zenMode:           false,
 		cursorVisible:     true, // Start with cursor visible
 		lastCursorBlink:   time.Now(),
-		spellChecker:      newSpellChecker("uk"), // Default to UK English
+		spellChecker:      newSpellChecker(defaultConfig().SpellLanguage),
+		config:            defaultConfig(),
+		linter:            newLinter(),
+		statsCache:        &statsCache{},
+		wordCounter:       newWordCounter(),
 		commandMode:       false,
 		commandBuffer:     "",
 		fileTreeVisible:   false,
 		fileTreeFocused:   false,
 		fileTreeCursor:    0,
 		fileTreeOffset:    0,
+		argFiles:          targets,
+		readOnly:          *readOnlyFlag,
 	}
 
-	// Initialize file tree
+	// Load settings before anything that depends on them
+	if _, err := m.reloadConfig(); err != nil {
+		m.setStatus("Config error: "+err.Error(), "red")
+	}
+	if presetStartsInEdit[m.config.Keymap] && !m.readOnly {
+		m.mode = EditMode
+	}
+
+	// Initialize file tree, kept up to date as files change on disk
+	m.treeWatcher = newTreeWatcher()
 	if err := m.initFileTree(); err != nil {
 		LogWarningf("Failed to initialize file tree: %v", err)
 	}
 
+	// Load the project's lint rules
+	if err := m.reloadLintConfig(); err != nil {
+		LogWarningf("Failed to load lint rules: %v", err)
+		m.setStatus("Lint config error: "+err.Error(), "red")
+	}
+
+	// Pick spell-check languages from the document or the project
+	if missing := m.applyDocumentLanguages(); len(missing) > 0 {
+		m.setStatus(missingDictionaryMessage(missing), "yellow")
+	}
+
+	// Jump to +LINE or file:line:col
+	if len(targets) > 0 {
+		m.jumpTo(targets[0].line, targets[0].col)
+	}
+	if len(targets) > 1 && m.statusMsg.Text == "" {
+		m.setStatus(fmt.Sprintf("%d files (:next, :prev, :args)", len(targets)), "green")
+	}
+
+	// Start tracking words written for goals and streaks
+	m.startSession()
+	if !isUntitled {
+		if abs, err := filepath.Abs(filename); err == nil {
+			rememberRecentFile(abs)
+		}
+	}
+
 	// Run the program
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
-	if _, err := p.Run(); err != nil {
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(programOutput))
+	final, err := p.Run()
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
-		os.Exit(1)
+		return exitFailure
+	}
+
+	// Record the session in the writing history
+	if fm, ok := final.(model); ok {
+		fm.treeWatcher.close()
+		if fm.sprint != nil {
+			fm.finishSprint(true)
+		}
+		fm.endSession()
 	}
+	return exitOK
 }
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// What a held left button is dragging
+const (
+	mouseDragNone    = iota
+	mouseDragSelect  // extending a text selection
+	mouseDragDivider // resizing the file tree
+)
+
+// mouseMultiClickDelay is the longest gap between the clicks of a double or triple click
+const mouseMultiClickDelay = 400 * time.Millisecond
+
+// mouseWheelLines is how far one wheel step scrolls
+const mouseWheelLines = 3
+
+// Screen areas the mouse can be over
+const (
+	mouseAreaNone = iota // panes and the status bar
+	mouseAreaTree
+	mouseAreaDivider
+	mouseAreaEditor
+)
+
+// mouseArea returns the part of the screen at a position
+func (m model) mouseArea(x, y int) int {
+	if y < 0 || y >= m.editorHeight() {
+		return mouseAreaNone
+	}
+	if m.zenMode {
+		return mouseAreaEditor // the margins belong to the text
+	}
+	left := 0
+	if m.fileTreeVisible {
+		treeWidth := m.fileTreeWidth()
+		if x < treeWidth {
+			return mouseAreaTree
+		}
+		if x == treeWidth {
+			return mouseAreaDivider
+		}
+		left = treeWidth + 1
+	}
+	if x < left+m.editorWidth() {
+		return mouseAreaEditor
+	}
+	return mouseAreaNone // the statistics panel
+}
+
+// editorOrigin returns the screen column and row where the document's first
+// visible line is drawn
+func (m model) editorOrigin() (int, int) {
+	if m.zenMode {
+		return (m.width - m.editorWidth()) / 2, m.typewriterPadding()
+	}
+	if m.fileTreeVisible {
+		return m.fileTreeWidth() + 1, 0 // +1 for the divider
+	}
+	return 0, 0
+}
+
+// documentPosition converts a screen position over the editor into a line and
+// byte offset in the document. Positions past the end of a line or of the
+// document land on the nearest end.
+func (m *model) documentPosition(x, y int) (int, int) {
+	left, top := m.editorOrigin()
+	row := max(0, y-top)
+	column := max(0, x-left)
+
+	wrappedIdx := m.offsetY + row
+	count := 0
+	for lineIdx := 0; lineIdx < len(m.lines); lineIdx++ {
+		segments := m.getWrappedLine(lineIdx)
+		if wrappedIdx < count+len(segments) {
+			segment := segments[wrappedIdx-count]
+			pos := segment.startX + byteOffsetForColumn(segment.text, column)
+			return lineIdx, min(pos, len(m.lines[lineIdx]))
+		}
+		count += len(segments)
+	}
+	last := len(m.lines) - 1
+	return last, len(m.lines[last])
+}
+
+// byteOffsetForColumn returns the byte offset of the character drawn at a screen column
+func byteOffsetForColumn(text string, column int) int {
+	for i := range text {
+		if column == 0 {
+			return i
+		}
+		column--
+	}
+	return len(text)
+}
+
+// wrappedLineCount returns how many screen rows the whole document wraps to
+func (m *model) wrappedLineCount() int {
+	count := 0
+	for lineIdx := range m.lines {
+		count += len(m.getWrappedLine(lineIdx))
+	}
+	return count
+}
+
+// handleMouse processes mouse events: clicks and drags in the text and the
+// tree, the wheel, and dragging the divider to resize the tree
+func (m model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// Panes that take the keyboard take the mouse out of play too
+	if m.commandMode || m.paletteVisible || m.finderVisible {
+		return m, nil
+	}
+	m.lastEdit = "" // clicking elsewhere ends a run of typing
+
+	if msg.Action == tea.MouseActionRelease {
+		if m.mouseDrag == mouseDragDivider {
+			m.setStatus(fmt.Sprintf("Tree width %d", m.config.TreeWidth), "green")
+		}
+		m.mouseDrag = mouseDragNone
+		return m, nil
+	}
+
+	if msg.Action == tea.MouseActionMotion {
+		switch m.mouseDrag {
+		case mouseDragDivider:
+			m.setFileTreeWidth(msg.X)
+		case mouseDragSelect:
+			y, x := m.documentPosition(msg.X, msg.Y)
+			m.cursorY, m.cursorX = y, x
+			m.selectionActive = y != m.selectionStartY || x != m.selectionStartX
+			m.adjustViewport()
+		}
+		return m, nil
+	}
+
+	area := m.mouseArea(msg.X, msg.Y)
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		delta := mouseWheelLines
+		if msg.Button == tea.MouseButtonWheelUp {
+			delta = -delta
+		}
+		if area == mouseAreaTree {
+			m.scrollFileTree(delta)
+		} else if area == mouseAreaEditor {
+			m.scrollEditor(delta)
+		}
+		return m, nil
+
+	case tea.MouseButtonLeft:
+		clicks := m.countClick(msg.X, msg.Y)
+		switch area {
+		case mouseAreaDivider:
+			m.mouseDrag = mouseDragDivider
+		case mouseAreaTree:
+			return m.clickFileTree(msg.Y, clicks)
+		case mouseAreaEditor:
+			m.clickEditor(msg.X, msg.Y, clicks)
+		}
+	}
+	return m, nil
+}
+
+// countClick returns 1, 2 or 3 for a single, double or triple click at a position
+func (m *model) countClick(x, y int) int {
+	now := time.Now()
+	if x == m.lastClickX && y == m.lastClickY && now.Sub(m.lastClickTime) <= mouseMultiClickDelay && m.clickCount < 3 {
+		m.clickCount++
+	} else {
+		m.clickCount = 1
+	}
+	m.lastClickTime, m.lastClickX, m.lastClickY = now, x, y
+	return m.clickCount
+}
+
+// clickEditor moves the cursor to a click and starts a selection drag. A double
+// click selects the word there and a triple click the paragraph.
+func (m *model) clickEditor(x, y int, clicks int) {
+	m.fileTreeFocused = false
+	lineY, lineX := m.documentPosition(x, y)
+	m.cursorY, m.cursorX = lineY, lineX
+	m.selectionActive = false
+	m.selectionStartY, m.selectionStartX = lineY, lineX
+	m.mouseDrag = mouseDragSelect
+
+	switch clicks {
+	case 2:
+		for _, w := range getWordsInLine(m.lines[lineY]) {
+			if lineX >= w.start && lineX <= w.end {
+				m.selectionStartX, m.cursorX = w.start, w.end
+				m.selectionActive = true
+				break
+			}
+		}
+		m.mouseDrag = mouseDragNone
+	case 3:
+		start, end := m.paragraphBounds(lineY)
+		m.selectionStartY, m.selectionStartX = start, 0
+		m.cursorY, m.cursorX = end, len(m.lines[end])
+		m.selectionActive = true
+		m.mouseDrag = mouseDragNone
+	}
+}
+
+// clickFileTree selects the entry at a screen row. A click expands or collapses
+// a folder; a double click opens a file. The filter row opens the filter box.
+func (m model) clickFileTree(y int, clicks int) (tea.Model, tea.Cmd) {
+	m.fileTreeFocused = true
+	row := y
+	if m.treeFilterShown() {
+		if row == 0 {
+			m.openTreeFilter()
+			return m, nil
+		}
+		row--
+	}
+	m.treeFilterTyping = false
+
+	idx := m.fileTreeOffset + row
+	nodes := m.visibleTreeNodes()
+	if idx >= len(nodes) {
+		return m, nil
+	}
+	m.fileTreeCursor = idx
+	// The second click of a double click on a folder would fold it straight back
+	if nodes[idx].IsDir && clicks == 1 || !nodes[idx].IsDir && clicks == 2 {
+		return m.openTreeSelection()
+	}
+	return m, nil
+}
+
+// scrollFileTree scrolls the tree without moving its selection
+func (m *model) scrollFileTree(delta int) {
+	limit := max(0, len(m.visibleTreeNodes())-m.treeListHeight())
+	m.fileTreeOffset = max(0, min(m.fileTreeOffset+delta, limit))
+}
+
+// scrollEditor scrolls the document without moving the cursor
+func (m *model) scrollEditor(delta int) {
+	limit := max(0, m.wrappedLineCount()-m.editorHeight())
+	m.offsetY = max(0, min(m.offsetY+delta, limit))
+}
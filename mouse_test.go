@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mouse feeds one mouse event to the model
+func mouse(t *testing.T, m model, button tea.MouseButton, action tea.MouseAction, x, y int) model {
+	t.Helper()
+	result, cmd := m.Update(tea.MouseMsg{X: x, Y: y, Button: button, Action: action})
+	m = result.(model)
+	if cmd != nil {
+		if msg, ok := cmd().(treeDirLoadedMsg); ok {
+			result, _ = m.Update(msg)
+			m = result.(model)
+		}
+	}
+	return m
+}
+
+// click presses and releases the left button
+func click(t *testing.T, m model, x, y int) model {
+	t.Helper()
+	m = mouse(t, m, tea.MouseButtonLeft, tea.MouseActionPress, x, y)
+	return mouse(t, m, tea.MouseButtonNone, tea.MouseActionRelease, x, y)
+}
+
+func TestMouseClickPlacesCursor(t *testing.T) {
+	m := newKeymapTestModel(t, defaultKeymapPreset)
+	m.lines = []string{"first line", "second line", "third line"}
+
+	// Without the tree the text starts in the top-left corner, in any mode
+	m = click(t, m, 3, 1)
+	if m.cursorY != 1 || m.cursorX != 3 {
+		t.Errorf("cursor = %d,%d, want 1,3", m.cursorY, m.cursorX)
+	}
+	if m.selectionActive {
+		t.Error("a click should not select anything")
+	}
+
+	// With the tree shown the text starts after it and the divider
+	m.fileTreeVisible = true
+	m.fileTreeFocused = true
+	left := m.fileTreeWidth() + 1
+	m = click(t, m, left+6, 2)
+	if m.cursorY != 2 || m.cursorX != 6 || m.fileTreeFocused {
+		t.Errorf("cursor = %d,%d focused %v, want 2,6 in the editor", m.cursorY, m.cursorX, m.fileTreeFocused)
+	}
+
+	// Past the end of a line or of the document lands on the end
+	m = click(t, m, left+40, 15)
+	if m.cursorY != 2 || m.cursorX != len("third line") {
+		t.Errorf("cursor = %d,%d", m.cursorY, m.cursorX)
+	}
+
+	// Status bar rows don't move the cursor
+	m = click(t, m, left+1, m.height-1)
+	if m.cursorY != 2 {
+		t.Error("a click on the status bar moved the cursor")
+	}
+}
+
+func TestMouseClickInZenMode(t *testing.T) {
+	m := newKeymapTestModel(t, defaultKeymapPreset)
+	m.lines = []string{"first line", "second line"}
+	m.zenMode = true
+	m.config.ZenTypewriter = false
+	left, _ := m.editorOrigin()
+	if left == 0 {
+		t.Fatal("zen mode should center the text")
+	}
+	m = click(t, m, left+2, 1)
+	if m.cursorY != 1 || m.cursorX != 2 {
+		t.Errorf("cursor = %d,%d, want 1,2", m.cursorY, m.cursorX)
+	}
+}
+
+func TestMouseDragAndMultiClick(t *testing.T) {
+	m := newKeymapTestModel(t, defaultKeymapPreset)
+	m.lines = []string{"The quick brown fox", "jumps over", "", "Next paragraph"}
+
+	m = mouse(t, m, tea.MouseButtonLeft, tea.MouseActionPress, 4, 0)
+	m = mouse(t, m, tea.MouseButtonLeft, tea.MouseActionMotion, 5, 1)
+	m = mouse(t, m, tea.MouseButtonNone, tea.MouseActionRelease, 5, 1)
+	if got := m.selectedText(); got != "quick brown fox\njumps" {
+		t.Errorf("dragged selection = %q", got)
+	}
+
+	m = click(t, m, 11, 0)
+	m = click(t, m, 11, 0)
+	if got := m.selectedText(); got != "brown" {
+		t.Errorf("double click selected %q", got)
+	}
+	m = click(t, m, 11, 0)
+	if got := m.selectedText(); got != "The quick brown fox\njumps over" {
+		t.Errorf("triple click selected %q", got)
+	}
+}
+
+func TestMouseWheel(t *testing.T) {
+	m := newKeymapTestModel(t, defaultKeymapPreset)
+	m.lines = nil
+	for i := 0; i < 100; i++ {
+		m.lines = append(m.lines, fmt.Sprintf("line %d", i))
+	}
+	m = mouse(t, m, tea.MouseButtonWheelDown, tea.MouseActionPress, 5, 5)
+	if m.offsetY != mouseWheelLines || m.cursorY != 0 {
+		t.Errorf("offset %d cursor %d after scrolling down", m.offsetY, m.cursorY)
+	}
+	m = mouse(t, m, tea.MouseButtonWheelUp, tea.MouseActionPress, 5, 5)
+	m = mouse(t, m, tea.MouseButtonWheelUp, tea.MouseActionPress, 5, 5)
+	if m.offsetY != 0 {
+		t.Errorf("offset %d after scrolling back up", m.offsetY)
+	}
+	for i := 0; i < 50; i++ {
+		m = mouse(t, m, tea.MouseButtonWheelDown, tea.MouseActionPress, 5, 5)
+	}
+	if want := 100 - m.editorHeight(); m.offsetY != want {
+		t.Errorf("offset %d, want it to stop at %d", m.offsetY, want)
+	}
+}
+
+func TestMouseFileTree(t *testing.T) {
+	m, root := newFileOpsTestModel(t)
+	m.width, m.height = 100, 24
+	m.fileTreeFocused = false
+
+	// Clicking a folder selects and expands it
+	m = click(t, m, 2, 0)
+	if !m.fileTreeFocused || m.fileTreeCursor != 0 {
+		t.Fatalf("focused %v cursor %d", m.fileTreeFocused, m.fileTreeCursor)
+	}
+	if !findTreeNode(m.fileTreeNodes, filepath.Join(root, "chapters")).Expanded {
+		t.Fatal("clicking a folder should expand it")
+	}
+
+	// A double click on a file opens it
+	m = click(t, m, 4, 1)
+	m = click(t, m, 4, 1)
+	if want := filepath.Join(root, "chapters", "one.md"); m.filename != want {
+		t.Errorf("filename = %q, want %q", m.filename, want)
+	}
+	if m.fileTreeVisible {
+		t.Error("opening a file hides the tree")
+	}
+
+	// Dragging the divider resizes the tree
+	m.fileTreeVisible = true
+	divider := m.fileTreeWidth()
+	m = mouse(t, m, tea.MouseButtonLeft, tea.MouseActionPress, divider, 3)
+	m = mouse(t, m, tea.MouseButtonLeft, tea.MouseActionMotion, 40, 3)
+	m = mouse(t, m, tea.MouseButtonNone, tea.MouseActionRelease, 40, 3)
+	if m.config.TreeWidth != 40 {
+		t.Errorf("tree width = %d after dragging", m.config.TreeWidth)
+	}
+}
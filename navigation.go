@@ -1,90 +1,204 @@
 package main
 
-import tea "github.com/charmbracelet/bubbletea"
-
-// handleReadMode processes keys in read mode (navigation only)
-func (m model) handleReadMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "up", "k":
-		// Navigate by wrapped lines, not source lines
-		currentWrappedIdx := m.getWrappedLineIndexForCursor()
-		if currentWrappedIdx > 0 {
-			m.moveToWrappedLine(currentWrappedIdx - 1)
-			m.adjustViewport()
-		}
+import "strings"
 
-	case "down", "j":
-		// Navigate by wrapped lines, not source lines
-		currentWrappedIdx := m.getWrappedLineIndexForCursor()
-		m.moveToWrappedLine(currentWrappedIdx + 1)
-		m.adjustViewport()
+// getCurrentLine returns the current line content
+func (m model) getCurrentLine() string {
+	if m.cursorY >= 0 && m.cursorY < len(m.lines) {
+		return m.lines[m.cursorY]
+	}
+	return ""
+}
 
-	case "left", "h":
-		if m.cursorX > 0 {
-			m.cursorX--
+// wordAtCursor returns the word under (or immediately before) the cursor
+func (m model) wordAtCursor() (wordPos, bool) {
+	for _, w := range getWordsInLine(m.getCurrentLine()) {
+		if m.cursorX >= w.start && m.cursorX <= w.end {
+			return w, true
 		}
+	}
+	return wordPos{}, false
+}
 
-	case "right", "l":
-		if m.cursorX < len(m.getCurrentLine()) {
-			m.cursorX++
-		}
+// clampCursorX keeps the cursor within the current line
+func (m *model) clampCursorX() {
+	if lineLen := len(m.getCurrentLine()); m.cursorX > lineLen {
+		m.cursorX = lineLen
+	}
+}
 
-	case "pgup":
-		m.cursorY -= (m.height - 4) // Leave room for status bar
-		if m.cursorY < 0 {
-			m.cursorY = 0
-		}
-		m.adjustViewport()
-
-	case "pgdown":
-		m.cursorY += (m.height - 4)
-		if m.cursorY >= len(m.lines) {
-			m.cursorY = len(m.lines) - 1
-		}
+// moveCursorUp moves up one wrapped line (not source line)
+func (m *model) moveCursorUp() {
+	currentWrappedIdx := m.getWrappedLineIndexForCursor()
+	if currentWrappedIdx > 0 {
+		m.moveToWrappedLine(currentWrappedIdx - 1)
+		m.clampCursorX()
 		m.adjustViewport()
+	}
+}
 
-	case "ctrl+home", "g":
-		if msg.String() == "g" {
-			// Simple implementation: single 'g' goes to top
-			m.cursorY = 0
-			m.cursorX = 0
-			m.adjustViewport()
-		} else {
-			m.cursorY = 0
-			m.cursorX = 0
-			m.adjustViewport()
-		}
+// moveCursorDown moves down one wrapped line (not source line)
+func (m *model) moveCursorDown() {
+	currentWrappedIdx := m.getWrappedLineIndexForCursor()
+	m.moveToWrappedLine(currentWrappedIdx + 1)
+	m.clampCursorX()
+	m.adjustViewport()
+}
 
-	case "ctrl+end", "G":
-		m.cursorY = len(m.lines) - 1
+// moveCursorLeft moves one character left, wrapping to the end of the previous line
+func (m *model) moveCursorLeft() {
+	if m.cursorX > 0 {
+		m.cursorX--
+	} else if m.cursorY > 0 {
+		m.cursorY--
 		m.cursorX = len(m.getCurrentLine())
 		m.adjustViewport()
+	}
+}
 
-	case "home":
+// moveCursorRight moves one character right, wrapping to the start of the next line
+func (m *model) moveCursorRight() {
+	if m.cursorX < len(m.getCurrentLine()) {
+		m.cursorX++
+	} else if m.cursorY < len(m.lines)-1 {
+		m.cursorY++
 		m.cursorX = 0
+		m.adjustViewport()
+	}
+}
 
-	case "end":
-		m.cursorX = len(m.getCurrentLine())
+// moveToLineStart moves to the start of the current line
+func (m *model) moveToLineStart() {
+	m.cursorX = 0
+}
+
+// moveToLineEnd moves to the end of the current line
+func (m *model) moveToLineEnd() {
+	m.cursorX = len(m.getCurrentLine())
+}
+
+// moveToDocumentStart moves to the first character of the document
+func (m *model) moveToDocumentStart() {
+	m.cursorY = 0
+	m.cursorX = 0
+	m.adjustViewport()
+}
+
+// moveToDocumentEnd moves to the last character of the document
+func (m *model) moveToDocumentEnd() {
+	m.cursorY = len(m.lines) - 1
+	m.cursorX = len(m.getCurrentLine())
+	m.adjustViewport()
+}
+
+// moveParagraphForward moves to the end of the paragraph, or to the end of the
+// next one when already there
+func (m *model) moveParagraphForward() {
+	y := m.cursorY
+	if _, end := m.paragraphBounds(y); y == end && m.cursorX == len(m.lines[y]) || strings.TrimSpace(m.lines[y]) == "" {
+		// Skip to the next paragraph
+		y++
+		for y < len(m.lines) && strings.TrimSpace(m.lines[y]) == "" {
+			y++
+		}
+		if y == len(m.lines) {
+			m.moveToDocumentEnd()
+			return
+		}
 	}
+	_, end := m.paragraphBounds(y)
+	m.cursorY, m.cursorX = end, len(m.lines[end])
+	m.adjustViewport()
+}
 
-	return m, nil
+// moveParagraphBackward moves to the start of the paragraph, or to the start
+// of the previous one when already there
+func (m *model) moveParagraphBackward() {
+	y := m.cursorY
+	if start, _ := m.paragraphBounds(y); y == start && m.cursorX == 0 || strings.TrimSpace(m.lines[y]) == "" {
+		// Skip to the previous paragraph
+		y--
+		for y >= 0 && strings.TrimSpace(m.lines[y]) == "" {
+			y--
+		}
+		if y < 0 {
+			m.moveToDocumentStart()
+			return
+		}
+	}
+	start, _ := m.paragraphBounds(y)
+	m.cursorY, m.cursorX = start, 0
+	m.adjustViewport()
 }
 
-// getCurrentLine returns the current line content
-func (m model) getCurrentLine() string {
-	if m.cursorY >= 0 && m.cursorY < len(m.lines) {
-		return m.lines[m.cursorY]
+// movePage moves a screenful up (direction -1) or down (direction 1)
+func (m *model) movePage(direction int) {
+	m.cursorY += direction * m.editorHeight()
+	if m.cursorY < 0 {
+		m.cursorY = 0
+	}
+	if m.cursorY >= len(m.lines) {
+		m.cursorY = len(m.lines) - 1
+	}
+	m.clampCursorX()
+	m.adjustViewport()
+}
+
+// moveWordForward moves to the start of the next word, continuing onto following lines
+func (m *model) moveWordForward() {
+	for {
+		for _, w := range getWordsInLine(m.getCurrentLine()) {
+			if w.start > m.cursorX {
+				m.cursorX = w.start
+				m.adjustViewport()
+				return
+			}
+		}
+		if m.cursorY >= len(m.lines)-1 {
+			m.moveToLineEnd()
+			return
+		}
+		m.cursorY++
+		m.cursorX = -1 // any word start on the next line qualifies
+	}
+}
+
+// moveWordBackward moves to the start of the previous word, continuing onto earlier lines
+func (m *model) moveWordBackward() {
+	for {
+		words := getWordsInLine(m.getCurrentLine())
+		for i := len(words) - 1; i >= 0; i-- {
+			if words[i].start < m.cursorX {
+				m.cursorX = words[i].start
+				m.adjustViewport()
+				return
+			}
+		}
+		if m.cursorY == 0 {
+			m.cursorX = 0
+			return
+		}
+		m.cursorY--
+		m.cursorX = len(m.getCurrentLine()) + 1
 	}
-	return ""
 }
 
 // adjustViewport ensures cursor is visible
 func (m *model) adjustViewport() {
-	visibleHeight := m.height - 2
+	visibleHeight := m.editorHeight()
 
 	// Find the wrapped line index where cursor is located
 	cursorWrappedIdx := m.getWrappedLineIndexForCursor()
 
+	// Typewriter scrolling keeps the cursor line in the middle of the screen
+	if m.zenMode && m.config.ZenTypewriter {
+		m.offsetY = cursorWrappedIdx - visibleHeight/2
+		if m.offsetY < 0 {
+			m.offsetY = 0 // typewriterPadding fills the space above
+		}
+		return
+	}
+
 	// Scroll down if cursor is below viewport
 	if cursorWrappedIdx >= m.offsetY+visibleHeight {
 		m.offsetY = cursorWrappedIdx - visibleHeight + 1
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// paletteRecentFile remembers recently run palette entries, in the config directory
+const paletteRecentFile = "palette_recent"
+
+// paletteRecentLimit is how many recent entries are remembered
+const paletteRecentLimit = 20
+
+// paletteRows is the most entries shown at once
+const paletteRows = 10
+
+// paletteItem is an action or command offered by the palette
+type paletteItem struct {
+	id          string // action name, or ":command"
+	description string
+	keys        string // current bindings, for display
+	positions   []int  // matched byte offsets in id, for highlighting
+}
+
+// paletteEntries lists every command and action with its current bindings
+func (m model) paletteEntries() []paletteItem {
+	var items []paletteItem
+	for _, c := range commandList {
+		id := ":" + c.name
+		if c.usage != "" {
+			id += " " + c.usage
+		}
+		items = append(items, paletteItem{id: id, description: c.description})
+	}
+	for _, name := range keyActionNames() {
+		items = append(items, paletteItem{
+			id:          name,
+			description: keyActions[name].description,
+			keys:        m.activeKeymap().bindingsFor(name, 2),
+		})
+	}
+	return items
+}
+
+// bindingsFor lists up to limit key sequences bound to an action, global ones first
+func (k *Keymap) bindingsFor(action string, limit int) string {
+	var found []string
+	for _, mode := range []string{keymapGlobal, keymapRead, keymapEdit, keymapTree, keymapCommand} {
+		var sequences []string
+		for seq, act := range k.bindings[mode] {
+			if act == action {
+				sequences = append(sequences, formatKeySequence(strings.Split(seq, keySeparator)))
+			}
+		}
+		sort.Slice(sequences, func(i, j int) bool {
+			if len(sequences[i]) != len(sequences[j]) {
+				return len(sequences[i]) < len(sequences[j])
+			}
+			return sequences[i] < sequences[j]
+		})
+		for _, seq := range sequences {
+			if len(found) < limit && !containsString(found, seq) {
+				found = append(found, seq)
+			}
+		}
+	}
+	return strings.Join(found, ", ")
+}
+
+// fuzzyMatch reports whether every rune of query appears in text in order
+// (ignoring case) and scores the match: consecutive runes and runes at the
+// start of a word score higher, gaps score lower. The matched byte offsets
+// are returned for highlighting.
+func fuzzyMatch(query string, text string) (int, []int, bool) {
+	if query == "" {
+		return 0, nil, true
+	}
+	q := []rune(strings.ToLower(query))
+	score := 0
+	qi := 0
+	last := -1
+	var positions []int
+	prev := rune(0)
+	for i, r := range text {
+		if qi < len(q) && unicode.ToLower(r) == q[qi] {
+			score += 1
+			switch {
+			case last >= 0 && i == last+utf8.RuneLen(prev):
+				score += 5 // consecutive
+			case i == 0 || strings.ContainsRune(" .:_-/", prev):
+				score += 4 // start of a word
+			case last >= 0:
+				score -= min(i-last, 3) // gap
+			}
+			positions = append(positions, i)
+			last = i
+			qi++
+		}
+		prev = r
+	}
+	if qi < len(q) {
+		return 0, nil, false
+	}
+	return score, positions, true
+}
+
+// filterPalette ranks entries for a query. Without a query, recent entries come first.
+func filterPalette(entries []paletteItem, query string, recent []string) []paletteItem {
+	recency := make(map[string]int)
+	for i, id := range recent {
+		recency[id] = i + 1 // newest entries are last, so they rank highest
+	}
+
+	type scored struct {
+		item  paletteItem
+		score int
+	}
+	var matches []scored
+	for _, item := range entries {
+		key := paletteKey(item.id)
+		score, positions, ok := fuzzyMatch(query, item.id)
+		if !ok {
+			// Descriptions match too, ranked below names
+			descScore, _, descOK := fuzzyMatch(query, item.description)
+			if !descOK || query == "" {
+				continue
+			}
+			score = descScore / 2
+		}
+		item.positions = positions
+		if r, ok := recency[key]; ok {
+			if query == "" {
+				score = 1000 + r
+			} else {
+				score += 3
+			}
+		}
+		matches = append(matches, scored{item, score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	items := make([]paletteItem, len(matches))
+	for i, s := range matches {
+		items[i] = s.item
+	}
+	return items
+}
+
+// paletteKey is the name recorded in the recent list (":theme", not ":theme [name]")
+func paletteKey(id string) string {
+	name, _, _ := strings.Cut(id, " ")
+	return name
+}
+
+// paletteRecentPath returns where recent palette entries are stored
+func paletteRecentPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, paletteRecentFile), nil
+}
+
+// loadPaletteRecent reads recent entries, oldest first
+func loadPaletteRecent() []string {
+	path, err := paletteRecentPath()
+	if err != nil {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var recent []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			recent = append(recent, line)
+		}
+	}
+	return recent
+}
+
+// rememberPaletteEntry moves an entry to the newest position and saves the list
+func (m *model) rememberPaletteEntry(key string) {
+	recent := make([]string, 0, len(m.paletteRecent)+1)
+	for _, id := range m.paletteRecent {
+		if id != key {
+			recent = append(recent, id)
+		}
+	}
+	recent = append(recent, key)
+	if len(recent) > paletteRecentLimit {
+		recent = recent[len(recent)-paletteRecentLimit:]
+	}
+	m.paletteRecent = recent
+
+	path, err := paletteRecentPath()
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(path, []byte(strings.Join(recent, "\n")+"\n"), 0644)
+	}
+	if err != nil {
+		LogWarningf("Failed to save recent palette entries: %v", err)
+	}
+}
+
+// openPalette shows the command palette
+func (m *model) openPalette() {
+	if !m.paletteRecentLoaded {
+		m.paletteRecent = loadPaletteRecent()
+		m.paletteRecentLoaded = true
+	}
+	m.paletteVisible = true
+	m.paletteQuery = ""
+	m.refreshPalette()
+	m.adjustViewport()
+}
+
+// closePalette hides the command palette
+func (m *model) closePalette() {
+	m.paletteVisible = false
+	m.paletteItems = nil
+	m.adjustViewport()
+}
+
+// refreshPalette re-filters the entries after the query changes
+func (m *model) refreshPalette() {
+	m.paletteItems = filterPalette(m.paletteEntries(), m.paletteQuery, m.paletteRecent)
+	m.paletteCursor = 0
+	m.paletteOffset = 0
+}
+
+// movePaletteCursor moves the selection, scrolling to keep it visible
+func (m *model) movePaletteCursor(step int) {
+	if len(m.paletteItems) == 0 {
+		return
+	}
+	m.paletteCursor = max(0, min(len(m.paletteItems)-1, m.paletteCursor+step))
+	rows := m.paletteListRows()
+	if m.paletteCursor < m.paletteOffset {
+		m.paletteOffset = m.paletteCursor
+	}
+	if m.paletteCursor >= m.paletteOffset+rows {
+		m.paletteOffset = m.paletteCursor - rows + 1
+	}
+}
+
+// handlePaletteKeys edits the query, moves the selection and runs the chosen entry
+func (m model) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c", "ctrl+g":
+		m.closePalette()
+	case "enter":
+		return m.runPaletteSelection()
+	case "up", "ctrl+p", "ctrl+k", "shift+tab":
+		m.movePaletteCursor(-1)
+	case "down", "ctrl+n", "ctrl+j", "tab":
+		m.movePaletteCursor(1)
+	case "pgup":
+		m.movePaletteCursor(-m.paletteListRows())
+	case "pgdown":
+		m.movePaletteCursor(m.paletteListRows())
+	case "backspace":
+		if m.paletteQuery != "" {
+			_, size := utf8.DecodeLastRuneInString(m.paletteQuery)
+			m.paletteQuery = m.paletteQuery[:len(m.paletteQuery)-size]
+			m.refreshPalette()
+		}
+	case "ctrl+u":
+		m.paletteQuery = ""
+		m.refreshPalette()
+	case "ctrl+w":
+		m.paletteQuery = strings.TrimRight(m.paletteQuery, " ")
+		if i := strings.LastIndexAny(m.paletteQuery, " .:_"); i >= 0 {
+			m.paletteQuery = m.paletteQuery[:i]
+		} else {
+			m.paletteQuery = ""
+		}
+		m.refreshPalette()
+	default:
+		if msg.Type == tea.KeyRunes && !msg.Alt || msg.Type == tea.KeySpace {
+			m.paletteQuery += string(msg.Runes)
+			m.refreshPalette()
+		}
+	}
+	return m, nil
+}
+
+// runPaletteSelection closes the palette and runs the selected entry.
+// Commands that need arguments open the command line ready for them.
+func (m model) runPaletteSelection() (tea.Model, tea.Cmd) {
+	if len(m.paletteItems) == 0 {
+		return m, nil
+	}
+	item := m.paletteItems[m.paletteCursor]
+	key := paletteKey(item.id)
+	m.closePalette()
+	m.rememberPaletteEntry(key)
+
+	if !strings.HasPrefix(key, ":") {
+		return m.runAction(key)
+	}
+	if strings.Contains(item.id, "<") {
+		m.openCommandLine()
+		m.setCommandText(strings.TrimPrefix(key, ":") + " ")
+		return m, nil
+	}
+	m.addCommandHistory(strings.TrimPrefix(key, ":"))
+	return m.executeCommand(key)
+}
+
+// paletteListRows returns how many entries fit in the palette
+func (m model) paletteListRows() int {
+	return max(1, min(paletteRows, m.height/2-1))
+}
+
+// palettePaneHeight returns the rows taken by the palette (0 when hidden)
+func (m model) palettePaneHeight() int {
+	if !m.paletteVisible {
+		return 0
+	}
+	return m.paletteListRows() + 1 // +1 for the query row
+}
+
+// renderPalette draws the query row and the matching entries above the status bar
+func (m model) renderPalette() string {
+	titleStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.title.bg")).
+		Foreground(m.themeColor("pane.title.fg")).
+		Bold(monochrome())
+	rowStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.bg")).
+		Foreground(m.themeColor("pane.fg"))
+	selectedStyle := rowStyle.
+		Background(m.themeColor("pane.selected.bg")).
+		Foreground(m.themeColor("pane.selected.fg")).
+		Reverse(monochrome())
+
+	// Query row, with the match count on the right
+	prompt := " > " + m.paletteQuery + "▏"
+	count := formatCount(len(m.paletteItems)) + " matches | ↑↓ enter esc "
+	gap := max(1, m.width-lipgloss.Width(prompt)-lipgloss.Width(count))
+	rows := []string{titleStyle.Render(truncateRunes(prompt+strings.Repeat(" ", gap)+count, m.width))}
+
+	idWidth := 0
+	for _, item := range m.paletteItems {
+		idWidth = max(idWidth, utf8.RuneCountInString(item.id))
+	}
+	idWidth = min(idWidth, m.width/2)
+
+	for i := 0; i < m.paletteListRows(); i++ {
+		idx := m.paletteOffset + i
+		style := rowStyle
+		if idx == m.paletteCursor {
+			style = selectedStyle
+		}
+		if idx >= len(m.paletteItems) {
+			rows = append(rows, rowStyle.Width(m.width).Render(""))
+			continue
+		}
+		item := m.paletteItems[idx]
+
+		id := truncateRunes(item.id, idWidth)
+		var b strings.Builder
+		b.WriteString(style.Render(" "))
+		b.WriteString(renderMatched(id, item.positions, style))
+		text := strings.Repeat(" ", idWidth-utf8.RuneCountInString(id)+2) + item.description
+		right := item.keys
+		if right != "" {
+			right += " "
+		}
+		room := m.width - 1 - idWidth - lipgloss.Width(right)
+		text = truncateRunes(text, max(0, room))
+		pad := max(0, room-lipgloss.Width(text))
+		b.WriteString(style.Render(text + strings.Repeat(" ", pad) + right))
+		rows = append(rows, b.String())
+	}
+	return strings.Join(rows, "\n")
+}
+
+// renderMatched draws text with the fuzzy-matched characters emphasised.
+// Pieces are rendered separately so every one keeps the row background.
+func renderMatched(text string, positions []int, style lipgloss.Style) string {
+	matched := style.Bold(true).Underline(true)
+	var b strings.Builder
+	for pos, r := range text {
+		if containsInt(positions, pos) {
+			b.WriteString(matched.Render(string(r)))
+		} else {
+			b.WriteString(style.Render(string(r)))
+		}
+	}
+	return b.String()
+}
+
+// containsInt reports whether list contains n
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
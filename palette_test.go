@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	if _, _, ok := fuzzyMatch("zt", "zen.toggle"); !ok {
+		t.Error("zt should match zen.toggle")
+	}
+	if _, _, ok := fuzzyMatch("tz", "zen.toggle"); ok {
+		t.Error("letters must match in order")
+	}
+	_, positions, _ := fuzzyMatch("SAVE", "file.save")
+	if !reflect.DeepEqual(positions, []int{5, 6, 7, 8}) {
+		t.Errorf("positions = %v", positions)
+	}
+
+	// Consecutive letters and word starts beat scattered ones
+	word, _, _ := fuzzyMatch("save", "file.save")
+	scattered, _, _ := fuzzyMatch("save", "select.above")
+	if word <= scattered {
+		t.Errorf("file.save scored %d, select.above %d", word, scattered)
+	}
+}
+
+func TestFilterPalette(t *testing.T) {
+	entries := []paletteItem{
+		{id: "file.save", description: "Save the file"},
+		{id: "zen.toggle", description: "Enter or leave zen mode"},
+		{id: ":stats", description: "Show document statistics"},
+		{id: ":theme [name]", description: "Switch colour theme"},
+	}
+	ids := func(items []paletteItem) []string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item.id)
+		}
+		return out
+	}
+
+	got := ids(filterPalette(entries, "", []string{":theme", "zen.toggle"}))
+	want := []string{"zen.toggle", ":theme [name]", "file.save", ":stats"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("recent first = %q, want %q", got, want)
+	}
+
+	if got := ids(filterPalette(entries, "zen", nil)); !reflect.DeepEqual(got, []string{"zen.toggle"}) {
+		t.Errorf("zen = %q", got)
+	}
+	// Descriptions match too
+	if got := ids(filterPalette(entries, "statistics", nil)); !reflect.DeepEqual(got, []string{":stats"}) {
+		t.Errorf("statistics = %q", got)
+	}
+}
+
+func TestPaletteRunsSelection(t *testing.T) {
+	m := newCommandLineTestModel(t)
+	m = pressSpecial(t, m, tea.KeyEsc)
+	m = pressSpecial(t, m, tea.KeyCtrlP)
+	if !m.paletteVisible || m.palettePaneHeight() == 0 {
+		t.Fatal("ctrl+p should open the palette")
+	}
+	for _, item := range m.paletteItems {
+		if item.id == "file.save" && item.keys != "ctrl+s" {
+			t.Errorf("file.save keys = %q", item.keys)
+		}
+	}
+
+	m = pressKeys(t, m, "z", "e", "n", ".", "t")
+	if len(m.paletteItems) == 0 || m.paletteItems[0].id != "zen.toggle" {
+		t.Fatalf("best match = %+v", m.paletteItems)
+	}
+	m = pressSpecial(t, m, tea.KeyEnter)
+	if m.paletteVisible || !m.zenMode {
+		t.Errorf("enter should run zen.toggle (visible %v, zen %v)", m.paletteVisible, m.zenMode)
+	}
+
+	// Commands that take arguments open the command line instead
+	m = pressSpecial(t, m, tea.KeyCtrlP)
+	m = pressKeys(t, m, ":", "g", "o", "a", "l", " ")
+	m = pressSpecial(t, m, tea.KeyEnter)
+	if !m.commandMode || m.commandBuffer != ":goal " {
+		t.Errorf("command line = %v %q", m.commandMode, m.commandBuffer)
+	}
+
+	if recent := loadPaletteRecent(); !reflect.DeepEqual(recent, []string{"zen.toggle", ":goal"}) {
+		t.Errorf("saved recent = %q", recent)
+	}
+
+	m = pressSpecial(t, m, tea.KeyEsc)
+	m = pressSpecial(t, m, tea.KeyCtrlP)
+	if m.paletteItems[0].id != ":goal [daily|file] <words|off>" {
+		t.Errorf("most recent first, got %q", m.paletteItems[0].id)
+	}
+	m = pressSpecial(t, m, tea.KeyEsc)
+	if m.paletteVisible {
+		t.Error("esc should close the palette")
+	}
+}
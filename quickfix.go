@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// quickfixHeight is the number of rows used by the quickfix pane (including its title)
+const quickfixHeight = 8
+
+// quickfixItem is a location in the document with a message
+type quickfixItem struct {
+	line int    // source line index
+	col  int    // byte offset within the line
+	text string // message shown in the list
+}
+
+// openQuickfix shows the quickfix pane with the given items and focuses it
+func (m *model) openQuickfix(title string, items []quickfixItem) {
+	m.quickfixTitle = title
+	m.quickfixItems = items
+	m.quickfixCursor = 0
+	m.quickfixOffset = 0
+	m.quickfixVisible = true
+	m.quickfixFocused = len(items) > 0
+	m.fileTreeFocused = false
+	LogEvent("QUICKFIX", fmt.Sprintf("Opened %s (%d items)", title, len(items)))
+}
+
+// closeQuickfix hides the quickfix pane
+func (m *model) closeQuickfix() {
+	m.quickfixVisible = false
+	m.quickfixFocused = false
+	m.adjustViewport()
+}
+
+// quickfixPaneHeight returns the rows taken by the quickfix pane (0 when hidden)
+func (m model) quickfixPaneHeight() int {
+	if !m.quickfixVisible {
+		return 0
+	}
+	return quickfixHeight
+}
+
+// editorHeight returns the number of rows available for document text
+func (m model) editorHeight() int {
+	height := m.height - m.statusBarHeight() - m.quickfixPaneHeight() - m.thesaurusPaneHeight() - m.infoPaneHeight() - m.completionMenuHeight() - m.palettePaneHeight() - m.finderPaneHeight() // Leave room for status bar and panes
+	if height < 1 {
+		height = 1
+	}
+	return height
+}
+
+// handleQuickfixNavigation handles keys while the quickfix pane has focus
+func (m model) handleQuickfixNavigation(key string) (tea.Model, tea.Cmd) {
+	listHeight := quickfixHeight - 1
+
+	switch key {
+	case "up", "k":
+		if m.quickfixCursor > 0 {
+			m.quickfixCursor--
+			if m.quickfixCursor < m.quickfixOffset {
+				m.quickfixOffset = m.quickfixCursor
+			}
+		}
+
+	case "down", "j":
+		if m.quickfixCursor < len(m.quickfixItems)-1 {
+			m.quickfixCursor++
+			if m.quickfixCursor >= m.quickfixOffset+listHeight {
+				m.quickfixOffset = m.quickfixCursor - listHeight + 1
+			}
+		}
+
+	case "enter":
+		// Jump to the selected location and give focus back to the editor
+		if m.quickfixCursor < len(m.quickfixItems) {
+			m.jumpToQuickfixItem(m.quickfixItems[m.quickfixCursor])
+			m.quickfixFocused = false
+		}
+
+	case "q":
+		m.closeQuickfix()
+	}
+
+	return m, nil
+}
+
+// jumpToQuickfixItem moves the cursor to an item's location
+func (m *model) jumpToQuickfixItem(item quickfixItem) {
+	if item.line < 0 || item.line >= len(m.lines) {
+		return
+	}
+	m.cursorY = item.line
+	m.cursorX = item.col
+	if m.cursorX > len(m.lines[item.line]) {
+		m.cursorX = len(m.lines[item.line])
+	}
+	m.selectionActive = false
+	m.adjustViewport()
+}
+
+// renderQuickfix renders the quickfix pane (title row plus list rows)
+func (m model) renderQuickfix() string {
+	titleStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.title.bg")).
+		Foreground(m.themeColor("pane.title.fg")).
+		Bold(monochrome()).
+		Width(m.width)
+
+	itemStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.bg")).
+		Foreground(m.themeColor("pane.fg")).
+		Width(m.width)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.selected.bg")).
+		Reverse(monochrome()).
+		Foreground(m.themeColor("pane.selected.fg")).
+		Width(m.width)
+
+	hint := "Enter: jump | q: close"
+	if !m.quickfixFocused {
+		hint = "ESC: close"
+	}
+	title := fmt.Sprintf(" %s (%d) | %s", m.quickfixTitle, len(m.quickfixItems), hint)
+
+	var rows []string
+	rows = append(rows, titleStyle.Render(truncateRunes(title, m.width)))
+
+	listHeight := quickfixHeight - 1
+	for i := 0; i < listHeight; i++ {
+		idx := m.quickfixOffset + i
+		if idx >= len(m.quickfixItems) {
+			if i == 0 && len(m.quickfixItems) == 0 {
+				rows = append(rows, itemStyle.Render(" No issues found"))
+			} else {
+				rows = append(rows, itemStyle.Render(""))
+			}
+			continue
+		}
+
+		item := m.quickfixItems[idx]
+		text := fmt.Sprintf(" %d:%d  %s", item.line+1, item.col+1, item.text)
+		text = truncateRunes(text, m.width)
+		if idx == m.quickfixCursor && m.quickfixFocused {
+			rows = append(rows, selectedStyle.Render(text))
+		} else {
+			rows = append(rows, itemStyle.Render(text))
+		}
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// truncateRunes shortens s to at most width runes, adding an ellipsis if cut
+func truncateRunes(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Clipboard modes (clipboard)
+const (
+	clipboardAuto     = "auto"     // the system clipboard tool, then OSC 52 when it fails
+	clipboardTerminal = "terminal" // OSC 52 only, e.g. over SSH
+	clipboardInternal = "internal" // the kill ring only
+)
+
+// clipboardModes lists the clipboard settings
+var clipboardModes = []string{clipboardAuto, clipboardTerminal, clipboardInternal}
+
+// killRingSize is how many copies, cuts and deleted lines the kill ring keeps
+const killRingSize = 20
+
+// The system clipboard and the terminal, replaced in tests
+var (
+	writeSystemClipboard           = clipboard.WriteAll
+	readSystemClipboard            = clipboard.ReadAll
+	terminalWriter       io.Writer = programOutput
+)
+
+// programOutput is the program's output. Escape sequences written outside the
+// renderer go through it too, so they can't land in the middle of a frame.
+var programOutput = &lockedOutput{file: os.Stdout}
+
+// lockedOutput serializes writes to a terminal. It keeps the file's Read and
+// Fd so that Bubble Tea still treats it as a terminal.
+type lockedOutput struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (o *lockedOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.file.Write(p)
+}
+
+func (o *lockedOutput) Read(p []byte) (int, error) { return o.file.Read(p) }
+func (o *lockedOutput) Close() error               { return o.file.Close() }
+func (o *lockedOutput) Fd() uintptr                { return o.file.Fd() }
+
+// writeOSC52 asks the terminal to put text on the clipboard, wrapped for tmux
+// or screen when running inside one
+func writeOSC52(text string) error {
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if os.Getenv("STY") != "" {
+		seq = seq.Screen()
+	}
+	_, err := seq.WriteTo(terminalWriter)
+	return err
+}
+
+// validRegister reports whether r names a register: a-z (A-Z append to
+// them), 0-9 for the kill ring entries, or " for the latest entry
+func validRegister(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '"'
+}
+
+// selectRegister waits for a register name that the next copy, cut or paste uses
+func (m *model) selectRegister() {
+	m.awaitingRegister = true
+	m.setStatus(`" … (register name)`, "green")
+}
+
+// handleRegisterKey takes the key typed after register.select
+func (m model) handleRegisterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.awaitingRegister = false
+	if msg.Type == tea.KeyEsc {
+		m.setStatus("", "green")
+		return m, nil
+	}
+	if len(msg.Runes) != 1 || !validRegister(msg.Runes[0]) {
+		m.setStatus("Registers are a-z, A-Z (append), 0-9 and \"", "yellow")
+		return m, nil
+	}
+	m.register = msg.Runes[0]
+	m.setStatus(fmt.Sprintf(`"%c`, m.register), "green")
+	return m, nil
+}
+
+// takeRegister returns the register chosen for this operation (0 for none) and clears it
+func (m *model) takeRegister() rune {
+	r := m.register
+	m.register = 0
+	if r == '"' {
+		return 0 // the unnamed register is the default
+	}
+	return r
+}
+
+// pushKillRing adds text to the front of the kill ring
+func (m *model) pushKillRing(text string) {
+	if text == "" {
+		return
+	}
+	m.killRing = append([]string{text}, m.killRing...)
+	if len(m.killRing) > killRingSize {
+		m.killRing = m.killRing[:killRingSize]
+	}
+}
+
+// registerText returns the contents of a register
+func (m *model) registerText(r rune) (string, bool) {
+	if r >= '0' && r <= '9' {
+		i := int(r - '0')
+		if i < len(m.killRing) {
+			return m.killRing[i], true
+		}
+		return "", false
+	}
+	text, ok := m.registers[r]
+	return text, ok
+}
+
+// storeClipboard keeps copied or cut text in the kill ring and in the chosen
+// register, or on the clipboard, and describes where it went
+func (m *model) storeClipboard(text string) (string, string) {
+	m.pushKillRing(text)
+	switch r := m.takeRegister(); {
+	case r >= 'a' && r <= 'z':
+		if m.registers == nil {
+			m.registers = make(map[rune]string)
+		}
+		m.registers[r] = text
+		return fmt.Sprintf("register %c", r), "green"
+	case r >= 'A' && r <= 'Z':
+		if m.registers == nil {
+			m.registers = make(map[rune]string)
+		}
+		lower := r - 'A' + 'a'
+		m.registers[lower] += text
+		return fmt.Sprintf("register %c", lower), "green"
+	case r != 0:
+		return "the kill ring", "green" // numbered registers are read-only
+	}
+
+	switch m.config.Clipboard {
+	case clipboardAuto:
+		err := writeSystemClipboard(text)
+		if err == nil {
+			m.clipboardSent = text
+			return "clipboard", "green"
+		}
+		LogDebugf("System clipboard unavailable, using OSC 52: %v", err)
+		fallthrough
+	case clipboardTerminal:
+		if err := writeOSC52(text); err != nil {
+			LogWarningf("Failed to write the terminal clipboard: %v", err)
+			return "the kill ring", "yellow"
+		}
+		m.clipboardSent = text
+		return "terminal clipboard", "green"
+	}
+	return "the kill ring", "green"
+}
+
+// clipboardText returns the text to paste: the chosen register, or else the
+// system clipboard when something outside the editor put text there since our
+// last copy, or else the newest kill ring entry
+func (m *model) clipboardText() (string, error) {
+	if r := m.takeRegister(); r != 0 {
+		if r >= 'A' && r <= 'Z' {
+			r = r - 'A' + 'a'
+		}
+		text, ok := m.registerText(r)
+		if !ok {
+			return "", fmt.Errorf("register %c is empty", r)
+		}
+		return text, nil
+	}
+	if m.config.Clipboard == clipboardAuto {
+		if text, err := readSystemClipboard(); err == nil && text != "" && text != m.clipboardSent {
+			return text, nil
+		}
+	}
+	if len(m.killRing) == 0 {
+		return "", fmt.Errorf("nothing to paste")
+	}
+	return m.killRing[0], nil
+}
+
+// pasteOlder replaces the text just pasted with the next older kill ring
+// entry, cycling through the ring on repeated use
+func (m *model) pasteOlder() {
+	p := m.lastPaste
+	if p.text == "" || len(m.killRing) == 0 || m.textBetween(p.y, p.x, m.cursorY, m.cursorX) != p.text {
+		m.setStatus("The last command was not a paste", "yellow")
+		return
+	}
+	index := (p.ring + 1) % len(m.killRing)
+	if index == p.ring {
+		m.setStatus("The kill ring holds nothing else", "yellow")
+		return
+	}
+	m.selectionActive = true
+	m.selectionStartY, m.selectionStartX = p.y, p.x
+	m.deleteSelection()
+	text := normalizePaste(m.killRing[index])
+	m.insertText(text)
+	m.lastPaste = pastedText{y: p.y, x: p.x, text: text, ring: index}
+	m.adjustViewport()
+	m.setStatus(fmt.Sprintf("Kill ring %d of %d", index+1, len(m.killRing)), "green")
+}
+
+// pastedText remembers the last paste so that pasteOlder can replace it
+type pastedText struct {
+	y, x int    // where the pasted text starts
+	text string // what was inserted
+	ring int    // its kill ring index, or -1 when it came from elsewhere
+}
+
+// textBetween returns the document text from (startY, startX) to (endY, endX),
+// or "" when the positions are out of range
+func (m *model) textBetween(startY, startX, endY, endX int) string {
+	if startY < 0 || endY >= len(m.lines) || startY > endY || startY == endY && startX > endX {
+		return ""
+	}
+	if startX > len(m.lines[startY]) || endX > len(m.lines[endY]) {
+		return ""
+	}
+	if startY == endY {
+		return m.lines[startY][startX:endX]
+	}
+	parts := []string{m.lines[startY][startX:]}
+	parts = append(parts, m.lines[startY+1:endY]...)
+	parts = append(parts, m.lines[endY][:endX])
+	return strings.Join(parts, "\n")
+}
+
+// showRegisters lists the named registers and the kill ring in the info pane
+func (m *model) showRegisters() {
+	var lines []string
+	preview := func(name string, text string) string {
+		return fmt.Sprintf("%-4s%s", name, registerPreview(text, 70))
+	}
+	for r := 'a'; r <= 'z'; r++ {
+		if text, ok := m.registers[r]; ok {
+			lines = append(lines, preview(`"`+string(r), text))
+		}
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	if len(m.killRing) == 0 {
+		lines = append(lines, "The kill ring is empty")
+	}
+	for i, text := range m.killRing {
+		name := ""
+		if i < 10 {
+			name = fmt.Sprintf(`"%d`, i)
+		}
+		lines = append(lines, preview(name, text))
+	}
+	lines = append(lines, "", `Use " (vim) or ctrl+r then a name to choose a register for the next copy, cut or paste`)
+	m.openInfoPane("Registers", lines)
+}
+
+// registerPreview shortens register text to one line of at most width characters
+func registerPreview(text string, width int) string {
+	text = strings.ReplaceAll(text, "\t", " ")
+	text = strings.ReplaceAll(text, "\n", "⏎")
+	if utf8.RuneCountInString(text) > width {
+		runes := []rune(text)
+		text = string(runes[:width-1]) + "…"
+	}
+	return text
+}
+
+// handlePutCommand handles ":put [register]", pasting a register or the clipboard
+func (m model) handlePutCommand(args []string) (tea.Model, tea.Cmd) {
+	if len(args) > 1 {
+		m.setStatus("Usage: :put [register]", "yellow")
+		return m, nil
+	}
+	if len(args) == 1 {
+		name := []rune(strings.TrimPrefix(args[0], `"`))
+		if len(name) != 1 || !validRegister(name[0]) {
+			m.setStatus("Registers are a-z, 0-9 and \"", "yellow")
+			return m, nil
+		}
+		m.register = name[0]
+	}
+	return m.runAction("clipboard.paste")
+}
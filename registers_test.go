@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeClipboard replaces the system clipboard and the terminal for a test.
+// With working false the system clipboard fails, as it does over SSH.
+func fakeClipboard(t *testing.T, working bool) (system *string, terminal *bytes.Buffer) {
+	t.Helper()
+	system, terminal = new(string), new(bytes.Buffer)
+	oldWrite, oldRead, oldTerminal := writeSystemClipboard, readSystemClipboard, terminalWriter
+	t.Cleanup(func() { writeSystemClipboard, readSystemClipboard, terminalWriter = oldWrite, oldRead, oldTerminal })
+	writeSystemClipboard = func(text string) error {
+		if !working {
+			return errors.New("no clipboard tool")
+		}
+		*system = text
+		return nil
+	}
+	readSystemClipboard = func() (string, error) {
+		if !working {
+			return "", errors.New("no clipboard tool")
+		}
+		return *system, nil
+	}
+	terminalWriter = terminal
+	t.Setenv("TMUX", "")
+	t.Setenv("STY", "")
+	return system, terminal
+}
+
+func TestClipboardFallsBackToOSC52(t *testing.T) {
+	_, terminal := fakeClipboard(t, false)
+	m := newEditorTestModel(t, []string{"one two three"}, 0, 4, 0, 7)
+
+	m = pressSpecial(t, m, tea.KeyCtrlX)
+	if m.lines[0] != "one  three" || m.statusMsg.Text != "Cut to terminal clipboard" {
+		t.Fatalf("line %q status %q", m.lines[0], m.statusMsg.Text)
+	}
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("two")) + "\x07"
+	if terminal.String() != want {
+		t.Errorf("terminal got %q, want %q", terminal.String(), want)
+	}
+
+	// Pasting can't read the terminal clipboard, so it uses the kill ring
+	m = pressSpecial(t, m, tea.KeyCtrlV)
+	if m.lines[0] != "one two three" {
+		t.Errorf("after paste: %q", m.lines[0])
+	}
+}
+
+func TestClipboardPrefersOutsideCopies(t *testing.T) {
+	system, _ := fakeClipboard(t, true)
+	m := newEditorTestModel(t, []string{"alpha beta"}, 0, 0, 0, 5)
+	m = pressSpecial(t, m, tea.KeyCtrlC)
+	if *system != "alpha" {
+		t.Fatalf("clipboard = %q", *system)
+	}
+
+	// A line killed since our own copy wins over the unchanged clipboard...
+	m.selectionActive = false
+	m.cursorX = 5
+	m.deleteToLineEnd()
+	m = pressSpecial(t, m, tea.KeyCtrlV)
+	if m.lines[0] != "alpha beta" {
+		t.Errorf("after kill and paste: %q", m.lines[0])
+	}
+
+	// ...but text copied in another program wins over the kill ring
+	*system = "gamma"
+	m = pressSpecial(t, m, tea.KeyCtrlV)
+	if m.lines[0] != "alpha betagamma" {
+		t.Errorf("after outside copy: %q", m.lines[0])
+	}
+}
+
+func TestNamedRegisters(t *testing.T) {
+	system, _ := fakeClipboard(t, true)
+	m := newEditorTestModel(t, []string{"red green blue"}, 0, 0, 0, 3)
+
+	m = pressSpecial(t, m, tea.KeyCtrlR)
+	m = pressKeys(t, m, "a")
+	m = pressSpecial(t, m, tea.KeyCtrlC)
+	if m.registers['a'] != "red" || *system != "" {
+		t.Fatalf("register a = %q, clipboard %q", m.registers['a'], *system)
+	}
+
+	// An upper-case name appends
+	m.selectionStartX, m.cursorX = 3, 9
+	m = pressSpecial(t, m, tea.KeyCtrlR)
+	m = pressKeys(t, m, "A")
+	m = pressSpecial(t, m, tea.KeyCtrlC)
+	if m.registers['a'] != "red green" {
+		t.Errorf("register a = %q after appending", m.registers['a'])
+	}
+
+	m.selectionActive = false
+	m.cursorX = len(m.lines[0])
+	m = pressSpecial(t, m, tea.KeyCtrlR)
+	m = pressKeys(t, m, "a")
+	m = pressSpecial(t, m, tea.KeyCtrlV)
+	if m.lines[0] != "red green bluered green" {
+		t.Errorf("after pasting register a: %q", m.lines[0])
+	}
+
+	// Numbered registers read the kill ring; :put takes a register name
+	m = runCommand(t, m, "put 1")
+	if !strings.HasSuffix(m.lines[0], "greenred") {
+		t.Errorf("after :put 1: %q", m.lines[0])
+	}
+	m = runCommand(t, m, "put z")
+	if m.statusMsg.Text != "Can't paste: register z is empty" {
+		t.Errorf("status = %q", m.statusMsg.Text)
+	}
+
+	m = runCommand(t, m, "registers")
+	if !m.infoVisible || m.infoLines[0] != `"a  red green` {
+		t.Errorf("registers pane: %q", m.infoLines)
+	}
+}
+
+func TestKillRing(t *testing.T) {
+	fakeClipboard(t, false)
+	m := newKeymapTestModel(t, "emacs")
+	m.mode = EditMode
+	m.lines = []string{"first", "second", "third"}
+
+	// Kill the three lines' text with ctrl+k, then yank and cycle with alt+y
+	for i := 0; i < 3; i++ {
+		m = pressSpecial(t, m, tea.KeyCtrlK)
+		m = pressSpecial(t, m, tea.KeyDown)
+	}
+	if want := []string{"third", "second", "first"}; !reflect.DeepEqual(m.killRing, want) {
+		t.Fatalf("kill ring = %q", m.killRing)
+	}
+	m.cursorY, m.cursorX = 0, 0
+	m = pressSpecial(t, m, tea.KeyCtrlY)
+	if m.lines[0] != "third" {
+		t.Fatalf("yank: %q", m.lines[0])
+	}
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y"), Alt: true})
+	m = result.(model)
+	if m.lines[0] != "second" {
+		t.Errorf("yank older: %q", m.lines[0])
+	}
+
+	// Moving away ends the yank
+	m = pressSpecial(t, m, tea.KeyLeft)
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y"), Alt: true})
+	m = result.(model)
+	if m.lines[0] != "second" || m.statusMsg.Text != "The last command was not a paste" {
+		t.Errorf("line %q status %q", m.lines[0], m.statusMsg.Text)
+	}
+
+	for i := 0; i < killRingSize+5; i++ {
+		m.pushKillRing("x")
+	}
+	if len(m.killRing) != killRingSize {
+		t.Errorf("kill ring grew to %d", len(m.killRing))
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Output formats for the spell and lint subcommands
+var reportFormats = []string{"text", "json", "sarif"}
+
+// spellingRule is the rule name given to misspellings
+const spellingRule = "spelling"
+
+// checkIssue is one finding reported by a subcommand
+type checkIssue struct {
+	File        string   `json:"file"`
+	Line        int      `json:"line"`       // 1-based
+	Column      int      `json:"column"`     // 1-based byte column
+	EndColumn   int      `json:"end_column"` // 1-based, exclusive
+	Rule        string   `json:"rule"`
+	Message     string   `json:"message"`
+	Word        string   `json:"word,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+
+	charColumn    int // 1-based code-point columns, for SARIF
+	charEndColumn int
+}
+
+// newCheckIssue locates a finding given a 0-based line and byte range
+func newCheckIssue(file string, lines []string, line int, start int, end int) checkIssue {
+	text := lines[line]
+	return checkIssue{
+		File:          file,
+		Line:          line + 1,
+		Column:        start + 1,
+		EndColumn:     end + 1,
+		charColumn:    utf8.RuneCountInString(text[:start]) + 1,
+		charEndColumn: utf8.RuneCountInString(text[:end]) + 1,
+	}
+}
+
+// reportRule describes a rule in SARIF output
+type reportRule struct {
+	id          string
+	description string
+}
+
+// writeReport prints issues in the requested format
+func writeReport(w io.Writer, format string, issues []checkIssue, rules []reportRule) error {
+	switch format {
+	case "json":
+		if issues == nil {
+			issues = []checkIssue{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(issues)
+	case "sarif":
+		return writeSARIF(w, issues, rules)
+	}
+
+	for _, issue := range issues {
+		var err error
+		if issue.Word != "" {
+			suggestions := ""
+			if len(issue.Suggestions) > 0 {
+				suggestions = " (" + strings.Join(issue.Suggestions, ", ") + ")"
+			}
+			_, err = fmt.Fprintf(w, "%s:%d:%d: %s%s\n", issue.File, issue.Line, issue.Column, issue.Word, suggestions)
+		} else {
+			_, err = fmt.Fprintf(w, "%s:%d:%d: %s [%s]\n", issue.File, issue.Line, issue.Column, issue.Message, issue.Rule)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SARIF 2.1.0 documents, as read by code-scanning tools
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// writeSARIF prints issues as a SARIF log
+func writeSARIF(w io.Writer, issues []checkIssue, rules []reportRule) error {
+	driver := sarifDriver{Name: "tuiwrite", Version: version, Rules: []sarifRule{}}
+	for _, r := range rules {
+		driver.Rules = append(driver.Rules, sarifRule{ID: r.id, ShortDescription: sarifMessage{r.description}})
+	}
+
+	results := []sarifResult{}
+	for _, issue := range issues {
+		// Misspellings fail a build; style findings are advice
+		level := "warning"
+		if issue.Rule == spellingRule {
+			level = "error"
+		}
+		results = append(results, sarifResult{
+			RuleID:  issue.Rule,
+			Level:   level,
+			Message: sarifMessage{issue.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact{URI: sarifURI(issue.File)},
+				Region: sarifRegion{
+					StartLine:   issue.Line,
+					StartColumn: issue.charColumn,
+					EndColumn:   issue.charEndColumn,
+				},
+			}}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{driver}, ColumnKind: "unicodeCodePoints", Results: results}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// sarifURI turns a path into a relative URI reference
+func sarifURI(path string) string {
+	path = strings.ReplaceAll(path, "\\", "/")
+	return strings.ReplaceAll(strings.TrimPrefix(path, "./"), " ", "%20")
+}
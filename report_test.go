@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/client9/gospell"
+)
+
+func TestMaskNonProse(t *testing.T) {
+	lines := []string{
+		"---",
+		"title: Zqx",
+		"---",
+		"Prose with `codez` and ``more ` code`` here.",
+		"```go",
+		"func zqx() {}",
+		"```",
+		"After the fence.",
+	}
+	masked := maskNonProse(lines)
+	for i := range lines {
+		if len(masked[i]) != len(lines[i]) {
+			t.Errorf("line %d changed length: %q", i, masked[i])
+		}
+	}
+	for _, i := range []int{0, 1, 2, 4, 5, 6} {
+		if strings.TrimSpace(masked[i]) != "" {
+			t.Errorf("line %d should be blank, got %q", i, masked[i])
+		}
+	}
+	if got := strings.Join(strings.Fields(masked[3]), " "); got != "Prose with and here." {
+		t.Errorf("inline code = %q", masked[3])
+	}
+	if masked[7] != lines[7] {
+		t.Errorf("text after a fence = %q", masked[7])
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	if d := editDistance([]rune("teh"), []rune("the")); d != 1 {
+		t.Errorf("a swap is one edit, got %d", d)
+	}
+	if d := editDistance([]rune("kitten"), []rune("sitting")); d != 3 {
+		t.Errorf("kitten/sitting = %d", d)
+	}
+
+	checker, err := gospell.NewGoSpellReader(strings.NewReader("SET UTF-8\n"), strings.NewReader("4\nnight\nknight\nnigh\nlight\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := newSpellChecker("us")
+	sc.checkers["us"] = checker
+	sc.languages = []string{"us"}
+
+	if got := sc.suggest("nigth", "", 3); !reflect.DeepEqual(got, []string{"night", "nigh", "light"}) {
+		t.Errorf("suggest(nigth) = %q", got)
+	}
+	if got := sc.suggest("Nigth", "", 1); !reflect.DeepEqual(got, []string{"Night"}) {
+		t.Errorf("suggestions should keep capitals, got %q", got)
+	}
+}
+
+// writeTestDictionary installs a tiny "us" dictionary under a temporary HOME
+func writeTestDictionary(t *testing.T, words ...string) string {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("config directory is only redirected through HOME on Linux")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".config", "tuiwrite", "dictionaries")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	dic := strings.Join(append([]string{"0"}, words...), "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "en_US.aff"), []byte("SET UTF-8\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "en_US.dic"), []byte(dic), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(home, ".config", "tuiwrite")
+}
+
+func TestSpellCommand(t *testing.T) {
+	configDir := writeTestDictionary(t, "the", "night", "was", "dark", "and")
+	project := t.TempDir()
+	chapter := filepath.Join(project, "ch1.md")
+	content := "---\ntitle: Zqx\n---\nThe nigth was dark and `codez` Elowen Brindle.\n"
+	if err := os.WriteFile(chapter, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runSpellCommand(args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	code, out, errOut := run("--lang", "us", chapter)
+	if code != exitFindings {
+		t.Fatalf("exit code = %d, stderr %q", code, errOut)
+	}
+	want := chapter + ":4:5: nigth (night)\n" +
+		chapter + ":4:32: Elowen\n" +
+		chapter + ":4:39: Brindle\n"
+	if out != want {
+		t.Errorf("text output:\n%s\nwant:\n%s", out, want)
+	}
+
+	// Personal and project word lists
+	if err := os.WriteFile(filepath.Join(configDir, personalWordsFile), []byte("# characters\nelowen\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, projectWordsFile), []byte("Brindle\nnigth\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code, out, _ := run("--lang", "us", chapter); code != exitOK || out != "" {
+		t.Errorf("word lists should accept every word: %d %q", code, out)
+	}
+
+	if err := os.WriteFile(filepath.Join(project, projectWordsFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, out, _ = run("--lang", "us", "--format", "json", chapter)
+	var issues []checkIssue
+	if err := json.Unmarshal([]byte(out), &issues); err != nil || code != exitFindings {
+		t.Fatalf("json output (%d): %v\n%s", code, err, out)
+	}
+	if len(issues) != 2 || issues[0].Word != "nigth" || issues[0].Column != 5 || issues[1].Word != "Brindle" {
+		t.Errorf("json issues = %+v", issues)
+	}
+
+	code, out, _ = run("--lang", "us", "--format", "sarif", chapter)
+	var log sarifLog
+	if err := json.Unmarshal([]byte(out), &log); err != nil || code != exitFindings {
+		t.Fatalf("sarif output (%d): %v\n%s", code, err, out)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 {
+		t.Fatalf("sarif = %+v", log)
+	}
+	region := log.Runs[0].Results[0].Locations[0].PhysicalLocation.Region
+	if region.StartLine != 4 || region.StartColumn != 5 || region.EndColumn != 10 {
+		t.Errorf("sarif region = %+v", region)
+	}
+
+	if code, _, _ := run("--format", "xml", chapter); code != exitUsage {
+		t.Errorf("unknown format = %d", code)
+	}
+	if code, _, errOut := run("--lang", "uk", chapter); code != exitFailure || !strings.Contains(errOut, "UK") {
+		t.Errorf("a missing dictionary = %d, %q", code, errOut)
+	}
+}
+
+func TestLintCommand(t *testing.T) {
+	dir := t.TempDir()
+	chapter := filepath.Join(dir, "ch1.md")
+	content := "---\ntitle: the the\n---\nShe walked the the long road.\n\n```\nthe the\n```\n"
+	if err := os.WriteFile(chapter, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := runLintCommand([]string{chapter}, &stdout, &stderr)
+	if code != exitFindings {
+		t.Fatalf("exit code = %d, stderr %q", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], chapter+":4:12: ") || !strings.HasSuffix(lines[0], "[repeated-word]") {
+		t.Errorf("lint output = %q", stdout.String())
+	}
+}
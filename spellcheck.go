@@ -6,9 +6,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/client9/gospell"
 )
@@ -35,16 +40,21 @@ var availableDicts = map[string]struct {
 
 // SpellChecker manages spell-checking functionality
 type SpellChecker struct {
-	checker  *gospell.GoSpell
-	enabled  bool
-	language string
+	checkers  map[string]*gospell.GoSpell // loaded dictionaries keyed by language code
+	enabled   bool
+	language  string   // primary language (first entry of languages)
+	languages []string // all active languages; a word is correct if any accepts it
+
+	knownWords map[string]bool // personal and project word lists
 }
 
 // newSpellChecker creates a new spell checker with the specified language
 func newSpellChecker(language string) *SpellChecker {
 	sc := &SpellChecker{
-		enabled:  false,
-		language: language,
+		checkers:  make(map[string]*gospell.GoSpell),
+		enabled:   false,
+		language:  language,
+		languages: []string{language},
 	}
 	return sc
 }
@@ -191,8 +201,13 @@ func (sc *SpellChecker) downloadDictionary(lang string) error {
 	return nil
 }
 
-// loadDictionary loads a dictionary into the spell checker
+// loadDictionary loads a dictionary into the spell checker without changing
+// the active language set
 func (sc *SpellChecker) loadDictionary(lang string) error {
+	if _, loaded := sc.checkers[lang]; loaded {
+		return nil
+	}
+
 	dictInfo, exists := availableDicts[lang]
 	if !exists {
 		return fmt.Errorf("dictionary for language '%s' not available", lang)
@@ -212,56 +227,175 @@ func (sc *SpellChecker) loadDictionary(lang string) error {
 		return fmt.Errorf("failed to load dictionary: %w", err)
 	}
 
-	sc.checker = checker
-	sc.language = lang
-	sc.enabled = true
+	if sc.checkers == nil {
+		sc.checkers = make(map[string]*gospell.GoSpell)
+	}
+	sc.checkers[lang] = checker
 
 	return nil
 }
 
-// setLanguage changes the spell-check language
+// setLanguage changes the spell-check language to a single dictionary
 func (sc *SpellChecker) setLanguage(language string) error {
-	lang := strings.ToLower(language)
+	return sc.setLanguages([]string{language})
+}
+
+// setLanguages activates one or more dictionaries at once, downloading any
+// that are missing. The first language becomes the primary language.
+func (sc *SpellChecker) setLanguages(languages []string) error {
+	var langs []string
+	for _, language := range languages {
+		lang := strings.ToLower(language)
+		if _, exists := availableDicts[lang]; !exists {
+			return fmt.Errorf("dictionary for language '%s' not available", lang)
+		}
+
+		// Check if dictionary exists locally
+		if !sc.hasDictionary(lang) {
+			// Download it
+			if err := sc.downloadDictionary(lang); err != nil {
+				return fmt.Errorf("failed to download dictionary: %w", err)
+			}
+		}
 
-	// Check if dictionary exists locally
-	if !sc.hasDictionary(lang) {
-		// Download it
-		if err := sc.downloadDictionary(lang); err != nil {
-			return fmt.Errorf("failed to download dictionary: %w", err)
+		// Load the dictionary
+		if err := sc.loadDictionary(lang); err != nil {
+			return err
 		}
+		if !containsString(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+
+	if len(langs) == 0 {
+		return fmt.Errorf("no language given")
 	}
 
-	// Load the dictionary
-	return sc.loadDictionary(lang)
+	sc.languages = langs
+	sc.language = langs[0]
+	sc.enabled = true
+	LogInfof("Spell-check languages: %s", strings.Join(langs, ", "))
+	return nil
 }
 
-// checkWord checks if a word is spelled correctly
+// useLocalLanguages activates the given languages using only dictionaries
+// already on disk. It never downloads, so it is safe to call when opening a
+// file, and leaves spell-checking on or off as it was. Languages without a
+// local dictionary are returned as missing.
+func (sc *SpellChecker) useLocalLanguages(languages []string) (missing []string) {
+	var langs []string
+	for _, lang := range languages {
+		if !sc.hasDictionary(lang) {
+			missing = append(missing, lang)
+			continue
+		}
+		if err := sc.loadDictionary(lang); err != nil {
+			LogWarningf("Failed to load %s dictionary: %v", lang, err)
+			missing = append(missing, lang)
+			continue
+		}
+		if !containsString(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+
+	if len(langs) > 0 {
+		sc.languages = langs
+		sc.language = langs[0]
+	}
+	return missing
+}
+
+// languageLabel returns the active languages for display, e.g. "UK+ES"
+func (sc *SpellChecker) languageLabel() string {
+	return strings.ToUpper(strings.Join(sc.languages, "+"))
+}
+
+// loaded reports whether a dictionary for an active language is loaded
+func (sc *SpellChecker) loaded() bool {
+	for _, lang := range sc.languages {
+		if _, loaded := sc.checkers[lang]; loaded {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureLoaded loads the active dictionaries if none are loaded yet
+func (sc *SpellChecker) ensureLoaded() error {
+	if sc.loaded() {
+		return nil
+	}
+	return sc.setLanguages(sc.languages)
+}
+
+// checkWord checks if a word is spelled correctly in any active language
 func (sc *SpellChecker) checkWord(word string) bool {
-	if !sc.enabled || sc.checker == nil {
-		return true // All words correct when disabled
+	if !sc.enabled || !shouldCheckWord(word) || sc.isKnownWord(word) {
+		return true
 	}
 
-	// Skip empty words or single characters
-	if len(word) <= 1 {
+	checked := false
+	for _, lang := range sc.languages {
+		checker, ok := sc.checkers[lang]
+		if !ok {
+			continue
+		}
+		checked = true
+		if checker.Spell(word) {
+			return true
+		}
+	}
+
+	// No dictionary loaded means nothing can be flagged
+	return !checked
+}
+
+// checkWordIn checks a word against a single language. Words marked with a
+// language whose dictionary is not loaded fall back to the active languages.
+func (sc *SpellChecker) checkWordIn(word string, lang string) bool {
+	if lang == "" {
+		return sc.checkWord(word)
+	}
+	if lang == skipSpellCheck || !sc.enabled || !shouldCheckWord(word) || sc.isKnownWord(word) {
 		return true
 	}
 
+	checker, ok := sc.checkers[lang]
+	if !ok {
+		return sc.checkWord(word)
+	}
+	return checker.Spell(word)
+}
+
+// shouldCheckWord reports whether a word is worth looking up at all
+func shouldCheckWord(word string) bool {
+	// Skip empty words or single characters
+	if utf8.RuneCountInString(word) <= 1 {
+		return false
+	}
+
 	// Skip words with numbers or special characters (likely proper nouns, URLs, etc.)
 	hasLetter := false
 	for _, r := range word {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+		if unicode.IsLetter(r) {
 			hasLetter = true
 		} else if r != '\'' && r != '-' {
-			return true // Contains special chars, skip
+			return false // Contains special chars, skip
 		}
 	}
 
-	if !hasLetter {
-		return true
-	}
+	return hasLetter
+}
 
-	// Check against dictionary using gospell
-	return sc.checker.Spell(word)
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
 
 // getWordsInLine extracts words and their positions from a line
@@ -272,7 +406,7 @@ func getWordsInLine(line string) []wordPos {
 	inWord := false
 
 	for i, r := range line {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '\'' || r == '-' {
+		if unicode.IsLetter(r) || r == '\'' || r == '-' {
 			if !inWord {
 				startPos = i
 				inWord = true
@@ -314,3 +448,197 @@ type wordPos struct {
 func (sc *SpellChecker) toggle() {
 	sc.enabled = !sc.enabled
 }
+
+// misspelling is a flagged word and where it is in the document
+type misspelling struct {
+	line int // 0-based line
+	col  int // 0-based byte offset
+	word string
+	lang string // inline span language, "" for the document's languages
+}
+
+// findMisspellings lists every word the active dictionaries reject,
+// honouring inline [text]{lang=..} spans
+func findMisspellings(lines []string, sc *SpellChecker) []misspelling {
+	var found []misspelling
+	for i, line := range lines {
+		spans := parseLanguageSpans(line)
+		for _, w := range getWordsInLine(line) {
+			lang := spanLanguageAt(spans, w.start)
+			if !sc.checkWordIn(w.word, lang) {
+				found = append(found, misspelling{line: i, col: w.start, word: w.word, lang: lang})
+			}
+		}
+	}
+	return found
+}
+
+// suggest lists up to limit dictionary words close to a misspelling,
+// nearest first. lang picks the dictionary ("" for the active languages).
+func (sc *SpellChecker) suggest(word string, lang string, limit int) []string {
+	langs := sc.languages
+	if lang != "" && lang != skipSpellCheck {
+		if _, ok := sc.checkers[lang]; ok {
+			langs = []string{lang}
+		}
+	}
+
+	target := []rune(strings.ToLower(word))
+	maxDistance := 2
+	if len(target) <= 4 {
+		maxDistance = 1
+	}
+
+	type candidate struct {
+		word       string
+		distance   int
+		lengthDiff int // ties go to same-length words, since swaps and typos are commoner than missing letters
+	}
+	seen := make(map[string]bool)
+	var candidates []candidate
+	for _, l := range langs {
+		checker, ok := sc.checkers[l]
+		if !ok {
+			continue
+		}
+		byLength := dictionaryByLength(checker)
+		for n := len(target) - maxDistance; n <= len(target)+maxDistance; n++ {
+			for _, lower := range byLength[n] {
+				if seen[lower] {
+					continue
+				}
+				if d := editDistance(target, []rune(lower)); d <= maxDistance {
+					seen[lower] = true
+					candidates = append(candidates, candidate{lower, d, absInt(n - len(target))})
+				}
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		if candidates[i].lengthDiff != candidates[j].lengthDiff {
+			return candidates[i].lengthDiff < candidates[j].lengthDiff
+		}
+		return candidates[i].word < candidates[j].word
+	})
+
+	var out []string
+	for _, c := range candidates {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, matchCase(word, c.word))
+	}
+	return out
+}
+
+// dictionaryIndex holds each loaded dictionary's words grouped by length, so
+// that suggest only compares words that could be close enough
+var dictionaryIndex = struct {
+	sync.Mutex
+	byLength map[*gospell.GoSpell]map[int][]string
+}{byLength: make(map[*gospell.GoSpell]map[int][]string)}
+
+// dictionaryByLength returns a dictionary's words, lowercased with case
+// variants collapsed (matchCase re-cases them), keyed by rune count. The index
+// is built on first use.
+func dictionaryByLength(checker *gospell.GoSpell) map[int][]string {
+	dictionaryIndex.Lock()
+	defer dictionaryIndex.Unlock()
+	if index, ok := dictionaryIndex.byLength[checker]; ok {
+		return index
+	}
+	index := make(map[int][]string)
+	seen := make(map[string]bool, len(checker.Dict))
+	for entry := range checker.Dict {
+		lower := strings.ToLower(entry)
+		if seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		n := utf8.RuneCountInString(lower)
+		index[n] = append(index[n], lower)
+	}
+	dictionaryIndex.byLength[checker] = index
+	return index
+}
+
+// editDistance counts the insertions, deletions, substitutions and adjacent
+// swaps needed to turn a into b (optimal string alignment distance)
+func editDistance(a []rune, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// maskNonProse blanks out the parts of a Markdown document that aren't prose
+// (front matter, fenced code blocks and inline code spans) so checkers skip
+// them. Byte offsets are unchanged.
+func maskNonProse(lines []string) []string {
+	masked := make([]string, len(lines))
+	copy(masked, lines)
+	blank := func(s string) string { return strings.Repeat(" ", len(s)) }
+
+	start := 0
+	if frontMatter, body := splitFrontMatter(lines); frontMatter != nil {
+		start = len(lines) - len(body)
+		for i := 0; i < start; i++ {
+			masked[i] = blank(lines[i])
+		}
+	}
+
+	fence := ""
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if fence != "" {
+			masked[i] = blank(lines[i])
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			for j := 3; j < len(trimmed) && trimmed[j] == fence[0]; j++ {
+				fence += fence[:1]
+			}
+			masked[i] = blank(lines[i])
+			continue
+		}
+		masked[i] = inlineCodePattern.ReplaceAllStringFunc(lines[i], blank)
+	}
+	return masked
+}
+
+// inlineCodePattern matches single and double backtick code spans
+var inlineCodePattern = regexp.MustCompile("``[^`]+(?:`[^`]+)*``|`[^`]+`")
+
+// isMarkdownFile reports whether a file is checked as Markdown
+func isMarkdownFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".mdown", ".mkd":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// skipSpellCheck marks a span that must never be spell-checked (such as the
+// attribute part of an inline language directive)
+const skipSpellCheck = "-"
+
+// languageMemoryFile stores the last spell-check languages used per project
+const languageMemoryFile = "spell-languages.json"
+
+// How many lines at the top and bottom of a file are scanned for a modeline
+const modelineScanLines = 5
+
+// langSpanPattern matches Pandoc-style inline spans: [texto]{lang=es}
+var langSpanPattern = regexp.MustCompile(`\[([^\[\]]*)\]\{\s*lang=["']?([A-Za-z_-]+)["']?\s*\}`)
+
+// modelinePattern matches "tuiwrite: spelllang=us,es" or vim's "spelllang=us,es"
+var modelinePattern = regexp.MustCompile(`(?:tuiwrite|vim?):.*\bspelllang=([A-Za-z_,-]+)`)
+
+// langSpan is a range of a source line checked against a specific language
+type langSpan struct {
+	start int
+	end   int
+	lang  string // language code, or skipSpellCheck
+}
+
+// normalizeLangCode maps codes such as "es", "es_ES", "en-GB" or "EN_us" onto
+// a key of availableDicts. Returns "" if no dictionary matches.
+func normalizeLangCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "_")
+	if _, ok := availableDicts[code]; ok {
+		return code
+	}
+
+	lang, region, found := strings.Cut(code, "_")
+	if !found {
+		return ""
+	}
+
+	// English dictionaries are keyed by region (uk, us, ca, au)
+	if lang == "en" {
+		if _, ok := availableDicts[region]; ok {
+			return region
+		}
+		return ""
+	}
+
+	if _, ok := availableDicts[lang]; ok {
+		return lang
+	}
+	return ""
+}
+
+// parseLanguageList splits a comma or space separated list of language codes
+func parseLanguageList(list string) []string {
+	var langs []string
+	for _, field := range strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '[' || r == ']' || r == '"' || r == '\''
+	}) {
+		if lang := normalizeLangCode(field); lang != "" && !containsString(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// parseDocumentLanguages reads the spell-check languages declared by a document,
+// either in YAML front matter ("lang: es" / "spelllang: [uk, es]") or in a
+// modeline near the top or bottom of the file ("tuiwrite: spelllang=uk,es").
+func parseDocumentLanguages(lines []string) []string {
+	// Front matter must start on the very first line
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			line := strings.TrimSpace(lines[i])
+			if line == "---" || line == "..." {
+				break
+			}
+			key, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "spelllang", "lang", "language":
+				if langs := parseLanguageList(value); len(langs) > 0 {
+					return langs
+				}
+			}
+		}
+	}
+
+	// Modelines, checked in the first and last few lines
+	for i, line := range lines {
+		if i >= modelineScanLines && i < len(lines)-modelineScanLines {
+			continue
+		}
+		if match := modelinePattern.FindStringSubmatch(line); match != nil {
+			if langs := parseLanguageList(match[1]); len(langs) > 0 {
+				return langs
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseLanguageSpans finds inline language directives in a source line. The
+// bracketed text is checked against the given language and the {lang=..}
+// attribute itself is skipped.
+func parseLanguageSpans(line string) []langSpan {
+	if !strings.Contains(line, "lang=") {
+		return nil
+	}
+
+	var spans []langSpan
+	for _, match := range langSpanPattern.FindAllStringSubmatchIndex(line, -1) {
+		lang := normalizeLangCode(line[match[4]:match[5]])
+		if lang == "" {
+			lang = skipSpellCheck // Unknown language: don't flag foreign words
+		}
+		spans = append(spans,
+			langSpan{start: match[0], end: match[2], lang: skipSpellCheck},
+			langSpan{start: match[2], end: match[3], lang: lang},
+			langSpan{start: match[3], end: match[1], lang: skipSpellCheck},
+		)
+	}
+	return spans
+}
+
+// spanLanguageAt returns the language for a byte offset, or "" for the
+// document's active languages
+func spanLanguageAt(spans []langSpan, pos int) string {
+	for _, span := range spans {
+		if pos >= span.start && pos < span.end {
+			return span.lang
+		}
+	}
+	return ""
+}
+
+// documentSpanLanguages collects every language referenced by inline spans
+func documentSpanLanguages(lines []string) []string {
+	var langs []string
+	for _, line := range lines {
+		for _, span := range parseLanguageSpans(line) {
+			if span.lang != skipSpellCheck && !containsString(langs, span.lang) {
+				langs = append(langs, span.lang)
+			}
+		}
+	}
+	return langs
+}
+
+// loadProjectLanguages returns the languages last used in a project directory
+func loadProjectLanguages(projectDir string) []string {
+	memory := readLanguageMemory()
+	return memory[projectKey(projectDir)]
+}
+
+// saveProjectLanguages remembers the languages used in a project directory
+func saveProjectLanguages(projectDir string, langs []string) error {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return err
+	}
+
+	memory := readLanguageMemory()
+	memory[projectKey(projectDir)] = langs
+
+	data, err := json.MarshalIndent(memory, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(configDir, languageMemoryFile), data, 0644)
+}
+
+// readLanguageMemory loads the project->languages map (empty if missing)
+func readLanguageMemory() map[string][]string {
+	memory := make(map[string][]string)
+
+	configDir, err := getConfigDir()
+	if err != nil {
+		return memory
+	}
+	data, err := os.ReadFile(filepath.Join(configDir, languageMemoryFile))
+	if err != nil {
+		return memory
+	}
+	if err := json.Unmarshal(data, &memory); err != nil {
+		LogWarningf("Ignoring corrupt %s: %v", languageMemoryFile, err)
+		return make(map[string][]string)
+	}
+	return memory
+}
+
+// projectKey normalises a project directory for use as a memory key
+func projectKey(projectDir string) string {
+	if abs, err := filepath.Abs(projectDir); err == nil {
+		return abs
+	}
+	return projectDir
+}
+
+// applyDocumentLanguages selects spell-check languages for the current file:
+// languages declared in the document win, then the project's last languages.
+// The personal and project word lists are reloaded.
+// Dictionaries referenced by inline spans are loaded too. Nothing is downloaded;
+// languages without a local dictionary are returned.
+func (m *model) applyDocumentLanguages() []string {
+	if m.spellChecker == nil {
+		return nil
+	}
+	m.spellChecker.loadWordLists(m.projectDir())
+
+	langs := parseDocumentLanguages(m.lines)
+	source := "document"
+	if len(langs) == 0 {
+		langs = loadProjectLanguages(m.projectDir())
+		source = "project"
+	}
+
+	var missing []string
+	if len(langs) > 0 {
+		missing = m.spellChecker.useLocalLanguages(langs)
+		LogInfof("Spell-check languages from %s: %s", source, strings.Join(langs, ", "))
+	}
+
+	// Span languages are loaded but not added to the active set
+	for _, lang := range documentSpanLanguages(m.lines) {
+		if !m.spellChecker.hasDictionary(lang) {
+			if !containsString(missing, lang) {
+				missing = append(missing, lang)
+			}
+			continue
+		}
+		if err := m.spellChecker.loadDictionary(lang); err != nil {
+			LogWarningf("Failed to load %s dictionary: %v", lang, err)
+		}
+	}
+
+	return missing
+}
+
+// missingDictionaryMessage formats a status message for undownloaded dictionaries
+func missingDictionaryMessage(missing []string) string {
+	return "Dictionary not installed: " + strings.ToUpper(strings.Join(missing, ", ")) + " (use :spell <lang> to download)"
+}
+
+// projectDir returns the directory used to remember per-project settings
+func (m model) projectDir() string {
+	if m.fileTreeRoot != "" {
+		return m.fileTreeRoot
+	}
+	if dir := filepath.Dir(m.filename); dir != "" {
+		return dir
+	}
+	return "."
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestNormalizeLangCode verifies locale codes map onto dictionary keys
+func TestNormalizeLangCode(t *testing.T) {
+	cases := map[string]string{
+		"es":    "es",
+		"ES":    "es",
+		"es_ES": "es",
+		"en-GB": "gb",
+		"en_us": "us",
+		"uk":    "uk",
+		"xx":    "",
+		"en_ZZ": "",
+	}
+
+	for input, want := range cases {
+		if got := normalizeLangCode(input); got != want {
+			t.Errorf("normalizeLangCode(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// TestParseDocumentLanguages verifies front matter and modeline detection
+func TestParseDocumentLanguages(t *testing.T) {
+	frontMatter := []string{"---", "title: Chapter One", "spelllang: [en_GB, es]", "---", "Text"}
+	if got := parseDocumentLanguages(frontMatter); !reflect.DeepEqual(got, []string{"gb", "es"}) {
+		t.Errorf("front matter: got %v", got)
+	}
+
+	modeline := []string{"Some prose.", "", "<!-- tuiwrite: spelllang=us,fr -->"}
+	if got := parseDocumentLanguages(modeline); !reflect.DeepEqual(got, []string{"us", "fr"}) {
+		t.Errorf("modeline: got %v", got)
+	}
+
+	if got := parseDocumentLanguages([]string{"No declaration here."}); got != nil {
+		t.Errorf("expected no languages, got %v", got)
+	}
+}
+
+// TestParseLanguageSpans verifies inline [text]{lang=xx} directives
+func TestParseLanguageSpans(t *testing.T) {
+	line := `She said [¿Dónde estás?]{lang=es} and left.`
+	spans := parseLanguageSpans(line)
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	wordPos := len("She said [")
+	if lang := spanLanguageAt(spans, wordPos); lang != "es" {
+		t.Errorf("expected 'es' inside span, got %q", lang)
+	}
+
+	attrPos := len(`She said [¿Dónde estás?]{l`)
+	if lang := spanLanguageAt(spans, attrPos); lang != skipSpellCheck {
+		t.Errorf("expected attribute to be skipped, got %q", lang)
+	}
+
+	if lang := spanLanguageAt(spans, 0); lang != "" {
+		t.Errorf("expected document language outside span, got %q", lang)
+	}
+}
+
+// TestGetWordsInLineUnicode verifies accented words are kept whole
+func TestGetWordsInLineUnicode(t *testing.T) {
+	words := getWordsInLine("¿Dónde estás?")
+	if len(words) != 2 || words[0].word != "Dónde" || words[1].word != "estás" {
+		t.Errorf("unexpected words: %+v", words)
+	}
+}
+
+// TestDocumentLanguageKeepsSpellCheckOff verifies that a language chosen by the
+// document doesn't turn spell-checking on; only :spell <lang> does
+func TestDocumentLanguageKeepsSpellCheckOff(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	dictPath, err := getDictPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	es := availableDicts["es"]
+	if err := os.MkdirAll(dictPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dictPath, es.affFile), []byte("SET UTF-8\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dictPath, es.dicFile), []byte("1\nhola\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := model{
+		lines:        []string{"<!-- tuiwrite: spelllang=es -->", "hola"},
+		config:       defaultConfig(),
+		spellChecker: newSpellChecker("uk"),
+	}
+	if missing := m.applyDocumentLanguages(); len(missing) > 0 {
+		t.Fatalf("missing dictionaries: %v", missing)
+	}
+	if m.spellChecker.language != "es" {
+		t.Errorf("language = %q, want es", m.spellChecker.language)
+	}
+	if m.spellChecker.enabled {
+		t.Error("a document language turned spell-checking on")
+	}
+
+	if err := m.spellChecker.setLanguages([]string{"es"}); err != nil {
+		t.Fatal(err)
+	}
+	if !m.spellChecker.enabled {
+		t.Error(":spell es should turn spell-checking on")
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sprintKind marks sprint results in the writing history
+const sprintKind = "sprint"
+
+// writingSprint is a timed writing session started with :sprint
+type writingSprint struct {
+	id        int             // distinguishes tick chains of successive sprints
+	end       time.Time       // when the countdown reaches zero
+	duration  time.Duration   // requested length
+	tracker   *sessionTracker // words added/removed during the sprint
+	locked    bool            // editor held in Edit mode with zen layout
+	blockQuit bool            // quitting is refused until the timer ends
+	prevZen   bool            // zen mode before the sprint, restored afterwards
+}
+
+// sprintTickMsg is sent every second while a sprint is running
+type sprintTickMsg struct {
+	id int
+}
+
+// tickSprint returns a command that sends sprintTickMsg after one second
+func tickSprint(id int) tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return sprintTickMsg{id: id}
+	})
+}
+
+// parseSprintDuration accepts Go durations ("25m", "1h30m") or bare minutes ("25")
+func parseSprintDuration(s string) (time.Duration, error) {
+	if minutes, err := strconv.Atoi(s); err == nil {
+		if minutes <= 0 {
+			return 0, fmt.Errorf("sprint length must be positive")
+		}
+		return time.Duration(minutes) * time.Minute, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sprint length %q (try 25m)", s)
+	}
+	if d < time.Second {
+		return 0, fmt.Errorf("sprint length must be at least 1s")
+	}
+	return d, nil
+}
+
+// formatCountdown renders the remaining time as m:ss or h:mm:ss
+func formatCountdown(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	total := int((d + time.Second - 1) / time.Second) // round up so 0:00 means done
+	h, mins, secs := total/3600, (total/60)%60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, mins, secs)
+	}
+	return fmt.Sprintf("%d:%02d", mins, secs)
+}
+
+// handleSprintCommand handles ":sprint <length> [lock] [noblock]" and ":sprint stop"
+func (m model) handleSprintCommand(args []string) (tea.Model, tea.Cmd) {
+	if len(args) == 0 {
+		if m.sprint == nil {
+			m.setStatus("Usage: :sprint <length> [lock] [noblock] | :sprint stop", "yellow")
+		} else {
+			m.setStatus(m.sprintStatus(), "green")
+		}
+		return m, nil
+	}
+
+	if args[0] == "stop" || args[0] == "cancel" {
+		if m.sprint == nil {
+			m.setStatus("No sprint running", "yellow")
+			return m, nil
+		}
+		m.finishSprint(true)
+		return m, nil
+	}
+
+	if m.readOnly {
+		m.setStatus("Can't edit: "+errReadOnly.Error(), "yellow")
+		return m, nil
+	}
+
+	if m.sprint != nil {
+		m.setStatus("A sprint is already running (:sprint stop to end it)", "yellow")
+		return m, nil
+	}
+
+	duration, err := parseSprintDuration(args[0])
+	if err != nil {
+		m.setStatus(err.Error(), "red")
+		return m, nil
+	}
+
+	locked := false
+	blockQuit := m.config.SprintBlockQuit
+	for _, opt := range args[1:] {
+		switch opt {
+		case "lock":
+			locked = true
+		case "noblock":
+			blockQuit = false
+		case "block":
+			blockQuit = true
+		default:
+			m.setStatus("Unknown sprint option: "+opt+" (lock, block, noblock)", "red")
+			return m, nil
+		}
+	}
+
+	m.sprintCounter++
+	tracker := newSessionTracker(m.filename, projectKey(m.projectDir()), m.wordCounter.count(m.lines))
+	tracker.session.Kind = sprintKind
+	m.sprint = &writingSprint{
+		id:        m.sprintCounter,
+		end:       time.Now().Add(duration),
+		duration:  duration,
+		tracker:   tracker,
+		locked:    locked,
+		blockQuit: blockQuit,
+		prevZen:   m.zenMode,
+	}
+
+	if locked {
+		m.mode = EditMode
+		m.zenMode = true
+		m.fileTreeVisible = false
+		m.fileTreeFocused = false
+		m.rewrapLines()
+	}
+
+	LogEvent("SPRINT", fmt.Sprintf("Started %s sprint (lock=%v, blockQuit=%v)", duration, locked, blockQuit))
+	m.setStatus("Sprint started: "+formatCountdown(duration)+" - go!", "green")
+	return m, tickSprint(m.sprint.id)
+}
+
+// handleSprintTick counts down and finishes the sprint when time is up
+func (m model) handleSprintTick(msg sprintTickMsg) (tea.Model, tea.Cmd) {
+	if m.sprint == nil || msg.id != m.sprint.id {
+		return m, nil // stale tick from a finished sprint
+	}
+	if time.Now().Before(m.sprint.end) {
+		return m, tickSprint(msg.id)
+	}
+	m.finishSprint(false)
+	return m, nil
+}
+
+// finishSprint ends the sprint, restores the layout and logs the result
+func (m *model) finishSprint(cancelled bool) {
+	sprint := m.sprint
+	sprint.tracker.observe(m.wordCounter.count(m.lines))
+	m.sprint = nil
+
+	if sprint.locked {
+		m.zenMode = sprint.prevZen
+		m.rewrapLines()
+	}
+
+	s := sprint.tracker.session
+	s.End = time.Now()
+	elapsed := s.End.Sub(s.Start)
+
+	path, err := configFilePath(historyFile)
+	if err == nil {
+		err = appendHistory(path, s)
+	}
+	if err != nil {
+		LogErrorf("Failed to record sprint: %v", err)
+	}
+
+	wpm := 0
+	if minutes := elapsed.Minutes(); minutes >= 1 {
+		wpm = int(float64(s.net()) / minutes)
+	}
+	result := fmt.Sprintf("%s words in %s (%d wpm)", formatCount(s.net()), formatCountdown(elapsed), wpm)
+	LogEvent("SPRINT", fmt.Sprintf("Finished (cancelled=%v): +%d -%d words", cancelled, s.Added, s.Removed))
+
+	if cancelled {
+		m.setStatus("Sprint stopped: "+result, "yellow")
+	} else {
+		m.setStatus("Sprint finished: "+result, "green")
+	}
+}
+
+// sprintStatus returns the countdown shown in the status bar ("" when no sprint runs)
+func (m model) sprintStatus() string {
+	if m.sprint == nil {
+		return ""
+	}
+	return fmt.Sprintf("Sprint %s · %s words", formatCountdown(time.Until(m.sprint.end)), formatCount(m.sprint.tracker.session.net()))
+}
+
+// quitBlocked reports whether a running sprint refuses to quit, and warns the user
+func (m *model) quitBlocked() bool {
+	if m.sprint == nil || !m.sprint.blockQuit {
+		return false
+	}
+	m.setStatus("Sprint in progress, "+formatCountdown(time.Until(m.sprint.end))+" left (F12 or :sprint stop to end early)", "yellow")
+	return true
+}
+
+// sprintLocked reports whether the editor must stay in Edit mode
+func (m model) sprintLocked() bool {
+	return m.sprint != nil && m.sprint.locked
+}
+
+// sprintSummary describes past sprints for the :goals report
+func sprintSummary(sessions []writingSession, project string) string {
+	count, best := 0, 0
+	var bestLength time.Duration
+	for _, s := range sessions {
+		if s.Kind != sprintKind || s.Project != project {
+			continue
+		}
+		count++
+		if s.net() > best {
+			best = s.net()
+			bestLength = s.End.Sub(s.Start)
+		}
+	}
+	if count == 0 {
+		return "Sprints: none yet (:sprint 25m)"
+	}
+	parts := []string{fmt.Sprintf("Sprints: %d", count)}
+	if best > 0 {
+		parts = append(parts, fmt.Sprintf("best %s words in %s", formatCount(best), formatCountdown(bestLength)))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseSprintDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"25m", 25 * time.Minute, true},
+		{"25", 25 * time.Minute, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"90s", 90 * time.Second, true},
+		{"0", 0, false},
+		{"-5m", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseSprintDuration(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseSprintDuration(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestFormatCountdown(t *testing.T) {
+	tests := map[time.Duration]string{
+		25 * time.Minute:                      "25:00",
+		61*time.Second + 500*time.Millisecond: "1:02",
+		time.Hour + 5*time.Minute:             "1:05:00",
+		-time.Second:                          "0:00",
+	}
+	for in, want := range tests {
+		if got := formatCountdown(in); got != want {
+			t.Errorf("formatCountdown(%v) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSprintsNotCountedTwice(t *testing.T) {
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	sessions := []writingSession{
+		{Start: day, End: day.Add(time.Hour), Project: "/a", Added: 800},
+		{Start: day, End: day.Add(25 * time.Minute), Project: "/a", Added: 500, Kind: sprintKind},
+		{Start: day, End: day.Add(10 * time.Minute), Project: "/a", Added: 200, Kind: sprintKind},
+	}
+
+	if got := dailyTotals(sessions, "/a")["2024-03-01"]; got != 800 {
+		t.Errorf("daily total = %d, want 800", got)
+	}
+	if got, want := sprintSummary(sessions, "/a"), "Sprints: 2, best 500 words in 25:00"; got != want {
+		t.Errorf("sprintSummary = %q, want %q", got, want)
+	}
+}
+
+func TestSprintBlocksQuitAndLocksMode(t *testing.T) {
+	m := model{
+		lines:       []string{"one two three"},
+		wrapCache:   make(map[int][]wrappedLine),
+		wordCounter: newWordCounter(),
+		config:      defaultConfig(),
+		mode:        ReadMode,
+	}
+
+	result, cmd := m.handleSprintCommand([]string{"25m", "lock"})
+	m = result.(model)
+	if m.sprint == nil || cmd == nil {
+		t.Fatal("sprint did not start")
+	}
+	if m.mode != EditMode || !m.zenMode || !m.sprintLocked() {
+		t.Error("locked sprint should switch to Edit mode with zen layout")
+	}
+	if !m.quitBlocked() {
+		t.Error("quitting should be blocked during the sprint")
+	}
+
+	// Words typed during the sprint are counted
+	m.lines = []string{"one two three four five"}
+	m.trackWords()
+	if got := m.sprint.tracker.session.net(); got != 2 {
+		t.Errorf("sprint words = %d, want 2", got)
+	}
+
+	// A stale tick from an earlier sprint is ignored
+	result, cmd = m.handleSprintTick(sprintTickMsg{id: m.sprint.id + 1})
+	if result.(model).sprint == nil || cmd != nil {
+		t.Error("stale tick should be ignored")
+	}
+
+	// noblock lets the writer quit
+	m.sprint.blockQuit = false
+	if m.quitBlocked() {
+		t.Error("noblock sprint should allow quitting")
+	}
+}
+
+func TestLockedSprintStopsWithKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	m := newKeymapTestModel(t, defaultKeymapPreset)
+	m.wordCounter = newWordCounter()
+	m = runCommand(t, m, "sprint 25m lock block")
+	if !m.sprintLocked() || !m.sprint.blockQuit {
+		t.Fatal("locked, blocking sprint did not start")
+	}
+
+	// Esc and the quit key are refused while it runs
+	m = pressSpecial(t, m, tea.KeyEsc)
+	if m.mode != EditMode {
+		t.Error("esc should not leave Edit mode during a locked sprint")
+	}
+	if _, cmd := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlQ}); cmd != nil {
+		t.Error("quitting should be refused during a blocking sprint")
+	}
+
+	m = pressSpecial(t, m, tea.KeyF12)
+	if m.sprint != nil || m.sprintLocked() {
+		t.Fatal("F12 should stop the sprint")
+	}
+	if !strings.HasPrefix(m.statusMsg.Text, "Sprint stopped") {
+		t.Errorf("status = %q", m.statusMsg.Text)
+	}
+	m = pressSpecial(t, m, tea.KeyEsc)
+	if m.mode != ReadMode {
+		t.Error("esc should return to Read mode once the sprint is stopped")
+	}
+}
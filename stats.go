@@ -0,0 +1,438 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statsPanelDefaultWidth is the width of the statistics sidebar
+const statsPanelDefaultWidth = 34
+
+// readingWordsPerMinute is the average adult silent reading speed
+const readingWordsPerMinute = 230
+
+// topWordCount is how many frequent words the panel lists
+const topWordCount = 5
+
+// textStats holds counts and readability scores for a piece of text
+type textStats struct {
+	words        int
+	characters   int // all characters, including spaces
+	charsNoSpace int
+	letters      int
+	sentences    int
+	paragraphs   int
+	syllables    int
+	complexWords int // words with three or more syllables
+	topWords     []wordFrequency
+}
+
+// wordFrequency is a word and how often it occurs
+type wordFrequency struct {
+	word  string
+	count int
+}
+
+// stopWords are excluded from the most frequent words list
+var stopWords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "an": true, "and": true, "any": true,
+	"are": true, "as": true, "at": true, "back": true, "be": true, "been": true, "but": true,
+	"by": true, "can": true, "could": true, "did": true, "do": true, "down": true, "for": true,
+	"from": true, "had": true, "has": true, "have": true, "he": true, "her": true, "him": true,
+	"his": true, "i": true, "if": true, "in": true, "into": true, "is": true, "it": true,
+	"it's": true, "its": true, "just": true, "like": true, "me": true, "my": true, "no": true,
+	"not": true, "now": true, "of": true, "on": true, "one": true, "or": true, "out": true,
+	"over": true, "said": true, "she": true, "so": true, "some": true, "than": true, "that": true,
+	"the": true, "their": true, "them": true, "then": true, "there": true, "they": true,
+	"this": true, "to": true, "up": true, "us": true, "was": true, "we": true, "were": true,
+	"what": true, "when": true, "which": true, "who": true, "will": true, "with": true,
+	"would": true, "you": true, "your": true, "i'm": true, "don't": true, "didn't": true,
+}
+
+// computeTextStats analyses lines of text. Paragraphs are runs of non-blank lines.
+func computeTextStats(lines []string) textStats {
+	var stats textStats
+	frequency := make(map[string]int)
+	inParagraph := false
+
+	for i, line := range lines {
+		stats.characters += utf8.RuneCountInString(line)
+		if i < len(lines)-1 {
+			stats.characters++ // The newline
+		}
+
+		if strings.TrimSpace(line) == "" {
+			inParagraph = false
+			continue
+		}
+		if !inParagraph {
+			stats.paragraphs++
+			inParagraph = true
+		}
+
+		for _, r := range line {
+			if !unicode.IsSpace(r) {
+				stats.charsNoSpace++
+			}
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				stats.letters++
+			}
+		}
+
+		stats.sentences += countSentences(line)
+
+		for _, w := range getWordsInLine(line) {
+			word := strings.ToLower(strings.Trim(w.word, "'-"))
+			// A lone "-" or "'" is punctuation, not a word
+			if word == "" {
+				continue
+			}
+			stats.words++
+			syllables := countSyllables(word)
+			stats.syllables += syllables
+			if syllables >= 3 {
+				stats.complexWords++
+			}
+			if !stopWords[word] && utf8.RuneCountInString(word) > 2 {
+				frequency[word]++
+			}
+		}
+	}
+
+	// Text without terminal punctuation still counts as one sentence
+	if stats.sentences == 0 && stats.words > 0 {
+		stats.sentences = 1
+	}
+
+	stats.topWords = topWords(frequency, topWordCount)
+	return stats
+}
+
+// countSentences counts sentence-ending punctuation runs in a line. A line
+// with words but no terminal punctuation (a heading, say) counts as one.
+func countSentences(line string) int {
+	count := 0
+	inTerminator := false
+	for _, r := range line {
+		if r == '.' || r == '!' || r == '?' || r == '…' {
+			if !inTerminator {
+				count++
+			}
+			inTerminator = true
+		} else {
+			inTerminator = false
+		}
+	}
+	if count == 0 && len(getWordsInLine(line)) > 0 {
+		count = 1
+	}
+	return count
+}
+
+// countSyllables estimates the syllables in an English word by counting vowel groups
+func countSyllables(word string) int {
+	word = strings.ToLower(word)
+	count := 0
+	prevVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !prevVowel {
+			count++
+		}
+		prevVowel = vowel
+	}
+
+	// Silent trailing "e" ("make"), but not "-le" ("table")
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// topWords returns the n most frequent words, ties broken alphabetically
+func topWords(frequency map[string]int, n int) []wordFrequency {
+	words := make([]wordFrequency, 0, len(frequency))
+	for word, count := range frequency {
+		words = append(words, wordFrequency{word, count})
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].count != words[j].count {
+			return words[i].count > words[j].count
+		}
+		return words[i].word < words[j].word
+	})
+	if len(words) > n {
+		words = words[:n]
+	}
+	return words
+}
+
+// readingMinutes returns the estimated reading time in whole minutes (at least 1 for any text)
+func (s textStats) readingMinutes() int {
+	if s.words == 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(s.words) / readingWordsPerMinute))
+}
+
+// averageSentenceLength returns words per sentence
+func (s textStats) averageSentenceLength() float64 {
+	if s.sentences == 0 {
+		return 0
+	}
+	return float64(s.words) / float64(s.sentences)
+}
+
+// fleschReadingEase returns the Flesch Reading Ease score (higher is easier)
+func (s textStats) fleschReadingEase() float64 {
+	if s.words == 0 {
+		return 0
+	}
+	return 206.835 - 1.015*s.averageSentenceLength() - 84.6*float64(s.syllables)/float64(s.words)
+}
+
+// fleschKincaidGrade returns the Flesch–Kincaid grade level
+func (s textStats) fleschKincaidGrade() float64 {
+	if s.words == 0 {
+		return 0
+	}
+	return 0.39*s.averageSentenceLength() + 11.8*float64(s.syllables)/float64(s.words) - 15.59
+}
+
+// gunningFog returns the Gunning fog index
+func (s textStats) gunningFog() float64 {
+	if s.words == 0 {
+		return 0
+	}
+	return 0.4 * (s.averageSentenceLength() + 100*float64(s.complexWords)/float64(s.words))
+}
+
+// colemanLiau returns the Coleman–Liau index
+func (s textStats) colemanLiau() float64 {
+	if s.words == 0 {
+		return 0
+	}
+	lettersPer100 := float64(s.letters) / float64(s.words) * 100
+	sentencesPer100 := float64(s.sentences) / float64(s.words) * 100
+	return 0.0588*lettersPer100 - 0.296*sentencesPer100 - 15.8
+}
+
+// automatedReadability returns the Automated Readability Index
+func (s textStats) automatedReadability() float64 {
+	if s.words == 0 {
+		return 0
+	}
+	return 4.71*float64(s.letters)/float64(s.words) + 0.5*s.averageSentenceLength() - 21.43
+}
+
+// countWords returns the number of words in the given lines
+func countWords(lines []string) int {
+	count := 0
+	for _, line := range lines {
+		count += countWordsInLine(line)
+	}
+	return count
+}
+
+// countWordsInLine counts words the same way getWordsInLine splits them, without
+// allocating. A lone "-" or "'" is punctuation, not a word.
+func countWordsInLine(line string) int {
+	count := 0
+	inWord := false
+	hasLetter := false
+	for _, r := range line {
+		if unicode.IsLetter(r) || r == '\'' || r == '-' {
+			inWord = true
+			if r != '\'' && r != '-' {
+				hasLetter = true
+			}
+			continue
+		}
+		if inWord && hasLetter {
+			count++
+		}
+		inWord = false
+		hasLetter = false
+	}
+	if inWord && hasLetter {
+		count++
+	}
+	return count
+}
+
+// wordCounter caches per-line word counts keyed by the line's content, so the
+// live word count only re-counts lines that changed since the last frame
+type wordCounter struct {
+	counts map[string]int
+}
+
+// newWordCounter creates an empty word count cache
+func newWordCounter() *wordCounter {
+	return &wordCounter{counts: make(map[string]int)}
+}
+
+// count returns the number of words in the given lines
+func (wc *wordCounter) count(lines []string) int {
+	if wc == nil {
+		return countWords(lines)
+	}
+
+	// Drop counts for old versions of edited lines
+	if len(wc.counts) > 2*len(lines)+100 {
+		wc.counts = make(map[string]int)
+	}
+
+	total := 0
+	for _, line := range lines {
+		n, ok := wc.counts[line]
+		if !ok {
+			n = countWordsInLine(line)
+			wc.counts[line] = n
+		}
+		total += n
+	}
+	return total
+}
+
+// statsCache holds the last document statistics so the panel isn't recomputed
+// on every frame (cursor blinks redraw twice a second)
+type statsCache struct {
+	stats    textStats
+	computed time.Time
+	lines    int
+}
+
+// statsRefreshInterval is how stale the document statistics may get
+const statsRefreshInterval = time.Second
+
+// documentStats returns the document statistics, recomputing at most once per
+// statsRefreshInterval unless the number of lines changed
+func (m model) documentStats() textStats {
+	cache := m.statsCache
+	if cache == nil {
+		return computeTextStats(m.lines)
+	}
+	if cache.computed.IsZero() || cache.lines != len(m.lines) || time.Since(cache.computed) > statsRefreshInterval {
+		cache.stats = computeTextStats(m.lines)
+		cache.computed = time.Now()
+		cache.lines = len(m.lines)
+	}
+	return cache.stats
+}
+
+// formatCount formats an integer with thousands separators (12,345)
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := fmt.Sprintf("%d", n)
+	var out strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			out.WriteRune(',')
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+// toggleStatsPanel shows or hides the statistics sidebar
+func (m *model) toggleStatsPanel() {
+	m.statsVisible = !m.statsVisible
+	if m.statsVisible {
+		LogEvent("STATS", "Opened statistics panel")
+		m.setStatus("Statistics panel (F2 or :stats to close)", "green")
+	} else {
+		LogEvent("STATS", "Closed statistics panel")
+		m.setStatus("Statistics panel closed", "yellow")
+	}
+
+	// The editor column changed width
+	m.rewrapLines()
+}
+
+// statsPanelWidth returns the width of the statistics column (0 when hidden)
+func (m model) statsPanelWidth() int {
+	if !m.statsVisible {
+		return 0
+	}
+	return statsPanelDefaultWidth
+}
+
+// renderStatsPanel renders the statistics sidebar as one string per row
+func (m model) renderStatsPanel(width int, height int) []string {
+	headingStyle := lipgloss.NewStyle().
+		Background(m.themeColor("editor.bg")).
+		Foreground(m.themeColor("stats.heading")).
+		Bold(true).
+		Width(width)
+
+	rowStyle := lipgloss.NewStyle().
+		Background(m.themeColor("editor.bg")).
+		Foreground(m.themeColor("stats.fg")).
+		Width(width)
+
+	var rows []string
+	heading := func(text string) {
+		rows = append(rows, headingStyle.Render(" "+text))
+	}
+	row := func(label string, value string) {
+		gap := width - 3 - utf8.RuneCountInString(label) - utf8.RuneCountInString(value)
+		if gap < 1 {
+			gap = 1
+		}
+		rows = append(rows, rowStyle.Render(truncateRunes("  "+label+strings.Repeat(" ", gap)+value, width)))
+	}
+
+	addStats := func(s textStats) {
+		row("Words", formatCount(s.words))
+		row("Characters", formatCount(s.characters))
+		row("Chars (no spaces)", formatCount(s.charsNoSpace))
+		row("Sentences", formatCount(s.sentences))
+		row("Paragraphs", formatCount(s.paragraphs))
+		row("Reading time", fmt.Sprintf("%d min", s.readingMinutes()))
+		row("Avg sentence", fmt.Sprintf("%.1f words", s.averageSentenceLength()))
+		row("Flesch ease", fmt.Sprintf("%.1f", s.fleschReadingEase()))
+		row("Flesch–Kincaid", fmt.Sprintf("%.1f", s.fleschKincaidGrade()))
+		row("Gunning fog", fmt.Sprintf("%.1f", s.gunningFog()))
+		row("Coleman–Liau", fmt.Sprintf("%.1f", s.colemanLiau()))
+		row("ARI", fmt.Sprintf("%.1f", s.automatedReadability()))
+		if len(s.topWords) > 0 {
+			rows = append(rows, rowStyle.Render(""))
+			heading("Most frequent")
+			for _, wf := range s.topWords {
+				row(wf.word, formatCount(wf.count))
+			}
+		}
+	}
+
+	// Selection first, since it's what the writer is looking at
+	if m.selectionActive {
+		heading("Selection")
+		addStats(computeTextStats(strings.Split(m.selectedText(), "\n")))
+		// Half the panel at most, so the document stats stay in view
+		if limit := height / 2; len(rows) > limit {
+			rows = rows[:limit]
+		}
+		rows = append(rows, rowStyle.Render(""))
+	}
+
+	heading("Document")
+	addStats(m.documentStats())
+
+	// Pad or trim to the available height
+	for len(rows) < height {
+		rows = append(rows, rowStyle.Render(""))
+	}
+	return rows[:height]
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestComputeTextStats verifies the basic counts on a small document
+func TestComputeTextStats(t *testing.T) {
+	lines := []string{
+		"The cat sat on the mat. The cat was happy!",
+		"",
+		"Another paragraph - with a dash.",
+	}
+
+	stats := computeTextStats(lines)
+
+	if stats.words != 15 {
+		t.Errorf("expected 15 words, got %d", stats.words)
+	}
+	if stats.sentences != 3 {
+		t.Errorf("expected 3 sentences, got %d", stats.sentences)
+	}
+	if stats.paragraphs != 2 {
+		t.Errorf("expected 2 paragraphs, got %d", stats.paragraphs)
+	}
+	if len(stats.topWords) == 0 || stats.topWords[0].word != "cat" || stats.topWords[0].count != 2 {
+		t.Errorf("expected 'cat' to be the most frequent word, got %+v", stats.topWords)
+	}
+	if stats.readingMinutes() != 1 {
+		t.Errorf("expected 1 minute reading time, got %d", stats.readingMinutes())
+	}
+}
+
+// TestCountWordsMatchesStats verifies the status bar count agrees with the panel
+func TestCountWordsMatchesStats(t *testing.T) {
+	lines := []string{"It's a well-known fact - isn't it?", "Dónde estás", "' -- '"}
+
+	if got, want := countWords(lines), computeTextStats(lines).words; got != want {
+		t.Errorf("countWords = %d, computeTextStats = %d", got, want)
+	}
+
+	wc := newWordCounter()
+	if got := wc.count(lines); got != countWords(lines) {
+		t.Errorf("cached count %d differs from countWords", got)
+	}
+}
+
+// TestCountSyllables verifies the syllable heuristic on common words
+func TestCountSyllables(t *testing.T) {
+	cases := map[string]int{
+		"cat":         1,
+		"make":        1,
+		"table":       2,
+		"happy":       2,
+		"beautiful":   3,
+		"readability": 5,
+	}
+	for word, want := range cases {
+		if got := countSyllables(word); got != want {
+			t.Errorf("countSyllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
+
+// TestFormatCount verifies thousands separators
+func TestFormatCount(t *testing.T) {
+	cases := map[int]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -4200: "-4,200"}
+	for n, want := range cases {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+// TestStatsPanelSelection verifies the selection section has the readability
+// scores and frequent words, as the document section does
+func TestStatsPanelSelection(t *testing.T) {
+	m := model{lines: []string{"The harbour lights burned. The harbour slept.", "", "Nothing else happened that night."}}
+	m.selectionActive = true
+	m.selectionStartY, m.selectionStartX = 0, 0
+	m.cursorY, m.cursorX = 0, len(m.lines[0])
+
+	rows := m.renderStatsPanel(40, 80)
+	text := stripAnsi(strings.Join(rows, "\n"))
+	selection, document, ok := strings.Cut(text, " Document")
+	if !ok || !strings.Contains(selection, " Selection") {
+		t.Fatalf("panel:\n%s", text)
+	}
+	for _, label := range []string{"Flesch–Kincaid", "Gunning fog", "Most frequent", "harbour"} {
+		if !strings.Contains(selection, label) {
+			t.Errorf("selection section lacks %q", label)
+		}
+		if !strings.Contains(document, label) {
+			t.Errorf("document section lacks %q", label)
+		}
+	}
+
+	// On a short panel the selection gives way so the document stats still show
+	rows = m.renderStatsPanel(40, 24)
+	text = stripAnsi(strings.Join(rows, "\n"))
+	if _, document, ok := strings.Cut(text, " Document"); !ok || !strings.Contains(document, "Reading time") {
+		t.Errorf("document stats pushed off a short panel:\n%s", text)
+	}
+}
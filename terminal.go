@@ -2,8 +2,13 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"strconv"
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // TerminalColorSupport represents the level of color support in the terminal
@@ -16,6 +21,30 @@ const (
 	TrueColor
 )
 
+// Color modes accepted by --color
+const (
+	colorModeNever  = "never"
+	colorModeAuto   = "auto"
+	colorModeAlways = "always"
+)
+
+// colorSupport is the color level used for rendering, chosen at startup
+var colorSupport = TrueColor
+
+// String names the color level for logs and the color test
+func (s TerminalColorSupport) String() string {
+	switch s {
+	case NoColor:
+		return "no color"
+	case Color16:
+		return "16 colors"
+	case Color256:
+		return "256 colors"
+	default:
+		return "true color (24-bit)"
+	}
+}
+
 // checkTerminalColors detects the terminal's color capabilities
 func checkTerminalColors() TerminalColorSupport {
 	// Check for NO_COLOR environment variable (universal disable)
@@ -23,7 +52,11 @@ func checkTerminalColors() TerminalColorSupport {
 		LogInfo("NO_COLOR environment variable detected, disabling colors")
 		return NoColor
 	}
+	return detectTerminalColors()
+}
 
+// detectTerminalColors works out the color level from COLORTERM and TERM
+func detectTerminalColors() TerminalColorSupport {
 	// Check COLORTERM for true color support
 	colorterm := os.Getenv("COLORTERM")
 	if colorterm == "truecolor" || colorterm == "24bit" {
@@ -57,6 +90,159 @@ func checkTerminalColors() TerminalColorSupport {
 	return Color16
 }
 
+// colorSupportForMode applies a --color mode: "never" turns colors off,
+// "always" ignores NO_COLOR, "auto" follows the terminal
+func colorSupportForMode(mode string) (TerminalColorSupport, error) {
+	switch mode {
+	case colorModeNever:
+		return NoColor, nil
+	case colorModeAlways:
+		return detectTerminalColors(), nil
+	case colorModeAuto, "":
+		return checkTerminalColors(), nil
+	}
+	return NoColor, fmt.Errorf("invalid --color %q (use never, auto or always)", mode)
+}
+
+// setColorSupport makes lipgloss render at the chosen color level
+func setColorSupport(support TerminalColorSupport) {
+	colorSupport = support
+	switch support {
+	case TrueColor:
+		lipgloss.SetColorProfile(termenv.TrueColor)
+	case Color256:
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	case Color16:
+		lipgloss.SetColorProfile(termenv.ANSI)
+	default:
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	LogInfof("Rendering with %s", support)
+}
+
+// monochrome reports whether colors are off, so styles need other cues
+// (reverse video, underline, bold) to stay readable
+func monochrome() bool {
+	return colorSupport == NoColor
+}
+
+// terminalColor converts a theme color to the nearest color the terminal can show
+func terminalColor(c Color, support TerminalColorSupport) lipgloss.Color {
+	switch support {
+	case TrueColor:
+		return lipgloss.Color(ColorToHex(c))
+	case Color256:
+		return lipgloss.Color(strconv.Itoa(nearestANSI256(c)))
+	case Color16:
+		return lipgloss.Color(strconv.Itoa(nearestANSI16(c)))
+	}
+	return lipgloss.Color("")
+}
+
+// ansi256Levels are the channel values of the 6x6x6 color cube (indexes 16-231)
+var ansi256Levels = [6]int{0, 95, 135, 175, 215, 255}
+
+// nearestANSI256 picks the closest color cube entry or gray ramp step (232-255)
+func nearestANSI256(c Color) int {
+	nearestLevel := func(v uint8) int {
+		best := 0
+		for i, level := range ansi256Levels {
+			if absInt(int(v)-level) < absInt(int(v)-ansi256Levels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	r, g, b := nearestLevel(c.R), nearestLevel(c.G), nearestLevel(c.B)
+	cube := Color{uint8(ansi256Levels[r]), uint8(ansi256Levels[g]), uint8(ansi256Levels[b])}
+	cubeIndex := 16 + 36*r + 6*g + b
+
+	// Gray ramp: 8, 18, ..., 238
+	avg := (int(c.R) + int(c.G) + int(c.B)) / 3
+	step := (avg - 8 + 5) / 10
+	if step < 0 {
+		step = 0
+	}
+	if step > 23 {
+		step = 23
+	}
+	grayLevel := uint8(8 + 10*step)
+	gray := Color{grayLevel, grayLevel, grayLevel}
+
+	if colorDistance(c, gray) < colorDistance(c, cube) {
+		return 232 + step
+	}
+	return cubeIndex
+}
+
+// nearestANSI16 maps a color to the basic palette by hue rather than by
+// distance, because the terminal decides what the 16 colors actually look like
+func nearestANSI16(c Color) int {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi := math.Max(r, math.Max(g, b))
+	lo := math.Min(r, math.Min(g, b))
+	lightness := (hi + lo) / 2
+
+	// Grays (including tinted backgrounds): black, bright black, white, bright white
+	if hi-lo < 0.2 {
+		switch {
+		case lightness < 0.2:
+			return 0
+		case lightness < 0.5:
+			return 8
+		case lightness < 0.8:
+			return 7
+		default:
+			return 15
+		}
+	}
+
+	var hue float64
+	switch hi {
+	case r:
+		hue = math.Mod((g-b)/(hi-lo), 6)
+	case g:
+		hue = (b-r)/(hi-lo) + 2
+	default:
+		hue = (r-g)/(hi-lo) + 4
+	}
+	hue *= 60
+	if hue < 0 {
+		hue += 360
+	}
+
+	// Hue ranges for red, yellow, green, cyan, blue and magenta. Cyan is
+	// narrow because most UI "blues" sit around 200-220 degrees.
+	base := 1
+	for _, sector := range []struct {
+		below float64
+		code  int
+	}{{20, 1}, {70, 3}, {160, 2}, {195, 6}, {270, 4}, {330, 5}} {
+		if hue < sector.below {
+			base = sector.code
+			break
+		}
+	}
+	if lightness > 0.6 {
+		return base + 8
+	}
+	return base
+}
+
+// colorDistance is the squared distance between two colors
+func colorDistance(a, b Color) int {
+	dr, dg, db := int(a.R)-int(b.R), int(a.G)-int(b.G), int(a.B)-int(b.B)
+	return dr*dr + dg*dg + db*db
+}
+
+// absInt returns the absolute value of an int
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
 // getTerminalInfo returns information about the current terminal
 func getTerminalInfo() string {
 	term := os.Getenv("TERM")
@@ -70,63 +256,70 @@ func getTerminalInfo() string {
 	return info
 }
 
-// printColorTest displays all Catppuccin Mocha colors for testing
-func printColorTest() {
-	println("=== Catppuccin Mocha Color Test ===")
-	println()
-	println(getTerminalInfo())
-	println()
-
-	// Accent colors
-	println("ACCENT COLORS:")
-	testColor("Rosewater", Rosewater)
-	testColor("Flamingo", Flamingo)
-	testColor("Pink", Pink)
-	testColor("Mauve", Mauve)
-	testColor("Red", Red)
-	testColor("Maroon", Maroon)
-	testColor("Peach", Peach)
-	testColor("Yellow", Yellow)
-	testColor("Green", Green)
-	testColor("Teal", Teal)
-	testColor("Sky", Sky)
-	testColor("Sapphire", Sapphire)
-	testColor("Blue", Blue)
-	testColor("Lavender", Lavender)
-	println()
-
-	// Monochromatic colors
-	println("MONOCHROMATIC COLORS:")
-	testColor("Text", Text)
-	testColor("Subtext1", Subtext1)
-	testColor("Subtext0", Subtext0)
-	testColor("Overlay2", Overlay2)
-	testColor("Overlay1", Overlay1)
-	testColor("Overlay0", Overlay0)
-	testColor("Surface2", Surface2)
-	testColor("Surface1", Surface1)
-	testColor("Surface0", Surface0)
-	testColor("Base", Base)
-	testColor("Mantle", Mantle)
-	testColor("Crust", Crust)
-	println()
-
-	println("If colors appear incorrect or monochrome, your terminal may not")
-	println("support 24-bit true color. See TERMINAL_SETUP.md for configuration help.")
+// printColorTest shows the active theme's palette and roles at the detected color level
+func printColorTest(theme *Theme, support TerminalColorSupport) {
+	fmt.Printf("=== %s Color Test ===\n\n", theme.Name)
+	fmt.Println(getTerminalInfo())
+	fmt.Printf("Rendering with: %s\n\n", support)
+
+	fmt.Println("PALETTE:")
+	slots := theme.palette.slots()
+	for _, name := range []string{"bg", "bg_alt", "surface", "surface_alt", "muted", "fg", "fg_alt", "red", "maroon", "yellow", "green", "blue", "mauve"} {
+		testColor(name, *slots[name], support)
+	}
+	fmt.Println()
+
+	fmt.Println("ROLES:")
+	for _, r := range themeRoles {
+		testColor(r.name, theme.color(r.name), support)
+	}
+	fmt.Println()
+
+	if support == NoColor {
+		fmt.Println("Colors are off (--color=never or NO_COLOR); the editor uses reverse video,")
+		fmt.Println("underline and bold instead.")
+		return
+	}
+	fmt.Println("If colors appear incorrect or monochrome, your terminal may not")
+	fmt.Println("support 24-bit true color. See TERMINAL_SETUP.md for configuration help.")
 }
 
 // testColor displays a single color test
-func testColor(name string, color Color) {
-	// Foreground color
-	fg := ColorToANSI(color, true)
-	// Background color
-	bg := ColorToANSI(color, false)
-	// Reset
+func testColor(name string, color Color, support TerminalColorSupport) {
+	fg := colorEscape(color, support, true)
+	bg := colorEscape(color, support, false)
 	reset := "\033[0m"
+	if support == NoColor {
+		reset = ""
+	}
 
 	// Display: colored text on default bg, and colored background with contrasting text
-	fmt.Printf("  %s%-12s%s  %s  %s  %s #%02x%02x%02x\n",
+	fmt.Printf("  %s%-20s%s  %s  %s  %s #%02x%02x%02x\n",
 		fg, name, reset,
 		bg, "    ", reset,
 		color.R, color.G, color.B)
 }
+
+// colorEscape returns the escape sequence that sets a color at the given level
+func colorEscape(c Color, support TerminalColorSupport, foreground bool) string {
+	switch support {
+	case TrueColor:
+		return ColorToANSI(c, foreground)
+	case Color256:
+		if foreground {
+			return fmt.Sprintf("\x1b[38;5;%dm", nearestANSI256(c))
+		}
+		return fmt.Sprintf("\x1b[48;5;%dm", nearestANSI256(c))
+	case Color16:
+		code := nearestANSI16(c)
+		offset := 30
+		if code >= 8 {
+			offset = 90 - 8
+		}
+		if !foreground {
+			offset += 10
+		}
+		return fmt.Sprintf("\x1b[%dm", offset+code)
+	}
+	return ""
+}
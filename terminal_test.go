@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestNearestANSI256(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want int
+	}{
+		{Color{0, 0, 0}, 16},           // cube black
+		{Color{255, 255, 255}, 231},    // cube white
+		{Color{255, 0, 0}, 196},        // pure red
+		{Color{128, 128, 128}, 244},    // gray ramp
+		{Color{0x1e, 0x1e, 0x2e}, 235}, // mocha base stays a dark gray
+		{Color{95, 135, 175}, 67},      // exact cube entry
+	}
+	for _, tt := range tests {
+		if got := nearestANSI256(tt.c); got != tt.want {
+			t.Errorf("nearestANSI256(%v) = %d, want %d", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestNearestANSI16(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want int
+	}{
+		{Base, 0},                 // dark tinted background -> black
+		{Surface1, 8},             // mid-dark gray
+		{Text, 15},                // light text
+		{Red, 9},                  // pastel red -> bright red
+		{Green, 10},               // pastel green -> bright green
+		{Blue, 12},                // pastel blue -> bright blue
+		{Color{0xb5, 0x89, 0}, 3}, // solarized yellow -> yellow
+		{Color{0x26, 0x8b, 0xd2}, 4},
+	}
+	for _, tt := range tests {
+		if got := nearestANSI16(tt.c); got != tt.want {
+			t.Errorf("nearestANSI16(%v) = %d, want %d", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestTerminalColor(t *testing.T) {
+	tests := []struct {
+		support TerminalColorSupport
+		want    lipgloss.Color
+	}{
+		{TrueColor, "#f38ba8"},
+		{Color256, "211"},
+		{Color16, "9"},
+		{NoColor, ""},
+	}
+	for _, tt := range tests {
+		if got := terminalColor(Red, tt.support); got != tt.want {
+			t.Errorf("terminalColor(Red, %s) = %q, want %q", tt.support, got, tt.want)
+		}
+	}
+}
+
+func TestColorSupportForMode(t *testing.T) {
+	t.Setenv("COLORTERM", "truecolor")
+	t.Setenv("NO_COLOR", "1")
+
+	tests := []struct {
+		mode string
+		want TerminalColorSupport
+	}{
+		{colorModeNever, NoColor},
+		{colorModeAuto, NoColor},     // NO_COLOR wins
+		{colorModeAlways, TrueColor}, // --color=always overrides NO_COLOR
+	}
+	for _, tt := range tests {
+		got, err := colorSupportForMode(tt.mode)
+		if err != nil || got != tt.want {
+			t.Errorf("colorSupportForMode(%q) = %s, %v; want %s", tt.mode, got, err, tt.want)
+		}
+	}
+	if _, err := colorSupportForMode("sometimes"); err == nil {
+		t.Error("an unknown mode should be rejected")
+	}
+}
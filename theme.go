@@ -0,0 +1,407 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// themeAuto picks theme_dark or theme_light from the terminal background
+const themeAuto = "auto"
+
+// themesDir holds user theme files (<name>.toml) inside the config directory
+const themesDir = "themes"
+
+// terminalDarkBackground is what the terminal reported at startup
+var terminalDarkBackground = true
+
+// themePalette holds the handful of colors a theme is built from
+type themePalette struct {
+	Bg         Color // editor background
+	BgAlt      Color // message line and pane background
+	Surface    Color // status bar and selected list rows
+	SurfaceAlt Color // pane titles
+	Muted      Color // dimmed text
+	Fg         Color // main text
+	FgAlt      Color // secondary text
+	Red        Color
+	Maroon     Color
+	Yellow     Color
+	Green      Color
+	Blue       Color
+	Mauve      Color
+}
+
+// slots maps the palette names used in theme files to the palette colors
+func (p *themePalette) slots() map[string]*Color {
+	return map[string]*Color{
+		"bg":          &p.Bg,
+		"bg_alt":      &p.BgAlt,
+		"surface":     &p.Surface,
+		"surface_alt": &p.SurfaceAlt,
+		"muted":       &p.Muted,
+		"fg":          &p.Fg,
+		"fg_alt":      &p.FgAlt,
+		"red":         &p.Red,
+		"maroon":      &p.Maroon,
+		"yellow":      &p.Yellow,
+		"green":       &p.Green,
+		"blue":        &p.Blue,
+		"mauve":       &p.Mauve,
+	}
+}
+
+// themeRoles lists the semantic roles and the palette color each uses by default
+var themeRoles = []struct {
+	name string
+	slot string
+}{
+	{"editor.bg", "bg"},
+	{"editor.fg", "fg"},
+	{"editor.dim", "muted"},
+	{"cursor.bg", "maroon"},
+	{"cursor.fg", "bg"},
+	{"selection.bg", "blue"},
+	{"selection.fg", "bg"},
+	{"spell.error.bg", "red"},
+	{"spell.error.fg", "bg"},
+	{"lint.warning", "yellow"},
+	{"status.bg", "surface"},
+	{"status.fg", "fg"},
+	{"status.mode.read", "blue"},
+	{"status.mode.edit", "green"},
+	{"message.bg", "bg_alt"},
+	{"message.fg", "fg"},
+	{"message.success", "green"},
+	{"message.warning", "yellow"},
+	{"message.error", "red"},
+	{"tree.bg", "bg"},
+	{"tree.dir", "yellow"},
+	{"tree.file", "blue"},
+	{"pane.title.bg", "surface_alt"},
+	{"pane.title.fg", "fg"},
+	{"pane.bg", "bg_alt"},
+	{"pane.fg", "fg_alt"},
+	{"pane.selected.bg", "surface"},
+	{"pane.selected.fg", "yellow"},
+	{"thesaurus.selected", "green"},
+	{"stats.heading", "mauve"},
+	{"stats.fg", "fg_alt"},
+}
+
+// Theme maps semantic roles to colors
+type Theme struct {
+	Name      string
+	Dark      bool
+	palette   themePalette
+	roles     map[string]Color
+	overrides map[string]string // role -> palette slot or #rrggbb, from theme files
+}
+
+// newTheme builds a theme whose roles all come from a palette
+func newTheme(name string, dark bool, p themePalette) *Theme {
+	slots := p.slots()
+	t := &Theme{Name: name, Dark: dark, palette: p, roles: make(map[string]Color, len(themeRoles))}
+	for _, r := range themeRoles {
+		t.roles[r.name] = *slots[r.slot]
+	}
+	return t
+}
+
+// color returns the color of a role (the main text color for unknown roles)
+func (t *Theme) color(role string) Color {
+	if c, ok := t.roles[role]; ok {
+		return c
+	}
+	return t.roles["editor.fg"]
+}
+
+// builtinThemes are the themes that ship with the editor
+var builtinThemes = map[string]func() *Theme{
+	"mocha": func() *Theme {
+		return newTheme("mocha", true, themePalette{
+			Bg: Base, BgAlt: Mantle, Surface: Surface0, SurfaceAlt: Surface1, Muted: Overlay0,
+			Fg: Text, FgAlt: Subtext1,
+			Red: Red, Maroon: Maroon, Yellow: Yellow, Green: Green, Blue: Blue, Mauve: Mauve,
+		})
+	},
+	"macchiato": func() *Theme {
+		return newTheme("macchiato", true, themePalette{
+			Bg: hexColor("#24273a"), BgAlt: hexColor("#1e2030"), Surface: hexColor("#363a4f"), SurfaceAlt: hexColor("#494d64"), Muted: hexColor("#6e738d"),
+			Fg: hexColor("#cad3f5"), FgAlt: hexColor("#b8c0e0"),
+			Red: hexColor("#ed8796"), Maroon: hexColor("#ee99a0"), Yellow: hexColor("#eed49f"), Green: hexColor("#a6da95"), Blue: hexColor("#8aadf4"), Mauve: hexColor("#c6a0f6"),
+		})
+	},
+	"frappe": func() *Theme {
+		return newTheme("frappe", true, themePalette{
+			Bg: hexColor("#303446"), BgAlt: hexColor("#292c3c"), Surface: hexColor("#414559"), SurfaceAlt: hexColor("#51576d"), Muted: hexColor("#737994"),
+			Fg: hexColor("#c6d0f5"), FgAlt: hexColor("#b5bfe2"),
+			Red: hexColor("#e78284"), Maroon: hexColor("#ea999c"), Yellow: hexColor("#e5c890"), Green: hexColor("#a6d189"), Blue: hexColor("#8caaee"), Mauve: hexColor("#ca9ee6"),
+		})
+	},
+	"latte": func() *Theme {
+		return newTheme("latte", false, themePalette{
+			Bg: hexColor("#eff1f5"), BgAlt: hexColor("#e6e9ef"), Surface: hexColor("#ccd0da"), SurfaceAlt: hexColor("#bcc0cc"), Muted: hexColor("#9ca0b0"),
+			Fg: hexColor("#4c4f69"), FgAlt: hexColor("#5c5f77"),
+			Red: hexColor("#d20f39"), Maroon: hexColor("#e64553"), Yellow: hexColor("#df8e1d"), Green: hexColor("#40a02b"), Blue: hexColor("#1e66f5"), Mauve: hexColor("#8839ef"),
+		})
+	},
+	"solarized-dark": func() *Theme {
+		return newTheme("solarized-dark", true, themePalette{
+			Bg: hexColor("#002b36"), BgAlt: hexColor("#073642"), Surface: hexColor("#073642"), SurfaceAlt: hexColor("#586e75"), Muted: hexColor("#586e75"),
+			Fg: hexColor("#839496"), FgAlt: hexColor("#93a1a1"),
+			Red: hexColor("#dc322f"), Maroon: hexColor("#cb4b16"), Yellow: hexColor("#b58900"), Green: hexColor("#859900"), Blue: hexColor("#268bd2"), Mauve: hexColor("#6c71c4"),
+		})
+	},
+	"solarized-light": func() *Theme {
+		return newTheme("solarized-light", false, themePalette{
+			Bg: hexColor("#fdf6e3"), BgAlt: hexColor("#eee8d5"), Surface: hexColor("#eee8d5"), SurfaceAlt: hexColor("#93a1a1"), Muted: hexColor("#93a1a1"),
+			Fg: hexColor("#657b83"), FgAlt: hexColor("#586e75"),
+			Red: hexColor("#dc322f"), Maroon: hexColor("#cb4b16"), Yellow: hexColor("#b58900"), Green: hexColor("#859900"), Blue: hexColor("#268bd2"), Mauve: hexColor("#6c71c4"),
+		})
+	},
+	"gruvbox-dark": func() *Theme {
+		return newTheme("gruvbox-dark", true, themePalette{
+			Bg: hexColor("#282828"), BgAlt: hexColor("#1d2021"), Surface: hexColor("#3c3836"), SurfaceAlt: hexColor("#504945"), Muted: hexColor("#928374"),
+			Fg: hexColor("#ebdbb2"), FgAlt: hexColor("#d5c4a1"),
+			Red: hexColor("#fb4934"), Maroon: hexColor("#fe8019"), Yellow: hexColor("#fabd2f"), Green: hexColor("#b8bb26"), Blue: hexColor("#83a598"), Mauve: hexColor("#d3869b"),
+		})
+	},
+	"gruvbox-light": func() *Theme {
+		return newTheme("gruvbox-light", false, themePalette{
+			Bg: hexColor("#fbf1c7"), BgAlt: hexColor("#f2e5bc"), Surface: hexColor("#ebdbb2"), SurfaceAlt: hexColor("#d5c4a1"), Muted: hexColor("#928374"),
+			Fg: hexColor("#3c3836"), FgAlt: hexColor("#504945"),
+			Red: hexColor("#9d0006"), Maroon: hexColor("#af3a03"), Yellow: hexColor("#b57614"), Green: hexColor("#79740e"), Blue: hexColor("#076678"), Mauve: hexColor("#8f3f71"),
+		})
+	},
+	"high-contrast": func() *Theme {
+		return newTheme("high-contrast", true, themePalette{
+			Bg: hexColor("#000000"), BgAlt: hexColor("#000000"), Surface: hexColor("#303030"), SurfaceAlt: hexColor("#5f5f5f"), Muted: hexColor("#a8a8a8"),
+			Fg: hexColor("#ffffff"), FgAlt: hexColor("#ffffff"),
+			Red: hexColor("#ff5f5f"), Maroon: hexColor("#ff8700"), Yellow: hexColor("#ffff00"), Green: hexColor("#00ff00"), Blue: hexColor("#00d7ff"), Mauve: hexColor("#ff87ff"),
+		})
+	},
+}
+
+// defaultTheme is used before the config is applied
+const defaultTheme = "mocha"
+
+// hexColor parses a color known to be valid (for the built-in themes)
+func hexColor(s string) Color {
+	c, err := parseHexColor(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// parseHexColor parses "#rrggbb" (or "#rgb")
+func parseHexColor(s string) (Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 || !strings.HasPrefix(strings.TrimSpace(s), "#") {
+		return Color{}, fmt.Errorf("invalid color %q (use #rrggbb)", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q (use #rrggbb)", s)
+	}
+	return Color{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// themeFile is the TOML layout of a user theme
+type themeFile struct {
+	Inherits string            `toml:"inherits"` // built-in or user theme to start from
+	Dark     *bool             `toml:"dark"`     // defaults to the inherited theme's value
+	Palette  map[string]string `toml:"palette"`  // palette slot -> #rrggbb
+	Roles    map[string]string `toml:"roles"`    // role -> #rrggbb or palette slot
+}
+
+// userThemePath returns where a user theme of that name would live
+func userThemePath(name string) (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, themesDir, name+".toml"), nil
+}
+
+// loadTheme returns a built-in theme or reads a user theme file
+func loadTheme(name string) (*Theme, error) {
+	return loadThemeDepth(strings.ToLower(name), 0)
+}
+
+// loadThemeDepth loads a theme, following at most a few levels of inheritance
+func loadThemeDepth(name string, depth int) (*Theme, error) {
+	path, err := userThemePath(name)
+	if err == nil {
+		if _, statErr := os.Stat(path); statErr == nil {
+			if depth > 4 {
+				return nil, fmt.Errorf("theme %s: inheritance is too deep", name)
+			}
+			return loadThemeFile(name, path, depth)
+		}
+	}
+	if build, ok := builtinThemes[name]; ok {
+		return build(), nil
+	}
+	return nil, fmt.Errorf("unknown theme %q (choose from %s)", name, strings.Join(availableThemes(), ", "))
+}
+
+// loadThemeFile reads a user theme, layering it over the theme it inherits
+func loadThemeFile(name string, path string, depth int) (*Theme, error) {
+	var tf themeFile
+	md, err := toml.DecodeFile(path, &tf)
+	if err != nil {
+		return nil, fmt.Errorf("theme %s: %v", name, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("theme %s: unknown key %q", name, undecoded[0].String())
+	}
+
+	base := defaultTheme
+	if tf.Inherits != "" {
+		base = strings.ToLower(tf.Inherits)
+	}
+	if base == name {
+		return nil, fmt.Errorf("theme %s: cannot inherit from itself", name)
+	}
+	parent, err := loadThemeDepth(base, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("theme %s: %v", name, err)
+	}
+
+	palette := parent.palette
+	slots := palette.slots()
+	for slot, value := range tf.Palette {
+		target, ok := slots[slot]
+		if !ok {
+			return nil, fmt.Errorf("theme %s: unknown palette color %q", name, slot)
+		}
+		c, err := parseHexColor(value)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: %s: %v", name, slot, err)
+		}
+		*target = c
+	}
+
+	dark := parent.Dark
+	if tf.Dark != nil {
+		dark = *tf.Dark
+	}
+	t := newTheme(name, dark, palette)
+
+	// Role overrides are inherited too, and resolved against this palette
+	overrides := make(map[string]string, len(parent.overrides)+len(tf.Roles))
+	for role, value := range parent.overrides {
+		overrides[role] = value
+	}
+	for role, value := range tf.Roles {
+		overrides[role] = value
+	}
+	if err := t.override(overrides); err != nil {
+		return nil, fmt.Errorf("theme %s: %v", name, err)
+	}
+	return t, nil
+}
+
+// override sets roles to a palette slot name or a #rrggbb color
+func (t *Theme) override(overrides map[string]string) error {
+	slots := t.palette.slots()
+	for role, value := range overrides {
+		if _, ok := t.roles[role]; !ok {
+			return fmt.Errorf("unknown role %q", role)
+		}
+		if slot, ok := slots[value]; ok {
+			t.roles[role] = *slot
+			continue
+		}
+		c, err := parseHexColor(value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", role, err)
+		}
+		t.roles[role] = c
+	}
+	t.overrides = overrides
+	return nil
+}
+
+// availableThemes lists the built-in themes and the user theme files, sorted
+func availableThemes() []string {
+	seen := make(map[string]bool)
+	for name := range builtinThemes {
+		seen[name] = true
+	}
+	if configDir, err := getConfigDir(); err == nil {
+		files, _ := filepath.Glob(filepath.Join(configDir, themesDir, "*.toml"))
+		for _, f := range files {
+			seen[strings.TrimSuffix(filepath.Base(f), ".toml")] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// themeName resolves "auto" to the light or dark theme for this terminal
+func (c Config) themeName() string {
+	if c.Theme != themeAuto {
+		return c.Theme
+	}
+	if terminalDarkBackground {
+		return c.ThemeDark
+	}
+	return c.ThemeLight
+}
+
+// fallbackTheme is used by models built without a config (e.g. in tests)
+var fallbackTheme *Theme
+
+// activeTheme returns the configured theme, or the default one
+func (m model) activeTheme() *Theme {
+	if m.theme != nil {
+		return m.theme
+	}
+	if fallbackTheme == nil {
+		fallbackTheme = builtinThemes[defaultTheme]()
+	}
+	return fallbackTheme
+}
+
+// themeColor returns the color of a semantic role in the active theme, reduced
+// to what the terminal can show
+func (m model) themeColor(role string) lipgloss.Color {
+	return terminalColor(m.activeTheme().color(role), colorSupport)
+}
+
+// handleThemeCommand handles ":theme" (list) and ":theme <name>"
+func (m model) handleThemeCommand(args []string) (tea.Model, tea.Cmd) {
+	if len(args) == 0 {
+		current := m.activeTheme().Name
+		var lines []string
+		for _, name := range availableThemes() {
+			marker := "  "
+			if name == current {
+				marker = "* "
+			}
+			lines = append(lines, marker+name)
+		}
+		lines = append(lines, "", "Theme files: "+filepath.Join("<config dir>", themesDir, "<name>.toml"))
+		m.openInfoPane("Themes (:theme name, :theme auto)", lines)
+		return m, nil
+	}
+	return m.handleSetCommand([]string{"theme=" + args[0]})
+}
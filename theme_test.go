@@ -0,0 +1,187 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Color
+		ok   bool
+	}{
+		{"#1e1e2e", Base, true},
+		{"#fff", Color{255, 255, 255}, true},
+		{"1e1e2e", Color{}, false},
+		{"#12345", Color{}, false},
+		{"#gggggg", Color{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseHexColor(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseHexColor(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestBuiltinThemesDefineEveryRole(t *testing.T) {
+	for name, build := range builtinThemes {
+		theme := build()
+		if theme.Name != name {
+			t.Errorf("theme %s calls itself %s", name, theme.Name)
+		}
+		for _, r := range themeRoles {
+			if _, ok := theme.roles[r.name]; !ok {
+				t.Errorf("theme %s has no %s color", name, r.name)
+			}
+		}
+	}
+	if builtinThemes["latte"]().Dark || !builtinThemes["mocha"]().Dark {
+		t.Error("latte is light and mocha is dark")
+	}
+}
+
+// TestRenderersUseKnownRoles catches misspelled role names, which would
+// silently fall back to the text color
+func TestRenderersUseKnownRoles(t *testing.T) {
+	known := make(map[string]bool)
+	for _, r := range themeRoles {
+		known[r.name] = true
+	}
+
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := regexp.MustCompile(`themeColor\("([^"]+)"\)`)
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range call.FindAllStringSubmatch(string(src), -1) {
+			if !known[match[1]] {
+				t.Errorf("%s uses unknown role %q", f, match[1])
+			}
+		}
+	}
+}
+
+func TestThemeAuto(t *testing.T) {
+	cfg := defaultConfig()
+	defer func(dark bool) { terminalDarkBackground = dark }(terminalDarkBackground)
+
+	terminalDarkBackground = true
+	if got := cfg.themeName(); got != "mocha" {
+		t.Errorf("dark terminal: %s", got)
+	}
+	terminalDarkBackground = false
+	if got := cfg.themeName(); got != "latte" {
+		t.Errorf("light terminal: %s", got)
+	}
+	cfg.Theme = "gruvbox-dark"
+	if got := cfg.themeName(); got != "gruvbox-dark" {
+		t.Errorf("explicit theme: %s", got)
+	}
+}
+
+func TestUserThemeFile(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("config directory is only redirected through HOME on Linux")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".config", "tuiwrite", themesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name+".toml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("paper", `
+inherits = "latte"
+
+[palette]
+bg = "#ffffff"
+
+[roles]
+"selection.bg" = "yellow"
+"tree.dir" = "#123456"
+`)
+	write("paper-ink", `
+inherits = "paper"
+dark = true
+
+[palette]
+yellow = "#aabbcc"
+`)
+	write("broken", `
+[roles]
+"editor.sparkle" = "#ffffff"
+`)
+
+	paper, err := loadTheme("paper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	latte := builtinThemes["latte"]()
+	if paper.Dark || paper.color("editor.bg") != (Color{255, 255, 255}) {
+		t.Errorf("palette override: dark=%v bg=%v", paper.Dark, paper.color("editor.bg"))
+	}
+	if paper.color("selection.bg") != latte.color("lint.warning") {
+		t.Errorf("role set to a palette color: %v", paper.color("selection.bg"))
+	}
+	if paper.color("tree.dir") != (Color{0x12, 0x34, 0x56}) {
+		t.Errorf("role set to a hex color: %v", paper.color("tree.dir"))
+	}
+
+	ink, err := loadTheme("paper-ink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ink.Dark || ink.color("editor.bg") != (Color{255, 255, 255}) {
+		t.Errorf("inherited theme: dark=%v bg=%v", ink.Dark, ink.color("editor.bg"))
+	}
+	if ink.color("selection.bg") != (Color{0xaa, 0xbb, 0xcc}) {
+		t.Errorf("inherited role override should follow the new palette: %v", ink.color("selection.bg"))
+	}
+
+	if _, err := loadTheme("broken"); err == nil || !strings.Contains(err.Error(), "editor.sparkle") {
+		t.Errorf("unknown role: %v", err)
+	}
+	if !containsString(availableThemes(), "paper-ink") {
+		t.Errorf("availableThemes() = %v", availableThemes())
+	}
+}
+
+func TestThemeCommand(t *testing.T) {
+	m := model{
+		lines:     []string{"text"},
+		wrapCache: make(map[int][]wrappedLine),
+		config:    defaultConfig(),
+		width:     100,
+		height:    30,
+	}
+	result, _ := m.executeCommand(":theme solarized-light")
+	m = result.(model)
+	if m.config.Theme != "solarized-light" || m.activeTheme().Name != "solarized-light" {
+		t.Errorf("theme = %s (%s), status %q", m.config.Theme, m.activeTheme().Name, m.statusMsg.Text)
+	}
+
+	result, _ = m.executeCommand(":theme neon")
+	m = result.(model)
+	if m.activeTheme().Name != "solarized-light" || !strings.Contains(m.statusMsg.Text, "unknown theme") {
+		t.Errorf("unknown theme should be refused: %s, status %q", m.activeTheme().Name, m.statusMsg.Text)
+	}
+}
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// thesaurusHeight is the number of rows used by the thesaurus pane (including its title)
+const thesaurusHeight = 10
+
+// Thesaurus looks words up in an OpenOffice/LibreOffice th_*.dat file
+// (the MyThes format), using the matching .idx file when one exists
+type Thesaurus struct {
+	path     string           // path to the .dat file
+	language string           // spell-check language it was chosen for
+	index    map[string]int64 // lowercase word -> byte offset of its entry
+}
+
+// thesaurusMeaning is one sense of a word
+type thesaurusMeaning struct {
+	partOfSpeech string // e.g. "(noun)"
+	synonyms     []string
+	antonyms     []string
+}
+
+// thesaurusItem is a row in the thesaurus pane
+type thesaurusItem struct {
+	word         string
+	partOfSpeech string
+	antonym      bool
+}
+
+// findThesaurusFile locates the th_*.dat file for a spell-check language in
+// the dictionary directory, e.g. th_en_GB_v2.dat for "uk"
+func findThesaurusFile(lang string) (string, error) {
+	dictInfo, exists := availableDicts[lang]
+	if !exists {
+		return "", fmt.Errorf("no thesaurus for language '%s'", lang)
+	}
+
+	dictPath, err := getDictPath()
+	if err != nil {
+		return "", err
+	}
+
+	// Prefer the exact locale (en_GB), then any file for the language (en_*)
+	locale := strings.TrimSuffix(dictInfo.affFile, ".aff")
+	patterns := []string{
+		"th_" + locale + ".dat",
+		"th_" + locale + "_*.dat",
+		"th_" + strings.SplitN(locale, "_", 2)[0] + "_*.dat",
+	}
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(filepath.Join(dictPath, pattern))
+		if len(matches) > 0 {
+			sort.Strings(matches)
+			return matches[len(matches)-1], nil // Highest version wins
+		}
+	}
+
+	return "", fmt.Errorf("no thesaurus found for %s (place th_%s_v2.dat in %s)", strings.ToUpper(lang), locale, dictPath)
+}
+
+// loadThesaurus opens a thesaurus and builds its word index
+func loadThesaurus(path string) (*Thesaurus, error) {
+	t := &Thesaurus{path: path}
+
+	idxPath := strings.TrimSuffix(path, ".dat") + ".idx"
+	if index, err := readThesaurusIndex(idxPath); err == nil {
+		t.index = index
+	} else {
+		index, err := scanThesaurusIndex(path)
+		if err != nil {
+			return nil, err
+		}
+		t.index = index
+	}
+
+	LogInfof("Loaded thesaurus %s (%d words)", filepath.Base(path), len(t.index))
+	return t, nil
+}
+
+// readThesaurusIndex reads a MyThes .idx file: encoding, count, then "word|offset" lines
+func readThesaurusIndex(path string) (map[string]int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	index := make(map[string]int64)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if lineNum <= 2 {
+			continue // Encoding and entry count
+		}
+		word, offset, found := strings.Cut(scanner.Text(), "|")
+		if !found {
+			continue
+		}
+		n, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: bad offset", filepath.Base(path), lineNum)
+		}
+		index[strings.ToLower(word)] = n
+	}
+	return index, scanner.Err()
+}
+
+// scanThesaurusIndex builds an index by reading the whole .dat file
+func scanThesaurusIndex(path string) (map[string]int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	index := make(map[string]int64)
+	reader := bufio.NewReader(file)
+	var offset int64
+
+	// First line is the encoding
+	first, err := reader.ReadString('\n')
+	if err != nil {
+		return index, nil
+	}
+	offset += int64(len(first))
+
+	for {
+		header, err := reader.ReadString('\n')
+		if header == "" && err != nil {
+			break
+		}
+		entryOffset := offset
+		offset += int64(len(header))
+
+		word, count, found := strings.Cut(strings.TrimRight(header, "\r\n"), "|")
+		n, convErr := strconv.Atoi(count)
+		if !found || convErr != nil {
+			continue
+		}
+		index[strings.ToLower(word)] = entryOffset
+
+		// Skip the meaning lines
+		for i := 0; i < n; i++ {
+			line, err := reader.ReadString('\n')
+			offset += int64(len(line))
+			if err != nil {
+				break
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return index, nil
+}
+
+// lookup returns the meanings of a word, or nil if it isn't in the thesaurus
+func (t *Thesaurus) lookup(word string) ([]thesaurusMeaning, error) {
+	offset, ok := t.index[strings.ToLower(word)]
+	if !ok {
+		return nil, nil
+	}
+
+	file, err := os.Open(t.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return parseThesaurusEntry(bufio.NewReader(file))
+}
+
+// parseThesaurusEntry reads "word|count" followed by count meaning lines of the
+// form "(pos)|syn1|syn2 (similar term)|opposite (antonym)"
+func parseThesaurusEntry(reader *bufio.Reader) ([]thesaurusMeaning, error) {
+	header, err := reader.ReadString('\n')
+	if err != nil && header == "" {
+		return nil, err
+	}
+	_, count, found := strings.Cut(strings.TrimRight(header, "\r\n"), "|")
+	n, convErr := strconv.Atoi(count)
+	if !found || convErr != nil {
+		return nil, fmt.Errorf("malformed thesaurus entry %q", strings.TrimSpace(header))
+	}
+
+	var meanings []thesaurusMeaning
+	for i := 0; i < n; i++ {
+		line, err := reader.ReadString('\n')
+		fields := strings.Split(strings.TrimRight(line, "\r\n"), "|")
+		if len(fields) > 1 {
+			meaning := thesaurusMeaning{partOfSpeech: fields[0]}
+			for _, field := range fields[1:] {
+				term, note := splitThesaurusNote(field)
+				if term == "" {
+					continue
+				}
+				if note == "antonym" {
+					meaning.antonyms = append(meaning.antonyms, term)
+				} else {
+					meaning.synonyms = append(meaning.synonyms, term)
+				}
+			}
+			meanings = append(meanings, meaning)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	return meanings, nil
+}
+
+// splitThesaurusNote separates "small (antonym)" into "small" and "antonym"
+func splitThesaurusNote(field string) (string, string) {
+	field = strings.TrimSpace(field)
+	if strings.HasSuffix(field, ")") {
+		if open := strings.LastIndex(field, " ("); open >= 0 {
+			return strings.TrimSpace(field[:open]), field[open+2 : len(field)-1]
+		}
+	}
+	return field, ""
+}
+
+// thesaurusItems flattens meanings into pane rows: synonyms first, then antonyms
+func thesaurusItems(word string, meanings []thesaurusMeaning) []thesaurusItem {
+	var items []thesaurusItem
+	seen := map[string]bool{strings.ToLower(word): true}
+
+	for _, antonyms := range []bool{false, true} {
+		for _, meaning := range meanings {
+			terms := meaning.synonyms
+			if antonyms {
+				terms = meaning.antonyms
+			}
+			for _, term := range terms {
+				key := strings.ToLower(term)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				items = append(items, thesaurusItem{word: term, partOfSpeech: meaning.partOfSpeech, antonym: antonyms})
+			}
+		}
+	}
+	return items
+}
+
+// matchCase gives replacement the capitalisation of original ("Big" -> "Large")
+func matchCase(original string, replacement string) string {
+	first, _ := utf8.DecodeRuneInString(original)
+	if original == strings.ToUpper(original) && utf8.RuneCountInString(original) > 1 {
+		return strings.ToUpper(replacement)
+	}
+	if unicode.IsUpper(first) {
+		r, size := utf8.DecodeRuneInString(replacement)
+		return string(unicode.ToUpper(r)) + replacement[size:]
+	}
+	return replacement
+}
+
+// countWord counts case-insensitive occurrences of a word in the document
+func countWord(lines []string, word string) int {
+	count := 0
+	for _, line := range lines {
+		for _, w := range getWordsInLine(line) {
+			if strings.EqualFold(w.word, word) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// openThesaurus looks up the word under the cursor and shows the thesaurus pane
+func (m *model) openThesaurus() {
+	w, ok := m.wordAtCursor()
+	if !ok {
+		m.setStatus("No word under cursor", "yellow")
+		return
+	}
+
+	lang := m.spellLanguage()
+	if m.thesaurus == nil || m.thesaurus.language != lang {
+		path, err := findThesaurusFile(lang)
+		if err != nil {
+			m.setStatus(err.Error(), "red")
+			return
+		}
+		thesaurus, err := loadThesaurus(path)
+		if err != nil {
+			m.setStatus("Failed to load thesaurus: "+err.Error(), "red")
+			return
+		}
+		thesaurus.language = lang
+		m.thesaurus = thesaurus
+	}
+
+	meanings, err := m.thesaurus.lookup(w.word)
+	if err != nil {
+		m.setStatus("Thesaurus error: "+err.Error(), "red")
+		return
+	}
+	items := thesaurusItems(w.word, meanings)
+	if len(items) == 0 {
+		m.setStatus("No synonyms found for \""+w.word+"\"", "yellow")
+		return
+	}
+
+	m.thesaurusWord = w
+	m.thesaurusLine = m.cursorY
+	m.thesaurusItems = items
+	m.thesaurusCursor = 0
+	m.thesaurusOffset = 0
+	m.thesaurusVisible = true
+	m.thesaurusUses = countWord(m.lines, w.word)
+	m.adjustViewport()
+	LogEvent("THESAURUS", fmt.Sprintf("Looked up %q (%d results)", w.word, len(items)))
+}
+
+// thesaurusPaneHeight returns the rows taken by the thesaurus pane (0 when hidden)
+func (m model) thesaurusPaneHeight() int {
+	if !m.thesaurusVisible {
+		return 0
+	}
+	return thesaurusHeight
+}
+
+// handleThesaurusKeys handles keys while the thesaurus pane is open
+func (m model) handleThesaurusKeys(key string) (tea.Model, tea.Cmd) {
+	listHeight := thesaurusHeight - 1
+
+	switch key {
+	case "up", "k":
+		if m.thesaurusCursor > 0 {
+			m.thesaurusCursor--
+			if m.thesaurusCursor < m.thesaurusOffset {
+				m.thesaurusOffset = m.thesaurusCursor
+			}
+		}
+
+	case "down", "j":
+		if m.thesaurusCursor < len(m.thesaurusItems)-1 {
+			m.thesaurusCursor++
+			if m.thesaurusCursor >= m.thesaurusOffset+listHeight {
+				m.thesaurusOffset = m.thesaurusCursor - listHeight + 1
+			}
+		}
+
+	case "enter":
+		m.replaceWithThesaurusItem(m.thesaurusItems[m.thesaurusCursor])
+		m.thesaurusVisible = false
+		m.adjustViewport()
+
+	case "q":
+		m.thesaurusVisible = false
+		m.adjustViewport()
+	}
+
+	return m, nil
+}
+
+// replaceWithThesaurusItem swaps the looked-up word for the chosen alternative
+func (m *model) replaceWithThesaurusItem(item thesaurusItem) {
+	if m.readOnly {
+		m.setStatus("Can't edit: "+errReadOnly.Error(), "yellow")
+		return
+	}
+	w := m.thesaurusWord
+	y := m.thesaurusLine
+	if y < 0 || y >= len(m.lines) || w.end > len(m.lines[y]) || m.lines[y][w.start:w.end] != w.word {
+		m.setStatus("Word changed since lookup", "red")
+		return
+	}
+
+	replacement := matchCase(w.word, item.word)
+	line := m.lines[y]
+	m.lines[y] = line[:w.start] + replacement + line[w.end:]
+	m.cursorY = y
+	m.cursorX = w.start
+	m.modified = true
+	m.invalidateWrapCache(y)
+	m.setStatus("Replaced \""+w.word+"\" with \""+replacement+"\"", "green")
+}
+
+// renderThesaurus renders the thesaurus pane (title row plus list rows)
+func (m model) renderThesaurus() string {
+	titleStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.title.bg")).
+		Foreground(m.themeColor("pane.title.fg")).
+		Bold(monochrome()).
+		Width(m.width)
+
+	itemStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.bg")).
+		Foreground(m.themeColor("pane.fg")).
+		Width(m.width)
+
+	selectedStyle := lipgloss.NewStyle().
+		Background(m.themeColor("pane.selected.bg")).
+		Reverse(monochrome()).
+		Foreground(m.themeColor("thesaurus.selected")).
+		Width(m.width)
+
+	title := fmt.Sprintf(" Thesaurus: %s (used %d times) | Enter: replace | q: close", m.thesaurusWord.word, m.thesaurusUses)
+
+	var rows []string
+	rows = append(rows, titleStyle.Render(truncateRunes(title, m.width)))
+
+	listHeight := thesaurusHeight - 1
+	for i := 0; i < listHeight; i++ {
+		idx := m.thesaurusOffset + i
+		if idx >= len(m.thesaurusItems) {
+			rows = append(rows, itemStyle.Render(""))
+			continue
+		}
+
+		item := m.thesaurusItems[idx]
+		kind := "synonym"
+		if item.antonym {
+			kind = "antonym"
+		}
+		text := truncateRunes(fmt.Sprintf(" %-28s %s %s", item.word, kind, item.partOfSpeech), m.width)
+		if idx == m.thesaurusCursor {
+			rows = append(rows, selectedStyle.Render(text))
+		} else {
+			rows = append(rows, itemStyle.Render(text))
+		}
+	}
+
+	return strings.Join(rows, "\n")
+}
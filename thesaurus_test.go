@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// sampleThesaurus is a small file in MyThes th_*.dat format
+const sampleThesaurus = `UTF-8
+big|2
+(adj)|large|huge (similar term)|small (antonym)
+(adv)|boastfully|vauntingly
+quick|1
+(adj)|fast|speedy|slow (antonym)
+`
+
+// TestThesaurusLookup verifies indexing and parsing of a .dat file without an .idx
+func TestThesaurusLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "th_en_GB_v2.dat")
+	if err := os.WriteFile(path, []byte(sampleThesaurus), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	thesaurus, err := loadThesaurus(path)
+	if err != nil {
+		t.Fatalf("loadThesaurus: %v", err)
+	}
+
+	meanings, err := thesaurus.lookup("Quick")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if len(meanings) != 1 || len(meanings[0].synonyms) != 2 || meanings[0].antonyms[0] != "slow" {
+		t.Fatalf("unexpected meanings for quick: %+v", meanings)
+	}
+
+	meanings, _ = thesaurus.lookup("big")
+	items := thesaurusItems("big", meanings)
+	if len(items) != 5 {
+		t.Fatalf("expected 5 items for big, got %d: %+v", len(items), items)
+	}
+	if items[1].word != "huge" {
+		t.Errorf("expected annotation to be stripped, got %q", items[1].word)
+	}
+	if last := items[len(items)-1]; last.word != "small" || !last.antonym {
+		t.Errorf("expected antonyms listed last, got %+v", last)
+	}
+
+	if meanings, _ := thesaurus.lookup("missing"); meanings != nil {
+		t.Errorf("expected no meanings for an unknown word")
+	}
+}
+
+// TestMatchCase verifies replacements keep the original capitalisation
+func TestMatchCase(t *testing.T) {
+	cases := map[[2]string]string{
+		{"big", "large"}: "large",
+		{"Big", "large"}: "Large",
+		{"BIG", "large"}: "LARGE",
+	}
+	for in, want := range cases {
+		if got := matchCase(in[0], in[1]); got != want {
+			t.Errorf("matchCase(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ignoreFiles are read in every folder of the project, in this order
+var ignoreFiles = []string{".gitignore", ".tuiwriteignore"}
+
+// ignoreRule is one pattern line from a .gitignore-style file
+type ignoreRule struct {
+	base     string // folder holding the ignore file
+	pattern  string // slash-separated glob
+	negate   bool   // "!pattern" shows what an earlier rule hid
+	dirOnly  bool   // "pattern/" only matches folders
+	anchored bool   // a pattern containing "/" is matched from base, not by name
+}
+
+// parseIgnoreFile reads the rules of one ignore file (none if it doesn't exist)
+func parseIgnoreFile(file string) []ignoreRule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: filepath.Dir(file)}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`) // "\#file" and "\!file" are literal
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// loadIgnoreRules reads the ignore files of every folder from root down to dir
+func loadIgnoreRules(root string, dir string) []ignoreRule {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	var rules []ignoreRule
+	current := root
+	for _, part := range append([]string{""}, strings.Split(rel, string(filepath.Separator))...) {
+		if part != "" && part != "." {
+			current = filepath.Join(current, part)
+		}
+		for _, name := range ignoreFiles {
+			rules = append(rules, parseIgnoreFile(filepath.Join(current, name))...)
+		}
+	}
+	return rules
+}
+
+// ignored reports whether the rules hide a path. The last matching rule wins.
+func ignored(rules []ignoreRule, p string, isDir bool) bool {
+	result := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(rule.base, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		var match bool
+		if rule.anchored {
+			match = globMatch(rule.pattern, rel)
+		} else {
+			match = globMatch(rule.pattern, path.Base(rel))
+		}
+		if match {
+			result = !rule.negate
+		}
+	}
+	return result
+}
+
+// globMatch matches a slash-separated path against a pattern where "**"
+// stands for any number of folders
+func globMatch(pattern string, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches path segments one at a time, expanding "**"
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// treeFilter decides which files and folders the tree and the finder list,
+// and how the tree orders them and what it reads about them
+type treeFilter struct {
+	root        string
+	excludeDirs []string // folder names never shown
+	include     []string // extra file patterns shown besides text files
+	showHidden  bool     // show names starting with "."
+	sortBy      string   // tree_sort mode
+	words       bool     // count the words of text files
+}
+
+// treeFilter returns the filter for the current settings
+func (m model) treeFilter() treeFilter {
+	return treeFilter{
+		root:        m.fileTreeRoot,
+		excludeDirs: append([]string(nil), m.config.ExcludeDirs...),
+		include:     append([]string(nil), m.config.TreeInclude...),
+		showHidden:  m.config.TreeShowHidden,
+		sortBy:      m.config.TreeSort,
+		words:       m.treeColumnShown(treeColumnWords),
+	}
+}
+
+// shows reports whether an entry belongs in the tree, given the ignore rules of its folder
+func (f treeFilter) shows(p string, isDir bool, rules []ignoreRule) bool {
+	name := filepath.Base(p)
+	if name == ".git" || strings.HasPrefix(name, ".") && !f.showHidden {
+		return false
+	}
+	if isDir && containsString(f.excludeDirs, name) {
+		return false
+	}
+	if ignored(rules, p, isDir) {
+		return false
+	}
+	if isDir || isTextFile(name) {
+		return true
+	}
+	rel, _ := filepath.Rel(f.root, p)
+	for _, pattern := range f.include {
+		if globMatch(pattern, name) || strings.Contains(pattern, "/") && globMatch(pattern, filepath.ToSlash(rel)) {
+			return true
+		}
+	}
+	return false
+}
+
+// showsPath checks one entry, reading the ignore rules for its folder
+func (f treeFilter) showsPath(p string, isDir bool) bool {
+	return f.shows(p, isDir, loadIgnoreRules(f.root, filepath.Dir(p)))
+}
+
+// visibleTreeNodes returns the tree rows: every expanded node, or while a
+// filter is typed, the loaded entries whose names contain it and the folders
+// leading to them
+func (m model) visibleTreeNodes() []FileNode {
+	if m.treeFilterQuery == "" {
+		return flattenFileTree(m.fileTreeNodes)
+	}
+	return filterFileTree(m.fileTreeNodes, strings.ToLower(m.treeFilterQuery))
+}
+
+// filterFileTree flattens the entries matching query, opening folders with matches inside
+func filterFileTree(nodes []FileNode, query string) []FileNode {
+	var result []FileNode
+	for _, node := range nodes {
+		var children []FileNode
+		if node.IsDir {
+			children = filterFileTree(node.Children, query)
+		}
+		if len(children) == 0 && !strings.Contains(strings.ToLower(node.Name), query) {
+			continue
+		}
+		node.Expanded = len(children) > 0
+		result = append(result, node)
+		result = append(result, children...)
+	}
+	return result
+}
+
+// treeFilterShown reports whether the filter box takes the tree's top row
+func (m model) treeFilterShown() bool {
+	return m.treeFilterTyping || m.treeFilterQuery != ""
+}
+
+// treeListHeight returns the rows available for tree entries
+func (m model) treeListHeight() int {
+	height := m.editorHeight()
+	if m.treeFilterShown() {
+		height--
+	}
+	return max(1, height)
+}
+
+// openTreeFilter starts typing in the filter box at the top of the tree
+func (m *model) openTreeFilter() {
+	if !m.fileTreeVisible || !m.fileTreeFocused {
+		return
+	}
+	m.treeFilterTyping = true
+}
+
+// setTreeFilter changes the filter, selecting the first match
+func (m *model) setTreeFilter(query string) {
+	m.treeFilterQuery = query
+	m.fileTreeCursor = 0
+	m.fileTreeOffset = 0
+	// Start on the first file rather than the folders leading to it
+	for i, node := range m.visibleTreeNodes() {
+		if query != "" && strings.Contains(strings.ToLower(node.Name), strings.ToLower(query)) {
+			m.fileTreeCursor = i
+			break
+		}
+	}
+	m.moveTreeCursor(0)
+}
+
+// handleTreeFilterKeys edits the filter while the box is being typed in
+func (m model) handleTreeFilterKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c", "ctrl+g":
+		// Esc clears the filter and shows the whole tree again
+		selected := ""
+		if nodes := m.visibleTreeNodes(); m.fileTreeCursor < len(nodes) {
+			selected = nodes[m.fileTreeCursor].Path
+		}
+		m.treeFilterTyping = false
+		m.setTreeFilter("")
+		if selected != "" {
+			m.selectTreePath(selected)
+		}
+	case "enter":
+		m.treeFilterTyping = false
+	case "up", "ctrl+p":
+		m.moveTreeCursor(-1)
+	case "down", "ctrl+n":
+		m.moveTreeCursor(1)
+	case "backspace":
+		if m.treeFilterQuery == "" {
+			m.treeFilterTyping = false
+			break
+		}
+		_, size := utf8.DecodeLastRuneInString(m.treeFilterQuery)
+		m.setTreeFilter(m.treeFilterQuery[:len(m.treeFilterQuery)-size])
+	case "ctrl+u":
+		m.setTreeFilter("")
+	default:
+		if msg.Type == tea.KeyRunes && !msg.Alt || msg.Type == tea.KeySpace {
+			m.setTreeFilter(m.treeFilterQuery + string(msg.Runes))
+		}
+	}
+	return m, nil
+}
+
+// renderTreeFilterRow draws the filter box above the tree entries
+func (m model) renderTreeFilterRow(treeWidth int) string {
+	text := "/ " + m.treeFilterQuery
+	if m.treeFilterTyping {
+		text += "▏"
+	} else {
+		text += "  (/ to edit)"
+	}
+	return lipgloss.NewStyle().
+		Background(m.themeColor("pane.title.bg")).
+		Foreground(m.themeColor("pane.title.fg")).
+		Width(treeWidth).
+		Render(truncateRunes(text, treeWidth))
+}
+
+// rebuildFileTree reads the tree again from its root after the filter
+// settings changed, keeping the selection where it can
+func (m *model) rebuildFileTree() error {
+	selected := ""
+	if nodes := m.visibleTreeNodes(); m.fileTreeCursor < len(nodes) {
+		selected = nodes[m.fileTreeCursor].Path
+	}
+	nodes, err := buildFileTree(m.fileTreeRoot, m.treeFilter(), m.config.TreeMaxEntries)
+	if err != nil {
+		return err
+	}
+	m.fileTreeNodes = nodes
+	m.fileTreeCursor = 0
+	m.fileTreeOffset = 0
+	m.syncTreeWatches()
+	if selected != "" {
+		m.selectTreePath(selected)
+	}
+	return nil
+}
+
+// toggleHiddenFiles shows or hides dotfiles in the tree, keeping the selection
+func (m *model) toggleHiddenFiles() {
+	m.config.TreeShowHidden = !m.config.TreeShowHidden
+	if err := m.rebuildFileTree(); err != nil {
+		m.setStatus("Failed to rebuild file tree: "+err.Error(), "red")
+		return
+	}
+	if m.config.TreeShowHidden {
+		m.setStatus("Showing hidden files", "green")
+	} else {
+		m.setStatus("Hiding hidden files", "green")
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		".gitignore":             "# build output\nbuild/\n*.log\n!keep.log\ndocs/**/draft-*.md\n",
+		"drafts/.tuiwriteignore": "old.md\n",
+	})
+	rules := loadIgnoreRules(root, filepath.Join(root, "drafts"))
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"build", true, true},
+		{"build", false, false}, // "build/" only matches folders
+		{"notes/debug.log", false, true},
+		{"keep.log", false, false},
+		{"docs/a/b/draft-1.md", false, true},
+		{"docs/draft-2.md", false, true},
+		{"docs/final.md", false, false},
+		{"drafts/old.md", false, true},
+		{"old.md", false, false}, // the rule only applies inside drafts
+	}
+	for _, tt := range tests {
+		p := filepath.Join(root, filepath.FromSlash(tt.path))
+		if got := ignored(rules, p, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, dir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestTreeFilterShows(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		".gitignore":     "scratch.md\n",
+		".hidden.md":     "",
+		"cover.png":      "",
+		"art/map.svg":    "",
+		"outline.md":     "",
+		"scratch.md":     "",
+		"vendor/lib.txt": "",
+	})
+	filter := treeFilter{root: root, excludeDirs: []string{"vendor"}}
+	nodes, err := buildFileTree(root, filter, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	if want := []string{"art", "outline.md"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("default tree = %q, want %q", names, want)
+	}
+
+	filter.include = []string{"*.png", "art/*.svg"}
+	filter.showHidden = true
+	if !filter.showsPath(filepath.Join(root, "cover.png"), false) {
+		t.Error("include patterns should show matching files")
+	}
+	if !filter.showsPath(filepath.Join(root, "art", "map.svg"), false) {
+		t.Error("include patterns with a folder should match from the root")
+	}
+	if !filter.showsPath(filepath.Join(root, ".hidden.md"), false) {
+		t.Error("hidden files should show when enabled")
+	}
+	if filter.showsPath(filepath.Join(root, ".git"), true) {
+		t.Error(".git is never shown")
+	}
+	if filter.showsPath(filepath.Join(root, "scratch.md"), false) {
+		t.Error("ignored files stay hidden")
+	}
+}
+
+func TestTreeFilterBox(t *testing.T) {
+	m, root := newFileOpsTestModel(t)
+	m.selectTreePath(filepath.Join(root, "chapters", "one.md"))
+	m.fileTreeCursor = 0
+
+	m = pressKeys(t, m, "/")
+	if !m.treeFilterTyping {
+		t.Fatal("/ should open the filter box")
+	}
+	m = pressKeys(t, m, "TWO")
+	var names []string
+	for _, node := range m.visibleTreeNodes() {
+		names = append(names, node.Name)
+	}
+	if want := []string{"chapters", "two.md"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("filtered = %q, want %q", names, want)
+	}
+	if m.visibleTreeNodes()[m.fileTreeCursor].Name != "two.md" {
+		t.Error("the first match should be selected")
+	}
+
+	// Enter keeps the filter and hands keys back to the tree
+	m = pressSpecial(t, m, tea.KeyEnter)
+	if m.treeFilterTyping || m.treeFilterQuery != "TWO" {
+		t.Errorf("after enter: typing %v, query %q", m.treeFilterTyping, m.treeFilterQuery)
+	}
+
+	// Esc in the box clears it and keeps the selection
+	m = pressKeys(t, m, "/")
+	m = pressSpecial(t, m, tea.KeyEsc)
+	if m.treeFilterQuery != "" || m.treeFilterTyping {
+		t.Error("esc should clear the filter")
+	}
+	if m.visibleTreeNodes()[m.fileTreeCursor].Name != "two.md" {
+		t.Error("the selection should survive clearing the filter")
+	}
+}
+
+func TestToggleHiddenFiles(t *testing.T) {
+	m, root := newFileOpsTestModel(t)
+	if err := os.WriteFile(filepath.Join(root, ".notes.md"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m = pressKeys(t, m, ".")
+	if !containsString(treePaths(m, root), ".notes.md") {
+		t.Errorf("hidden file not shown: %q", treePaths(m, root))
+	}
+	m = pressKeys(t, m, ".")
+	if containsString(treePaths(m, root), ".notes.md") {
+		t.Error("hidden file still shown")
+	}
+}
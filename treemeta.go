@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Tree sort modes (tree_sort)
+const (
+	treeSortName     = "name"     // folders first, then alphabetically
+	treeSortModified = "modified" // folders first, then most recently changed first
+	treeSortManual   = "manual"   // the order saved in each folder's treeOrderFile
+)
+
+// treeSortModes lists the sort modes in the order tree.sort cycles through them
+var treeSortModes = []string{treeSortName, treeSortModified, treeSortManual}
+
+// Tree columns (tree_columns), shown right-aligned after the names in this order
+const (
+	treeColumnWords    = "words"
+	treeColumnModified = "modified"
+	treeColumnGit      = "git"
+)
+
+// treeColumns lists the columns the tree can show
+var treeColumns = []string{treeColumnWords, treeColumnModified, treeColumnGit}
+
+// treeOrderFile holds a folder's manual order, one name per line
+const treeOrderFile = ".tuiwrite-order"
+
+// treeWordsMaxSize is the largest file whose words the tree counts
+const treeWordsMaxSize = 4 << 20
+
+// treeGitInterval is how often git status is read again when nothing in the tree changed
+const treeGitInterval = 10 * time.Second
+
+// fillTreeNodeInfo records a node's modification time and, for text files
+// when asked, its word count
+func fillTreeNodeInfo(node *FileNode, info fs.FileInfo, words bool) {
+	node.ModTime = info.ModTime()
+	if !words || node.IsDir || !isTextFile(node.Name) || info.Size() > treeWordsMaxSize {
+		return
+	}
+	data, err := os.ReadFile(node.Path)
+	if err != nil {
+		return
+	}
+	node.Words = countWords(strings.Split(string(data), "\n"))
+}
+
+// readTreeOrder reads a folder's manual order (nil if it has none)
+func readTreeOrder(dir string) []string {
+	data, err := os.ReadFile(filepath.Join(dir, treeOrderFile))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// writeTreeOrder saves a folder's manual order
+func writeTreeOrder(dir string, names []string) error {
+	return os.WriteFile(filepath.Join(dir, treeOrderFile), []byte(strings.Join(names, "\n")+"\n"), 0644)
+}
+
+// sortTreeNodes orders a folder's entries for a sort mode. In manual order the
+// entries listed in the folder's order file come first, in that order.
+func sortTreeNodes(nodes []FileNode, dir string, sortBy string) {
+	rank := map[string]int{}
+	if sortBy == treeSortManual {
+		for i, name := range readTreeOrder(dir) {
+			if _, ok := rank[name]; !ok {
+				rank[name] = i
+			}
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if sortBy == treeSortManual {
+			ra, aListed := rank[a.Name]
+			rb, bListed := rank[b.Name]
+			if aListed || bListed {
+				if aListed && bListed {
+					return ra < rb
+				}
+				return aListed
+			}
+		}
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		if sortBy == treeSortModified && !a.ModTime.Equal(b.ModTime) {
+			return a.ModTime.After(b.ModTime)
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+}
+
+// treeColumnShown reports whether tree_columns includes a column
+func (m model) treeColumnShown(column string) bool {
+	return containsString(m.config.TreeColumns, column)
+}
+
+// resortTree orders every loaded folder for the current sort mode, keeping the selection
+func (m *model) resortTree() {
+	selected := ""
+	if nodes := m.visibleTreeNodes(); m.fileTreeCursor < len(nodes) {
+		selected = nodes[m.fileTreeCursor].Path
+	}
+	var walk func(nodes []FileNode, dir string)
+	walk = func(nodes []FileNode, dir string) {
+		sortTreeNodes(nodes, dir, m.config.TreeSort)
+		for i := range nodes {
+			if nodes[i].IsDir && nodes[i].Loaded {
+				walk(nodes[i].Children, nodes[i].Path)
+			}
+		}
+	}
+	walk(m.fileTreeNodes, m.fileTreeRoot)
+	if selected != "" {
+		m.selectTreePath(selected)
+	}
+}
+
+// cycleTreeSort switches the tree to the next sort mode
+func (m *model) cycleTreeSort() {
+	next := treeSortModes[0]
+	for i, mode := range treeSortModes {
+		if mode == m.config.TreeSort {
+			next = treeSortModes[(i+1)%len(treeSortModes)]
+		}
+	}
+	m.config.TreeSort = next
+	m.resortTree()
+	switch next {
+	case treeSortModified:
+		m.setStatus("Tree sorted by modification time", "green")
+	case treeSortManual:
+		m.setStatus("Tree in manual order (J/K move the selection)", "green")
+	default:
+		m.setStatus("Tree sorted by name", "green")
+	}
+}
+
+// moveTreeEntry moves the selection up or down among its siblings and saves
+// the folder's manual order
+func (m *model) moveTreeEntry(delta int) {
+	if m.config.TreeSort != treeSortManual {
+		m.setStatus("Switch the tree to manual order (s) to reorder entries", "yellow")
+		return
+	}
+	nodes := m.visibleTreeNodes()
+	if m.fileTreeCursor >= len(nodes) {
+		return
+	}
+	path := nodes[m.fileTreeCursor].Path
+	dir := filepath.Dir(path)
+	siblings, _ := treeChildren(&m.fileTreeNodes, m.fileTreeRoot, dir)
+	if siblings == nil {
+		return
+	}
+	i := 0
+	for i < len(*siblings) && (*siblings)[i].Path != path {
+		i++
+	}
+	j := i + delta
+	if i == len(*siblings) || j < 0 || j >= len(*siblings) {
+		return
+	}
+	(*siblings)[i], (*siblings)[j] = (*siblings)[j], (*siblings)[i]
+
+	names := make([]string, len(*siblings))
+	for k, node := range *siblings {
+		names[k] = node.Name
+	}
+	if err := writeTreeOrder(dir, names); err != nil {
+		m.setStatus("Failed to save the order: "+err.Error(), "red")
+		return
+	}
+	m.selectTreePath(path)
+}
+
+// resizeFileTree widens or narrows the tree, leaving the editor room to write
+func (m *model) resizeFileTree(delta int) {
+	m.setFileTreeWidth(m.config.TreeWidth + delta)
+	m.setStatus(fmt.Sprintf("Tree width %d", m.config.TreeWidth), "green")
+}
+
+// setFileTreeWidth changes the tree width within tree_width's limits
+func (m *model) setFileTreeWidth(width int) {
+	limit := 80
+	if m.width > 0 {
+		limit = min(limit, m.width-20)
+	}
+	width = max(10, min(width, limit))
+	if width == m.config.TreeWidth {
+		return
+	}
+	m.config.TreeWidth = width
+	m.rewrapLines()
+}
+
+// noteTreeFileSaved updates the open file's details in the tree after a save
+func (m *model) noteTreeFileSaved() {
+	node := findTreeNode(m.fileTreeNodes, m.filename)
+	if node == nil {
+		return
+	}
+	node.ModTime = m.lastSave
+	if m.treeColumnShown(treeColumnWords) {
+		node.Words = countWords(m.lines)
+	}
+}
+
+// treeGitMsg carries git status marks for the files under a tree root
+type treeGitMsg struct {
+	root  string
+	marks map[string]string // absolute path to status mark
+}
+
+// loadTreeGit returns a command that reads git status for the project. Folders
+// outside a repository get no marks.
+func loadTreeGit(root string) tea.Cmd {
+	return func() tea.Msg {
+		msg := treeGitMsg{root: root}
+		top, err := exec.Command("git", "-C", root, "rev-parse", "--show-toplevel").Output()
+		if err != nil {
+			return msg
+		}
+		out, err := exec.Command("git", "-C", root, "status", "--porcelain", "-z", "--untracked-files=all").Output()
+		if err != nil {
+			LogDebugf("git status in %s: %v", root, err)
+			return msg
+		}
+		msg.marks = parseGitStatus(out, strings.TrimSpace(string(top)), root)
+		return msg
+	}
+}
+
+// parseGitStatus turns `git status --porcelain -z` output, whose paths are
+// relative to the repository's top folder, into marks for the paths under root:
+// the file's status letter, and "*" on the folders above a changed file
+func parseGitStatus(out []byte, top string, root string) map[string]string {
+	// git reports the real path; the tree may have been opened through a symlink
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		realRoot = root
+	}
+	marks := make(map[string]string)
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		code, rel := entry[:2], entry[3:]
+		if code[0] == 'R' || code[0] == 'C' {
+			i++ // the original path follows a rename or copy
+		}
+		mark := ""
+		switch {
+		case code == "??":
+			mark = "?"
+		case strings.Contains(code, "U") || code == "AA" || code == "DD":
+			mark = "!"
+		case strings.Contains(code, "A"):
+			mark = "A"
+		case strings.Contains(code, "R"):
+			mark = "R"
+		case strings.Contains(code, "M"):
+			mark = "M"
+		default:
+			continue // deleted files aren't in the tree
+		}
+		under, err := filepath.Rel(realRoot, filepath.Join(top, filepath.FromSlash(strings.TrimSuffix(rel, "/"))))
+		if err != nil || strings.HasPrefix(under, "..") {
+			continue // outside the tree
+		}
+		path := filepath.Join(root, under)
+		marks[path] = mark
+		for dir := filepath.Dir(path); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+			if _, ok := marks[dir]; ok {
+				break
+			}
+			marks[dir] = "*"
+		}
+	}
+	return marks
+}
+
+// treeGitCmd reads git status again when the git column is shown and the last
+// read is out of date (or force is set because the tree changed)
+func (m *model) treeGitCmd(force bool) tea.Cmd {
+	if !m.treeColumnShown(treeColumnGit) || m.fileTreeRoot == "" || m.treeGitLoading {
+		return nil
+	}
+	if !force && time.Since(m.treeGitRead) < treeGitInterval {
+		return nil
+	}
+	m.treeGitLoading = true
+	m.treeGitRead = time.Now()
+	return loadTreeGit(m.fileTreeRoot)
+}
+
+// handleTreeGit stores fresh git status marks
+func (m *model) handleTreeGit(msg treeGitMsg) {
+	m.treeGitLoading = false
+	if msg.root == m.fileTreeRoot {
+		m.treeGitMarks = msg.marks
+	}
+}
+
+// treeBadges returns the columns shown after a node's name, or "" when none are on
+func (m model) treeBadges(node FileNode) string {
+	var parts []string
+	if m.treeColumnShown(treeColumnWords) {
+		words := ""
+		if n := treeNodeWords(node); n > 0 {
+			words = formatTreeWords(n)
+		}
+		parts = append(parts, fmt.Sprintf("%5s", words))
+	}
+	if m.treeColumnShown(treeColumnModified) {
+		parts = append(parts, fmt.Sprintf("%3s", formatTreeAge(node.ModTime, time.Now())))
+	}
+	if m.treeColumnShown(treeColumnGit) {
+		mark := m.treeGitMarks[node.Path]
+		if mark == "" {
+			mark = " "
+		}
+		parts = append(parts, mark)
+	}
+	return strings.Join(parts, " ")
+}
+
+// treeNodeWords is a file's word count, or the total of a folder's loaded files
+func treeNodeWords(node FileNode) int {
+	if !node.IsDir {
+		return node.Words
+	}
+	total := 0
+	for _, child := range node.Children {
+		total += treeNodeWords(child)
+	}
+	return total
+}
+
+// formatTreeWords fits a word count in five columns (950, 12.4k, 123k)
+func formatTreeWords(n int) string {
+	switch {
+	case n < 10000:
+		return fmt.Sprintf("%d", n)
+	case n < 99950:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%dk", n/1000)
+	}
+}
+
+// formatTreeAge says how long ago t was in at most three columns (5m, 3h, 2d, 6w, 4y)
+func formatTreeAge(t time.Time, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 14*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dw", int(d.Hours()/24/7))
+	default:
+		return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSortTreeNodes(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	nodes := func() []FileNode {
+		return []FileNode{
+			{Name: "b.md", ModTime: now.Add(-time.Hour)},
+			{Name: "notes", IsDir: true, ModTime: now.Add(-48 * time.Hour)},
+			{Name: "a.md", ModTime: now.Add(-48 * time.Hour)},
+			{Name: "c.md", ModTime: now},
+		}
+	}
+	names := func(nodes []FileNode) []string {
+		var names []string
+		for _, node := range nodes {
+			names = append(names, node.Name)
+		}
+		return names
+	}
+
+	byName := nodes()
+	sortTreeNodes(byName, dir, treeSortName)
+	if want := []string{"notes", "a.md", "b.md", "c.md"}; !reflect.DeepEqual(names(byName), want) {
+		t.Errorf("name = %q", names(byName))
+	}
+	byTime := nodes()
+	sortTreeNodes(byTime, dir, treeSortModified)
+	if want := []string{"notes", "c.md", "b.md", "a.md"}; !reflect.DeepEqual(names(byTime), want) {
+		t.Errorf("modified = %q", names(byTime))
+	}
+
+	// Listed entries come first in the saved order, the rest follow by name
+	if err := writeTreeOrder(dir, []string{"c.md", "gone.md", "notes"}); err != nil {
+		t.Fatal(err)
+	}
+	manual := nodes()
+	sortTreeNodes(manual, dir, treeSortManual)
+	if want := []string{"c.md", "notes", "a.md", "b.md"}; !reflect.DeepEqual(names(manual), want) {
+		t.Errorf("manual = %q", names(manual))
+	}
+}
+
+func TestManualTreeOrder(t *testing.T) {
+	m, root := newFileOpsTestModel(t)
+	m.selectTreePath(filepath.Join(root, "outline.md"))
+
+	m = pressKeys(t, m, "K")
+	if !strings.Contains(m.statusMsg.Text, "manual order") {
+		t.Errorf("moving outside manual order: %q", m.statusMsg.Text)
+	}
+
+	m = pressKeys(t, m, "s", "s")
+	if m.config.TreeSort != treeSortManual {
+		t.Fatalf("sort = %q", m.config.TreeSort)
+	}
+	m = pressKeys(t, m, "K")
+	if want := []string{"outline.md", "chapters"}; !reflect.DeepEqual(treePaths(m, root), want) {
+		t.Errorf("after K: %q", treePaths(m, root))
+	}
+	if got := readTreeOrder(root); !reflect.DeepEqual(got, []string{"outline.md", "chapters"}) {
+		t.Errorf("saved order = %q", got)
+	}
+	if flattenFileTree(m.fileTreeNodes)[m.fileTreeCursor].Name != "outline.md" {
+		t.Error("the moved entry should stay selected")
+	}
+
+	// The saved order is used when the folder is read again
+	nodes, err := buildFileTree(root, treeFilter{root: root, sortBy: treeSortManual}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes[0].Name != "outline.md" {
+		t.Errorf("reread order starts with %q", nodes[0].Name)
+	}
+}
+
+func TestTreeColumns(t *testing.T) {
+	root := writeProject(t, map[string]string{"chapter.md": "It was a dark and stormy night."})
+	nodes, err := buildFileTree(root, treeFilter{root: root, words: true}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes[0].Words != 7 || nodes[0].ModTime.IsZero() {
+		t.Fatalf("words %d, modified %v", nodes[0].Words, nodes[0].ModTime)
+	}
+
+	m := model{config: defaultConfig(), fileTreeRoot: root, fileTreeNodes: nodes}
+	m.config.TreeColumns = []string{treeColumnWords, treeColumnModified, treeColumnGit}
+	m.treeGitMarks = map[string]string{filepath.Join(root, "chapter.md"): "M"}
+	row := stripAnsi(m.renderFileTreeRow(nodes, 0, 40))
+	if !strings.Contains(row, "chapter.md") || !strings.HasSuffix(strings.TrimRight(row, " "), "7 now M") {
+		t.Errorf("row = %q", row)
+	}
+
+	// A narrow tree drops the details before the names
+	if row := stripAnsi(m.renderFileTreeRow(nodes, 0, 16)); strings.Contains(row, " M") {
+		t.Errorf("narrow row = %q", row)
+	}
+}
+
+func TestParseGitStatus(t *testing.T) {
+	top := t.TempDir()
+	root := filepath.Join(top, "book")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	out := " M book/part/one.md\x00?? book/new.md\x00R  book/two.md\x00book/old.md\x00M  README.md\x00"
+	marks := parseGitStatus([]byte(out), top, root)
+	want := map[string]string{
+		filepath.Join(root, "part", "one.md"): "M",
+		filepath.Join(root, "part"):           "*",
+		filepath.Join(root, "new.md"):         "?",
+		filepath.Join(root, "two.md"):         "R",
+	}
+	if !reflect.DeepEqual(marks, want) {
+		t.Errorf("marks = %v", marks)
+	}
+}
+
+func TestFormatTreeDetails(t *testing.T) {
+	now := time.Now()
+	for _, tt := range []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "now"},
+		{5 * time.Minute, "5m"},
+		{3 * time.Hour, "3h"},
+		{50 * time.Hour, "2d"},
+		{30 * 24 * time.Hour, "4w"},
+		{800 * 24 * time.Hour, "2y"},
+	} {
+		if got := formatTreeAge(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("formatTreeAge(%v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+	for n, want := range map[int]string{950: "950", 12400: "12.4k", 99990: "99k", 123456: "123k"} {
+		if got := formatTreeWords(n); got != want {
+			t.Errorf("formatTreeWords(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestResizeFileTree(t *testing.T) {
+	m := newKeymapTestModel(t, defaultKeymapPreset)
+	m.width = 100
+	m.fileTreeVisible = true
+	m.fileTreeFocused = true
+	start := m.config.TreeWidth
+
+	m = pressKeys(t, m, ">")
+	if m.config.TreeWidth != start+2 {
+		t.Errorf("width = %d, want %d", m.config.TreeWidth, start+2)
+	}
+	for i := 0; i < 40; i++ {
+		m = pressKeys(t, m, ">")
+	}
+	if m.config.TreeWidth != 80 {
+		t.Errorf("width grew to %d", m.config.TreeWidth)
+	}
+	m.width = 60
+	if w := m.fileTreeWidth(); w != 40 {
+		t.Errorf("narrow window tree width = %d", w)
+	}
+}
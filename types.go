@@ -40,6 +40,7 @@ type StatusMessage struct {
 type wrappedLine struct {
 	text        string // the wrapped line text
 	sourceLineY int    // which original line this came from
+	startX      int    // offset of this segment within the source line
 	isLastWrap  bool   // true if this is the last wrap of the source line
 }
 
@@ -85,14 +86,48 @@ type model struct {
 	lastFontSizeTime  time.Time // for smooth font size changes
 
 	// Zen mode
-	zenMode bool // true when in fullscreen/borderless mode
+	zenMode bool // true when in distraction-free mode
 
 	// Spell checking
 	spellChecker *SpellChecker
 
+	// Prose linting
+	linter *Linter
+
 	// Command mode
 	commandMode   bool   // true when in command mode (after typing :)
 	commandBuffer string // current command being typed
+	commandCursor int    // byte offset of the cursor in commandBuffer (after the ":")
+
+	// Command-line history and completion
+	commandHistory  []string // oldest first, shared with other sessions through the config directory
+	historyLoaded   bool
+	historyIndex    int      // entry being shown while browsing, -1 when not browsing
+	historyDraft    string   // what was typed before browsing began
+	completions     []string // completion menu items, nil when closed
+	completionIndex int      // selected menu item, -1 before the first cycle
+	completionStart int      // byte offset where the completed word starts
+
+	// Command palette
+	paletteVisible      bool
+	paletteQuery        string
+	paletteItems        []paletteItem // entries matching the query, best first
+	paletteCursor       int
+	paletteOffset       int
+	paletteRecent       []string // recently run entries, oldest first
+	paletteRecentLoaded bool
+
+	// File finder
+	finderVisible     bool
+	finderLoading     bool // true until the project scan finishes
+	finderQuery       string
+	finderFiles       []string     // every project file, relative to fileTreeRoot
+	finderItems       []finderItem // files matching the query, best first
+	finderRecent      []string     // recently opened files, relative to fileTreeRoot
+	finderCursor      int
+	finderOffset      int
+	finderPreview     []string // first lines of the selected file
+	finderPreviewPath string
 
 	// Cursor blink
 	cursorVisible   bool      // true when cursor should be visible (toggles for blink)
@@ -103,13 +138,95 @@ type model struct {
 	selectionStartX int  // starting column of selection
 	selectionStartY int  // starting line of selection
 
+	// Clipboard and registers
+	killRing         []string        // recent copies, cuts and deleted lines, newest first
+	registers        map[rune]string // named registers a-z
+	register         rune            // register chosen for the next copy, cut or paste (0 for none)
+	awaitingRegister bool            // the next key names a register
+	clipboardSent    string          // text last put on the system or terminal clipboard
+	lastPaste        pastedText      // the last paste, for clipboard.paste_older
+
+	// Undo
+	undoStack []undoState // the document before each recent edit, oldest first
+	redoStack []undoState // edits undone, most recent last
+	lastEdit  string      // kind of the last edit recorded (undoEdit, undoTyping), "" after any other action
+
+	// Mouse
+	mouseDrag     int       // what the held left button drags (mouseDragNone, mouseDragSelect, mouseDragDivider)
+	lastClickTime time.Time // when the left button was last pressed, to spot double and triple clicks
+	lastClickX    int       // screen column of the last click
+	lastClickY    int       // screen row of the last click
+	clickCount    int       // 1, 2 or 3 for the last click being single, double or triple
+
 	// File tree
-	fileTreeVisible bool       // true when file tree sidebar is shown
-	fileTreeFocused bool       // true when file tree has focus (vs editor)
-	fileTreeCursor  int        // current selection in file tree
-	fileTreeOffset  int        // scroll offset for file tree viewport
-	fileTreeNodes   []FileNode // flattened list of visible tree nodes
-	fileTreeRoot    string     // root directory path for file tree
+	fileTreeVisible  bool              // true when file tree sidebar is shown
+	fileTreeFocused  bool              // true when file tree has focus (vs editor)
+	fileTreeCursor   int               // current selection in file tree
+	fileTreeOffset   int               // scroll offset for file tree viewport
+	fileTreeNodes    []FileNode        // flattened list of visible tree nodes
+	fileTreeRoot     string            // root directory path for file tree
+	treeFilterQuery  string            // names containing this are shown, with the folders above them
+	treeFilterTyping bool              // true while typing in the filter box
+	treeWatcher      *treeWatcher      // refreshes expanded folders when files change on disk
+	treeGitMarks     map[string]string // git status mark per path, for tree_columns "git"
+	treeGitRead      time.Time         // when git status was last requested
+	treeGitLoading   bool              // true while git status is being read
+
+	// Statistics
+	statsVisible bool         // true when the statistics sidebar is shown
+	statsCache   *statsCache  // last document statistics (shared across frames)
+	wordCounter  *wordCounter // cached per-line word counts for the status bar
+
+	// Quickfix pane (lint findings and other location lists)
+	quickfixVisible bool           // true when the quickfix pane is shown
+	quickfixFocused bool           // true when the quickfix pane has focus
+	quickfixTitle   string         // heading shown above the list
+	quickfixItems   []quickfixItem // locations listed in the pane
+	quickfixCursor  int            // selected item
+	quickfixOffset  int            // scroll offset for the list
+
+	// Thesaurus pane
+	thesaurus        *Thesaurus      // loaded thesaurus (matches the spell-check language)
+	thesaurusVisible bool            // true when the thesaurus pane is shown (and focused)
+	thesaurusWord    wordPos         // word being looked up
+	thesaurusLine    int             // source line of the looked-up word
+	thesaurusUses    int             // how often the word appears in the document
+	thesaurusItems   []thesaurusItem // synonyms and antonyms
+	thesaurusCursor  int             // selected item
+	thesaurusOffset  int             // scroll offset for the list
+
+	// Info pane (read-only reports such as :goals)
+	infoVisible bool     // true when the info pane is shown (and focused)
+	infoTitle   string   // heading shown above the text
+	infoLines   []string // text rows
+	infoOffset  int      // scroll offset for the text
+
+	// Writing goals
+	session    *sessionTracker // words added/removed since the file was opened
+	goals      projectGoals    // daily and per-file word targets for the project
+	wordsStale bool            // the text changed since the session last counted words
+
+	// Writing sprints
+	sprint        *writingSprint // running sprint (nil when none)
+	sprintCounter int            // number of sprints started, used as tick id
+
+	// Settings
+	config             Config // merged user and project settings
+	configStamp        string // config file modification times at the last load
+	autoSaveRunning    bool   // an auto-save tick is pending
+	cursorBlinkRunning bool   // a cursor blink tick is pending
+
+	// Command line
+	argFiles []fileTarget // files named on the command line, for :next and :prev
+	argIndex int          // which of argFiles is open
+	readOnly bool         // started with --readonly: no Edit mode, no saving
+
+	// Appearance
+	theme *Theme // active color theme (nil until the config is applied)
+
+	// Key bindings
+	keymap      *Keymap  // active bindings (nil until the config is applied)
+	pendingKeys []string // keys of a partly typed sequence such as "g" in "g g"
 }
 
 // FileNode represents an item in the file tree
@@ -120,6 +237,11 @@ type FileNode struct {
 	Expanded bool       // true if directory is expanded (only applies to directories)
 	Depth    int        // indentation depth in the tree
 	Children []FileNode // child nodes (only for directories)
+	Loaded   bool       // true once a directory's children have been read
+	Loading  bool       // true while a directory's children are being read
+	Hidden   int        // entries beyond tree_max_entries left out of Children
+	ModTime  time.Time  // last modification, for tree_columns and tree_sort
+	Words    int        // word count of a text file, when tree_columns shows words
 }
 
 // Tab represents a single open file/document (for future multi-tab support)
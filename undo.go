@@ -0,0 +1,92 @@
+package main
+
+import (
+	"slices"
+)
+
+// undoLimit is how many edits undo can step back through
+const undoLimit = 100
+
+// Edit kinds for saveUndo
+const (
+	undoEdit   = "edit"   // one action, e.g. a deletion or a paste
+	undoTyping = "typing" // typed characters, undone together
+)
+
+// undoState is the document and cursor as they were before an edit
+type undoState struct {
+	lines            []string
+	cursorY, cursorX int
+}
+
+// undoSnapshot copies the document and cursor
+func (m *model) undoSnapshot() undoState {
+	return undoState{lines: slices.Clone(m.lines), cursorY: m.cursorY, cursorX: m.cursorX}
+}
+
+// saveUndo records the document before an edit. A run of typed characters is
+// one step, so it only records the first.
+func (m *model) saveUndo(kind string) {
+	if kind == undoTyping && m.lastEdit == undoTyping {
+		return
+	}
+	m.lastEdit = kind
+	m.undoStack = append(m.undoStack, m.undoSnapshot())
+	if len(m.undoStack) > undoLimit {
+		m.undoStack = m.undoStack[len(m.undoStack)-undoLimit:]
+	}
+	m.redoStack = nil
+}
+
+// dropUnchangedUndo forgets the last step if the edit didn't change anything,
+// e.g. backspace at the start of the document
+func (m *model) dropUnchangedUndo() {
+	if n := len(m.undoStack); n > 0 && slices.Equal(m.undoStack[n-1].lines, m.lines) {
+		m.undoStack = m.undoStack[:n-1]
+		m.lastEdit = ""
+	}
+}
+
+// clearUndo forgets the edit history, e.g. when another file is opened
+func (m *model) clearUndo() {
+	m.undoStack, m.redoStack = nil, nil
+	m.lastEdit = ""
+}
+
+// undo restores the document as it was before the last edit
+func (m *model) undo() {
+	if len(m.undoStack) == 0 {
+		m.setStatus("Nothing to undo", "yellow")
+		return
+	}
+	state := m.undoStack[len(m.undoStack)-1]
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	m.redoStack = append(m.redoStack, m.undoSnapshot())
+	m.restoreUndoState(state)
+	m.setStatus("Undone", "green")
+}
+
+// redo reapplies the last edit undone
+func (m *model) redo() {
+	if len(m.redoStack) == 0 {
+		m.setStatus("Nothing to redo", "yellow")
+		return
+	}
+	state := m.redoStack[len(m.redoStack)-1]
+	m.redoStack = m.redoStack[:len(m.redoStack)-1]
+	m.undoStack = append(m.undoStack, m.undoSnapshot())
+	m.restoreUndoState(state)
+	m.setStatus("Redone", "green")
+}
+
+// restoreUndoState puts back a recorded document and cursor
+func (m *model) restoreUndoState(state undoState) {
+	m.lines = state.lines
+	m.cursorY, m.cursorX = state.cursorY, state.cursorX
+	m.selectionActive = false
+	m.lastEdit = ""
+	m.lastPaste = pastedText{}
+	m.modified = true
+	m.invalidateAllWrapCache()
+	m.adjustViewport()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -14,174 +15,228 @@ func (m model) View() string {
 		return "Loading..."
 	}
 
+	if m.zenMode {
+		return m.renderZenView()
+	}
+
 	var sb strings.Builder
 
-	// Base style with Catppuccin Base background and Text foreground
+	// Base style with the editor background and text colors
 	baseStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color(ColorToHex(Base))).
-		Foreground(lipgloss.Color(ColorToHex(Text)))
-
-	// Calculate visible area (leave 2 lines for status bar)
-	visibleHeight := m.height - 2
-
-	// If file tree is visible, render split view
-	if m.fileTreeVisible {
-		// File tree takes 30 characters, editor gets the rest
-		treeWidth := 30
-		editorWidth := m.width - treeWidth - 1 // -1 for divider
-
-		// Get flattened file tree nodes
-		flatNodes := flattenFileTree(m.fileTreeNodes)
-
-		// Get visible lines once for the editor (not in the loop!)
-		visibleLines := m.getVisibleWrappedLines(m.offsetY, visibleHeight)
-
-		// Render each line with file tree on left, editor on right
-		for i := 0; i < visibleHeight; i++ {
-			// Calculate the actual node index with offset
-			nodeIdx := m.fileTreeOffset + i
-
-			var treeLine string
-			// Render file tree column
-			if nodeIdx < len(flatNodes) {
-				node := flatNodes[nodeIdx]
-
-				// Color based on file type
-				var itemColor Color
-				if node.IsDir {
-					itemColor = Yellow // Folders are yellow
-				} else {
-					itemColor = Blue // Files are blue
-				}
+		Background(m.themeColor("editor.bg")).
+		Foreground(m.themeColor("editor.fg"))
 
-				// Highlight selected node with inverted colors
-				var treeStyle lipgloss.Style
-				if nodeIdx == m.fileTreeCursor && m.fileTreeFocused {
-					// Inverted: yellow/blue background with base text
-					treeStyle = lipgloss.NewStyle().
-						Background(lipgloss.Color(ColorToHex(itemColor))).
-						Foreground(lipgloss.Color(ColorToHex(Base)))
-				} else {
-					// Normal: base background with colored text
-					treeStyle = lipgloss.NewStyle().
-						Background(lipgloss.Color(ColorToHex(Base))).
-						Foreground(lipgloss.Color(ColorToHex(itemColor)))
-				}
+	// Calculate visible area (leave 2 lines for status bar and room for panes)
+	visibleHeight := m.editorHeight()
 
-				// Indentation
-				indent := strings.Repeat("  ", node.Depth)
+	// Column layout: [file tree │] editor [│ statistics]
+	treeWidth := m.fileTreeWidth()
+	statsWidth := m.statsPanelWidth()
+	editorWidth := m.editorWidth()
 
-				// Icon
-				icon := "📄 "
-				if node.IsDir {
-					if node.Expanded {
-						icon = "📂 "
-					} else {
-						icon = "📁 "
-					}
-				}
+	// Get flattened file tree nodes
+	var flatNodes []FileNode
+	if m.fileTreeVisible {
+		flatNodes = m.visibleTreeNodes()
+	}
 
-				// Truncate name if too long
-				name := node.Name
-				maxNameLen := treeWidth - len(indent) - len(icon) - 2
-				if len(name) > maxNameLen {
-					name = name[:maxNameLen-1] + "…"
-				}
+	// Statistics are computed once per frame, not per row
+	var statsRows []string
+	if m.statsVisible {
+		statsRows = m.renderStatsPanel(statsWidth, visibleHeight)
+	}
 
-				treeLine = treeStyle.Width(treeWidth).Render(indent + icon + name)
-			} else {
-				// Empty tree line with base background
-				treeLine = baseStyle.Width(treeWidth).Render("")
+	// Get only the wrapped lines we need for the visible area (lazy!)
+	visibleLines := m.getVisibleWrappedLines(m.offsetY, visibleHeight)
+
+	for i := 0; i < visibleHeight; i++ {
+		// Render file tree column
+		if m.fileTreeVisible {
+			switch {
+			case !m.treeFilterShown():
+				sb.WriteString(m.renderFileTreeRow(flatNodes, m.fileTreeOffset+i, treeWidth))
+			case i == 0:
+				sb.WriteString(m.renderTreeFilterRow(treeWidth))
+			default:
+				sb.WriteString(m.renderFileTreeRow(flatNodes, m.fileTreeOffset+i-1, treeWidth))
 			}
 
-			sb.WriteString(treeLine)
-
 			// Divider with base background
-			divider := baseStyle.Render("│")
-			sb.WriteString(divider)
+			sb.WriteString(baseStyle.Render("│"))
+		}
 
-			// Render editor column
-			var editorLine string
-			if i < len(visibleLines) {
-				wl := visibleLines[i]
-				line := wl.text
+		// Render editor column
+		var line string
+		if i < len(visibleLines) {
+			wl := visibleLines[i]
+			line = wl.text
 
 			// Show cursor if this wrapped line is from the current source line
+			// Cursor is visible in both Read and Edit modes
 			if wl.sourceLineY == m.cursorY && !m.fileTreeFocused {
-				// Apply both spell-check and cursor
-				line = m.renderLineWithCursorAndSpellCheck(line, m.cursorX)
+				// Apply both spell-check and cursor highlighting
+				line = m.renderLineWithCursorAndSpellCheck(line, m.cursorX, wl.sourceLineY, wl.startX)
 			} else {
 				// Just apply spell-check highlighting (no cursor)
-				line = m.applySpellCheckHighlighting(line)
+				line = m.applySpellCheckHighlighting(line, wl.sourceLineY, wl.startX)
 			}
 
 			// Apply selection highlighting if active
 			if m.selectionActive {
 				line = m.applySelectionHighlighting(line, wl.sourceLineY, 0)
 			}
+		} else {
+			line = "~" // Empty line indicator
+		}
 
-			// Truncate line if too long for editor width (after styling)
-				// Note: This is a rough truncation and may cut ANSI codes, but it's acceptable
-				if len(line) > editorWidth-1 {
-					line = line[:editorWidth-2] + "…"
-				}
+		// Apply base style with full width to ensure background fills
+		sb.WriteString(baseStyle.Width(editorWidth).Render(line))
 
-				editorLine = baseStyle.Width(editorWidth).Render(line)
-			} else {
-				editorLine = baseStyle.Width(editorWidth).Render("~") // Empty line indicator
-			}
-
-			sb.WriteString(editorLine)
+		// Render statistics column
+		if m.statsVisible {
+			sb.WriteString(baseStyle.Render("│"))
+			sb.WriteString(statsRows[i])
+		}
 
-			if i < visibleHeight-1 {
-				sb.WriteString("\n")
-			}
+		if i < visibleHeight-1 {
+			sb.WriteString("\n")
 		}
-	} else {
-		// No file tree - full width editor
-		// Get only the wrapped lines we need for the visible area (lazy!)
-		visibleLines := m.getVisibleWrappedLines(m.offsetY, visibleHeight)
+	}
 
-		// Render document lines using wrapped lines
-		for i := 0; i < visibleHeight; i++ {
-			var line string
-			if i < len(visibleLines) {
-				wl := visibleLines[i]
-				line = wl.text
+	// Panes sit between the document and the status bar
+	if m.infoVisible {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderInfoPane())
+	}
+	if m.thesaurusVisible {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderThesaurus())
+	}
+	if m.quickfixVisible {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderQuickfix())
+	}
+	if m.completionMenuHeight() > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderCompletionMenu())
+	}
+	if m.paletteVisible {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderPalette())
+	}
+	if m.finderVisible {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderFinder())
+	}
 
-			// Show cursor if this wrapped line is from the current source line
-			// Cursor is visible in both Read and Edit modes
-			if wl.sourceLineY == m.cursorY {
-				// Apply both spell-check and cursor highlighting
-				line = m.renderLineWithCursorAndSpellCheck(line, m.cursorX)
-			} else {
-				// Just apply spell-check highlighting (no cursor)
-				line = m.applySpellCheckHighlighting(line)
-			}
+	// Status bar (2 lines)
+	sb.WriteString("\n")
+	sb.WriteString(m.renderStatusBar())
 
-			// Apply selection highlighting if active
-			if m.selectionActive {
-				line = m.applySelectionHighlighting(line, wl.sourceLineY, 0)
-			}
-			} else {
-				line = "~" // Empty line indicator
-			}
+	return sb.String()
+}
 
-			// Apply base style with full width to ensure background fills
-			styledLine := baseStyle.Width(m.width).Render(line)
-			sb.WriteString(styledLine)
+// fileTreeWidth returns the width of the file tree column (0 when hidden)
+func (m model) fileTreeWidth() int {
+	if !m.fileTreeVisible {
+		return 0
+	}
+	// File tree takes tree_width characters, editor gets the rest (at least 20 when the window is narrow)
+	if m.width > 0 {
+		return max(10, min(m.config.TreeWidth, m.width-20))
+	}
+	return m.config.TreeWidth
+}
 
-			if i < visibleHeight-1 {
-				sb.WriteString("\n")
-			}
+// editorWidth returns the width of the editor column, excluding sidebars and dividers
+func (m model) editorWidth() int {
+	if m.zenMode {
+		return m.zenEditorWidth()
+	}
+
+	width := m.width
+	if m.fileTreeVisible {
+		width -= m.fileTreeWidth() + 1 // +1 for divider
+	}
+	if m.statsVisible {
+		width -= m.statsPanelWidth() + 1
+	}
+	if width < 1 {
+		width = 1
+	}
+	return width
+}
+
+// renderFileTreeRow renders one row of the file tree column
+func (m model) renderFileTreeRow(flatNodes []FileNode, nodeIdx int, treeWidth int) string {
+	if nodeIdx >= len(flatNodes) {
+		// Empty tree line with base background
+		return lipgloss.NewStyle().
+			Background(m.themeColor("tree.bg")).
+			Width(treeWidth).
+			Render("")
+	}
+
+	node := flatNodes[nodeIdx]
+
+	// Color based on file type
+	itemColor := m.themeColor("tree.file")
+	if node.IsDir {
+		itemColor = m.themeColor("tree.dir")
+	}
+
+	// Highlight selected node with inverted colors
+	var treeStyle lipgloss.Style
+	if nodeIdx == m.fileTreeCursor && m.fileTreeFocused {
+		// Inverted: item color background with tree background text
+		treeStyle = lipgloss.NewStyle().
+			Background(itemColor).
+			Foreground(m.themeColor("tree.bg")).
+			Reverse(monochrome())
+	} else {
+		// Normal: tree background with colored text
+		treeStyle = lipgloss.NewStyle().
+			Background(m.themeColor("tree.bg")).
+			Foreground(itemColor)
+	}
+
+	// Indentation
+	indent := strings.Repeat("  ", node.Depth)
+
+	// Icon
+	icon := "📄 "
+	if node.IsDir {
+		if node.Expanded {
+			icon = "📂 "
+		} else {
+			icon = "📁 "
 		}
 	}
 
-	// Status bar (2 lines)
-	sb.WriteString("\n")
-	sb.WriteString(m.renderStatusBar())
+	// Folders being read, or listed only in part, say so
+	name := node.Name
+	if node.Loading {
+		name += " (loading…)"
+	} else if node.Expanded && node.Hidden > 0 {
+		name += fmt.Sprintf(" (+%d more)", node.Hidden)
+	}
 
-	return sb.String()
+	// Details from tree_columns go on the right while the name has room
+	maxNameLen := treeWidth - len(indent) - len(icon) - 2
+	badges := m.treeBadges(node)
+	if badges != "" && maxNameLen-len(badges)-1 >= 6 {
+		maxNameLen -= len(badges) + 1
+	} else {
+		badges = ""
+	}
+
+	// Truncate name if too long
+	name = truncateRunes(name, maxNameLen)
+	if badges != "" {
+		name += strings.Repeat(" ", maxNameLen-utf8.RuneCountInString(name)) + " " + badges
+	}
+
+	return treeStyle.Width(treeWidth).Render(indent + icon + name)
 }
 
 // stripAnsi removes ANSI escape sequences for length calculation
@@ -197,52 +252,79 @@ func stripAnsi(s string) string {
 func (m model) renderStatusBar() string {
 	// Status bar styles
 	statusStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color(ColorToHex(Surface0))).
-		Foreground(lipgloss.Color(ColorToHex(Text))).
+		Background(m.themeColor("status.bg")).
+		Foreground(m.themeColor("status.fg")).
+		Reverse(monochrome()).
 		Width(m.width)
 
 	commandStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color(ColorToHex(Mantle))).
-		Foreground(lipgloss.Color(ColorToHex(Text))).
+		Background(m.themeColor("message.bg")).
+		Foreground(m.themeColor("message.fg")).
 		Width(m.width)
 
 	modifiedIndicator := ""
 	if m.modified {
 		modifiedIndicator = " [+]"
 	}
+	if m.readOnly {
+		modifiedIndicator += " [RO]"
+	}
 
-	// First line: mode, filename, position (Surface0 background)
+	// First line: mode, filename, position
+	modeColor := m.themeColor("status.mode.read")
+	if m.mode == EditMode {
+		modeColor = m.themeColor("status.mode.edit")
+	}
+	modeStyle := lipgloss.NewStyle().
+		Background(m.themeColor("status.bg")).
+		Foreground(modeColor).
+		Reverse(monochrome()).
+		Bold(true)
 	leftStatus := fmt.Sprintf(" %s | %s%s", m.mode, m.filename, modifiedIndicator)
-	rightStatus := fmt.Sprintf("Ln %d, Col %d ", m.cursorY+1, m.cursorX+1)
+	rightStatus := fmt.Sprintf("%s | Ln %d, Col %d ", m.wordCountStatus(), m.cursorY+1, m.cursorX+1)
+	if goal := m.goalStatus(); goal != "" {
+		rightStatus = goal + " | " + rightStatus
+	}
+	if sprint := m.sprintStatus(); sprint != "" {
+		rightStatus = sprint + " | " + rightStatus
+	}
+	if pending := m.pendingKeysStatus(); pending != "" {
+		rightStatus = pending + " | " + rightStatus
+	}
 
-	padding := m.width - len(leftStatus) - len(rightStatus)
+	padding := m.width - lipgloss.Width(leftStatus) - lipgloss.Width(rightStatus)
 	if padding < 0 {
 		padding = 0
 	}
 
-	statusLine1 := statusStyle.Render(leftStatus + strings.Repeat(" ", padding) + rightStatus)
+	// The mode label is colored; the rest of the line keeps the status colors
+	modeLabel := " " + m.mode.String()
+	statusLine1 := statusStyle.UnsetWidth().Render(" ") + modeStyle.Render(m.mode.String()) +
+		statusStyle.UnsetWidth().Render(strings.TrimPrefix(leftStatus, modeLabel)+strings.Repeat(" ", padding)+rightStatus)
 
-	// Second line: status message or help (Mantle background for visual separation)
+	// Second line: status message or help (separate background for visual separation)
 	var commandText string
-	if m.statusMsg.Text != "" && time.Since(m.statusMsg.Timestamp) < 3*time.Second {
+	if m.commandMode {
+		// Show the command being typed, with its cursor
+		return statusLine1 + "\n" + m.renderCommandLine(commandStyle)
+	} else if m.statusMsg.Text != "" && time.Since(m.statusMsg.Timestamp) < 3*time.Second {
 		// Show temporary status message with appropriate color
-		var msgColor Color
+		msgColor := m.themeColor("message.fg")
 		switch m.statusMsg.Color {
 		case "green":
-			msgColor = Green
+			msgColor = m.themeColor("message.success")
 		case "yellow":
-			msgColor = Yellow
+			msgColor = m.themeColor("message.warning")
 		case "red":
-			msgColor = Red
-		default:
-			msgColor = Text
+			msgColor = m.themeColor("message.error")
 		}
 		// Use lipgloss for consistent color rendering
-		msgStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(ColorToHex(msgColor)))
+		msgStyle := lipgloss.NewStyle().Foreground(msgColor)
 		commandText = msgStyle.Render(m.statusMsg.Text)
-	} else if m.commandMode {
-		// Show command buffer when in command mode
-		commandText = m.commandBuffer
+	} else if finding, ok := findingAt(m.lintFindingsForLine(m.cursorY), m.cursorX); ok {
+		// Explain the lint finding under the cursor
+		msgStyle := lipgloss.NewStyle().Foreground(m.themeColor("lint.warning"))
+		commandText = msgStyle.Render("[" + finding.Rule + "] " + finding.Message)
 	} else {
 		// Show help text
 		if m.mode == ReadMode {
@@ -257,6 +339,31 @@ func (m model) renderStatusBar() string {
 	return statusLine1 + "\n" + statusLine2
 }
 
+// statusBarHeight returns the rows used by the status bar. Zen mode hides it,
+// showing only the command line while a command is typed.
+func (m model) statusBarHeight() int {
+	if m.zenMode {
+		if m.commandMode {
+			return 1
+		}
+		return 0
+	}
+	return 2
+}
+
+// wordCountStatus returns the live word count, e.g. "1,204 words" or "37/1,204 words"
+func (m model) wordCountStatus() string {
+	total := m.wordCounter.count(m.lines)
+	if m.selectionActive {
+		selected := countWords(strings.Split(m.selectedText(), "\n"))
+		return formatCount(selected) + "/" + formatCount(total) + " words"
+	}
+	if total == 1 {
+		return "1 word"
+	}
+	return formatCount(total) + " words"
+}
+
 // setStatus sets a status message with color
 func (m *model) setStatus(text string, color string) {
 	m.statusMsg = StatusMessage{
@@ -266,10 +373,15 @@ func (m *model) setStatus(text string, color string) {
 	}
 }
 
-// applySpellCheckHighlighting applies red background to misspelled words in a line
-func (m model) applySpellCheckHighlighting(line string) string {
-	// Skip if spell checker is disabled
-	if m.spellChecker == nil || !m.spellChecker.enabled {
+// applySpellCheckHighlighting applies red background to misspelled words in a line.
+// lineY and lineOffset locate the wrapped segment in the source line so that
+// inline language spans are honoured.
+func (m model) applySpellCheckHighlighting(line string, lineY int, lineOffset int) string {
+	spellCheckEnabled := m.spellChecker != nil && m.spellChecker.enabled
+	findings := m.lintFindingsForLine(lineY)
+
+	// Skip if neither spell checking nor linting has anything to show
+	if !spellCheckEnabled && len(findings) == 0 {
 		return line
 	}
 
@@ -283,10 +395,10 @@ func (m model) applySpellCheckHighlighting(line string) string {
 	var result strings.Builder
 	lastEnd := 0
 
-	// Style for misspelled words - red background with base (dark) text
-	errorStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color(ColorToHex(Red))).
-		Foreground(lipgloss.Color(ColorToHex(Base)))
+	var spans []langSpan
+	if spellCheckEnabled {
+		spans = m.spellSpansForLine(lineY)
+	}
 
 	for _, w := range words {
 		// Add text before this word
@@ -294,10 +406,9 @@ func (m model) applySpellCheckHighlighting(line string) string {
 			result.WriteString(line[lastEnd:w.start])
 		}
 
-		// Check if word is misspelled
-		if !m.spellChecker.checkWord(w.word) {
-			// Highlight misspelled word
-			result.WriteString(errorStyle.Render(w.word))
+		// Misspelled words take precedence over lint findings
+		if style := m.wordStyle(w, spans, findings, lineOffset); style != nil {
+			result.WriteString(style.Render(w.word))
 		} else {
 			// Word is correct, render normally
 			result.WriteString(w.word)
@@ -314,8 +425,31 @@ func (m model) applySpellCheckHighlighting(line string) string {
 	return result.String()
 }
 
+// wordStyle returns the highlight style for a word: the spell error colors when
+// misspelled, a warning underline when covered by a lint finding, nil otherwise
+func (m model) wordStyle(w wordPos, spans []langSpan, findings []LintFinding, lineOffset int) *lipgloss.Style {
+	pos := lineOffset + w.start
+	if m.spellChecker != nil && m.spellChecker.enabled && !m.spellChecker.checkWordIn(w.word, spanLanguageAt(spans, pos)) {
+		// Misspelled word - error background with contrasting text
+		style := lipgloss.NewStyle().
+			Background(m.themeColor("spell.error.bg")).
+			Foreground(m.themeColor("spell.error.fg")).
+			Underline(monochrome()).
+			Bold(monochrome())
+		return &style
+	}
+	if _, ok := findingAt(findings, pos); ok {
+		// Lint finding - underlined in the warning color
+		style := lipgloss.NewStyle().
+			Underline(true).
+			Foreground(m.themeColor("lint.warning"))
+		return &style
+	}
+	return nil
+}
+
 // renderLineWithCursorAndSpellCheck renders a line with both cursor and spell-check highlighting
-func (m model) renderLineWithCursorAndSpellCheck(line string, cursorPos int) string {
+func (m model) renderLineWithCursorAndSpellCheck(line string, cursorPos int, lineY int, lineOffset int) string {
 	// First, work out which character will have the cursor
 	if cursorPos < 0 {
 		cursorPos = 0
@@ -328,18 +462,20 @@ func (m model) renderLineWithCursorAndSpellCheck(line string, cursorPos int) str
 	words := getWordsInLine(line)
 	
 	// Styles
-	errorStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color(ColorToHex(Red))).
-		Foreground(lipgloss.Color(ColorToHex(Base)))
-	
 	cursorStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color(ColorToHex(Maroon))).
-		Foreground(lipgloss.Color(ColorToHex(Base)))
+		Background(m.themeColor("cursor.bg")).
+		Foreground(m.themeColor("cursor.fg")).
+		Reverse(monochrome())
 	
 	// Build result
 	var result strings.Builder
 	lastEnd := 0
 	spellCheckEnabled := m.spellChecker != nil && m.spellChecker.enabled
+	var spans []langSpan
+	if spellCheckEnabled {
+		spans = m.spellSpansForLine(lineY)
+	}
+	findings := m.lintFindingsForLine(lineY)
 	
 	for _, w := range words {
 		// Render text before this word
@@ -371,7 +507,7 @@ func (m model) renderLineWithCursorAndSpellCheck(line string, cursorPos int) str
 		}
 		
 		// Render the word
-		isMisspelled := spellCheckEnabled && !m.spellChecker.checkWord(w.word)
+		style := m.wordStyle(w, spans, findings, lineOffset)
 		
 		// Check if cursor is in this word
 		if cursorPos >= w.start && cursorPos < w.end {
@@ -383,8 +519,8 @@ func (m model) renderLineWithCursorAndSpellCheck(line string, cursorPos int) str
 				after = w.word[relPos+1:]
 			}
 			
-			if isMisspelled {
-				result.WriteString(errorStyle.Render(before))
+			if style != nil {
+				result.WriteString(style.Render(before))
 			} else {
 				result.WriteString(before)
 			}
@@ -395,15 +531,15 @@ func (m model) renderLineWithCursorAndSpellCheck(line string, cursorPos int) str
 				result.WriteString(cursorChar)
 			}
 			
-			if isMisspelled {
-				result.WriteString(errorStyle.Render(after))
+			if style != nil {
+				result.WriteString(style.Render(after))
 			} else {
 				result.WriteString(after)
 			}
 		} else {
 			// No cursor in word
-			if isMisspelled {
-				result.WriteString(errorStyle.Render(w.word))
+			if style != nil {
+				result.WriteString(style.Render(w.word))
 			} else {
 				result.WriteString(w.word)
 			}
@@ -439,6 +575,14 @@ func (m model) renderLineWithCursorAndSpellCheck(line string, cursorPos int) str
 	return result.String()
 }
 
+// spellSpansForLine returns the inline language spans of a source line
+func (m model) spellSpansForLine(lineY int) []langSpan {
+	if lineY < 0 || lineY >= len(m.lines) {
+		return nil
+	}
+	return parseLanguageSpans(m.lines[lineY])
+}
+
 // applySelectionHighlighting applies blue highlight background to selected text
 func (m model) applySelectionHighlighting(line string, lineY int, lineOffset int) string {
 	if !m.selectionActive {
@@ -495,8 +639,9 @@ func (m model) applySelectionHighlighting(line string, lineY int, lineOffset int
 	// A full implementation would need to parse and re-apply ANSI codes
 	// For now, we'll apply selection over the plain text
 	selectionStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color(ColorToHex(Blue))).
-		Foreground(lipgloss.Color(ColorToHex(Base)))
+		Background(m.themeColor("selection.bg")).
+		Foreground(m.themeColor("selection.fg")).
+		Reverse(monochrome())
 
 	if selStart == 0 && selEnd >= len(line) {
 		// Entire line is selected
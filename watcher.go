@@ -0,0 +1,298 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// treeEventDelay gathers a burst of filesystem events (a save, a checkout) into one refresh
+const treeEventDelay = 150 * time.Millisecond
+
+// treePollInterval is how often folders are checked when they can't be watched
+const treePollInterval = 2 * time.Second
+
+// treeChangedMsg reports folders whose entries changed on disk
+type treeChangedMsg struct {
+	dirs []string
+}
+
+// treePollMsg is sent periodically to check polled folders for changes
+type treePollMsg time.Time
+
+// treeWatcher keeps the file tree's expanded folders up to date. It uses
+// inotify (or the platform's equivalent) and falls back to polling folder
+// modification times for folders that can't be watched.
+type treeWatcher struct {
+	fs      *fsnotify.Watcher    // nil when watching isn't available
+	changes chan treeChangedMsg  // batched events from the fsnotify goroutine
+	watched map[string]bool      // folders added to fs
+	polled  map[string]time.Time // folders checked by polling, with their last modification time
+	writes  atomic.Bool          // report files being written, when the tree shows their details
+}
+
+// newTreeWatcher starts watching; folders are added with sync
+func newTreeWatcher() *treeWatcher {
+	w := &treeWatcher{
+		changes: make(chan treeChangedMsg),
+		watched: make(map[string]bool),
+		polled:  make(map[string]time.Time),
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		LogWarningf("File watching unavailable, polling the file tree instead: %v", err)
+		return w
+	}
+	w.fs = fsw
+	go w.run()
+	return w
+}
+
+// run gathers fsnotify events into batches of changed folders
+func (w *treeWatcher) run() {
+	pending := make(map[string]bool)
+	var timer <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			// Only entries appearing, disappearing or being renamed change the tree,
+			// and edits to an ignore or order file (or any file, when details are shown)
+			changesEntries := event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+			name := filepath.Base(event.Name)
+			changesTree := w.writes.Load() || containsString(ignoreFiles, name) || name == treeOrderFile
+			if !changesEntries && !(event.Has(fsnotify.Write) && changesTree) {
+				continue
+			}
+			pending[filepath.Dir(event.Name)] = true
+			if timer == nil {
+				timer = time.After(treeEventDelay)
+			}
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			LogWarningf("File watcher: %v", err)
+		case <-timer:
+			dirs := make([]string, 0, len(pending))
+			for dir := range pending {
+				dirs = append(dirs, dir)
+			}
+			sort.Strings(dirs)
+			w.changes <- treeChangedMsg{dirs: dirs}
+			pending = make(map[string]bool)
+			timer = nil
+		}
+	}
+}
+
+// wait returns a command that delivers the next batch of changes
+func (w *treeWatcher) wait() tea.Cmd {
+	if w == nil || w.fs == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return <-w.changes
+	}
+}
+
+// tickTreePoll returns a command that sends treePollMsg after treePollInterval
+func tickTreePoll() tea.Cmd {
+	return tea.Tick(treePollInterval, func(t time.Time) tea.Msg {
+		return treePollMsg(t)
+	})
+}
+
+// sync watches exactly the given folders
+func (w *treeWatcher) sync(dirs []string) {
+	want := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		want[dir] = true
+	}
+
+	for dir := range w.watched {
+		if !want[dir] {
+			w.fs.Remove(dir)
+			delete(w.watched, dir)
+		}
+	}
+	for dir := range w.polled {
+		if !want[dir] {
+			delete(w.polled, dir)
+		}
+	}
+
+	for _, dir := range dirs {
+		if w.watched[dir] {
+			continue
+		}
+		if _, ok := w.polled[dir]; ok {
+			continue
+		}
+		if w.fs != nil {
+			err := w.fs.Add(dir)
+			if err == nil {
+				w.watched[dir] = true
+				continue
+			}
+			// Usually the inotify watch limit; this folder is polled instead
+			LogWarningf("Can't watch %s, polling it instead: %v", dir, err)
+		}
+		w.polled[dir] = dirModTime(dir)
+	}
+}
+
+// poll returns the polled folders whose modification time changed
+func (w *treeWatcher) poll() []string {
+	var changed []string
+	for dir, last := range w.polled {
+		if now := dirModTime(dir); !now.Equal(last) {
+			w.polled[dir] = now
+			changed = append(changed, dir)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// close stops watching
+func (w *treeWatcher) close() {
+	if w != nil && w.fs != nil {
+		w.fs.Close()
+	}
+}
+
+// dirModTime returns when a folder's entries last changed (zero if it is gone)
+func dirModTime(dir string) time.Time {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// expandedTreeDirs lists the tree root and every expanded folder that has been read
+func (m model) expandedTreeDirs() []string {
+	dirs := []string{m.fileTreeRoot}
+	var walk func(nodes []FileNode)
+	walk = func(nodes []FileNode) {
+		for _, node := range nodes {
+			if node.IsDir && node.Expanded && node.Loaded {
+				dirs = append(dirs, node.Path)
+				walk(node.Children)
+			}
+		}
+	}
+	walk(m.fileTreeNodes)
+	return dirs
+}
+
+// syncTreeWatches watches the folders currently shown in the tree
+func (m *model) syncTreeWatches() {
+	if m.treeWatcher == nil || m.fileTreeRoot == "" {
+		return
+	}
+	m.treeWatcher.writes.Store(len(m.config.TreeColumns) > 0)
+	m.treeWatcher.sync(m.expandedTreeDirs())
+}
+
+// treeWatchCmds returns the commands that keep the tree watcher running
+func (m model) treeWatchCmds() tea.Cmd {
+	if m.treeWatcher == nil {
+		return nil
+	}
+	return tea.Batch(m.treeWatcher.wait(), tickTreePoll())
+}
+
+// refreshTreeDir returns a command that reads a shown folder again
+func (m model) refreshTreeDir(dir string) tea.Cmd {
+	depth := 0
+	if dir != m.fileTreeRoot {
+		node := findTreeNode(m.fileTreeNodes, dir)
+		if node == nil || !node.Loaded {
+			return nil // not shown; it is read when expanded
+		}
+		depth = node.Depth + 1
+	}
+	cmd := loadTreeDir(m.fileTreeRoot, dir, depth, m.treeFilter(), m.config.TreeMaxEntries)
+	return func() tea.Msg {
+		msg := cmd().(treeDirLoadedMsg)
+		msg.refresh = true
+		return msg
+	}
+}
+
+// handleTreeChanged re-reads folders that changed on disk
+func (m model) handleTreeChanged(dirs []string) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, dir := range dirs {
+		if cmd := m.refreshTreeDir(dir); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// mergeTreeChildren takes a folder's fresh entries, keeping the expansion state
+// and loaded contents of entries that were already there (but their fresh details)
+func mergeTreeChildren(old []FileNode, fresh []FileNode) []FileNode {
+	byPath := make(map[string]FileNode, len(old))
+	for _, node := range old {
+		byPath[node.Path] = node
+	}
+	for i, node := range fresh {
+		if prev, ok := byPath[node.Path]; ok && prev.IsDir == node.IsDir {
+			prev.ModTime, prev.Words = node.ModTime, node.Words
+			fresh[i] = prev
+		}
+	}
+	return fresh
+}
+
+// applyTreeRefresh replaces a folder's entries after a refresh, keeping the
+// selection on the same entry (or in the same place if it went away)
+func (m *model) applyTreeRefresh(msg treeDirLoadedMsg) {
+	if msg.err != nil {
+		// The folder itself went away; its parent's refresh removes it
+		LogDebugf("Refresh of %s: %v", msg.path, msg.err)
+		return
+	}
+
+	flat := m.visibleTreeNodes()
+	selected := ""
+	if m.fileTreeCursor < len(flat) {
+		selected = flat[m.fileTreeCursor].Path
+	}
+
+	if msg.path == m.fileTreeRoot {
+		m.fileTreeNodes = mergeTreeChildren(m.fileTreeNodes, msg.children)
+	} else {
+		node := findTreeNode(m.fileTreeNodes, msg.path)
+		if node == nil || !node.Loaded {
+			return
+		}
+		node.Children = mergeTreeChildren(node.Children, msg.children)
+		node.Hidden = msg.hidden
+	}
+
+	for i, node := range m.visibleTreeNodes() {
+		if node.Path == selected {
+			m.fileTreeCursor = i
+			break
+		}
+	}
+	if n := len(m.visibleTreeNodes()); m.fileTreeCursor >= n {
+		m.fileTreeCursor = max(0, n-1)
+	}
+	if m.fileTreeOffset > m.fileTreeCursor {
+		m.fileTreeOffset = m.fileTreeCursor
+	}
+	m.syncTreeWatches()
+}
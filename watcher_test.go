@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTreeRefreshKeepsState(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"chapters/one.md": "",
+		"outline.md":      "",
+		"zebra.md":        "",
+	})
+	nodes, err := buildFileTree(root, treeFilter{root: root}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{
+		config:          defaultConfig(),
+		height:          24,
+		fileTreeRoot:    root,
+		fileTreeNodes:   nodes,
+		fileTreeVisible: true,
+		fileTreeFocused: true,
+	}
+	m.selectTreePath(filepath.Join(root, "chapters", "one.md"))
+	m.selectTreePath(filepath.Join(root, "outline.md"))
+
+	// Another program adds files and removes one
+	for _, name := range []string{"chapters/two.md", "notes.md"} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Remove(filepath.Join(root, "zebra.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{root, filepath.Join(root, "chapters")} {
+		result, _ := m.Update(m.refreshTreeDir(dir)())
+		m = result.(model)
+	}
+	want := []string{"chapters", "chapters/one.md", "chapters/two.md", "notes.md", "outline.md"}
+	if got := treePaths(m, root); !reflect.DeepEqual(got, want) {
+		t.Errorf("tree = %q, want %q", got, want)
+	}
+	if selected := flattenFileTree(m.fileTreeNodes)[m.fileTreeCursor].Name; selected != "outline.md" {
+		t.Errorf("selection moved to %q", selected)
+	}
+
+	// Folders that aren't shown aren't read
+	if cmd := m.refreshTreeDir(filepath.Join(root, "elsewhere")); cmd != nil {
+		t.Error("refreshing an unknown folder should do nothing")
+	}
+}
+
+func TestTreePolling(t *testing.T) {
+	dir := t.TempDir()
+	w := &treeWatcher{watched: map[string]bool{}, polled: map[string]time.Time{}}
+	w.sync([]string{dir})
+	if changed := w.poll(); len(changed) != 0 {
+		t.Errorf("nothing changed yet, got %q", changed)
+	}
+
+	// Make sure the folder's modification time moves on
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(dir, past, past); err != nil {
+		t.Fatal(err)
+	}
+	w.polled[dir] = past
+	if err := os.WriteFile(filepath.Join(dir, "new.md"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed := w.poll(); !reflect.DeepEqual(changed, []string{dir}) {
+		t.Errorf("poll = %q", changed)
+	}
+
+	w.sync(nil)
+	if len(w.polled) != 0 {
+		t.Error("sync should stop polling folders no longer shown")
+	}
+}
+
+func TestTreeWatcherEvents(t *testing.T) {
+	w := newTreeWatcher()
+	defer w.close()
+	if w.fs == nil {
+		t.Skip("file watching is not available here")
+	}
+	dir := t.TempDir()
+	w.sync([]string{dir})
+	if !w.watched[dir] {
+		t.Skip("this folder can't be watched")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "new.md"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-w.changes:
+		if !reflect.DeepEqual(msg.dirs, []string{dir}) {
+			t.Errorf("changed = %q", msg.dirs)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+}
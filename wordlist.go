@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Word lists accepted by the spell checker in every language
+const (
+	personalWordsFile = "words.txt"       // in the config directory
+	projectWordsFile  = ".tuiwrite-words" // in the project directory
+)
+
+// readWordList reads one word per line, skipping blank lines and # comments.
+// A missing file is an empty list.
+func readWordList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words, scanner.Err()
+}
+
+// wordListPaths returns the personal and project word lists, in that order
+func wordListPaths(projectDir string) []string {
+	var paths []string
+	if configDir, err := getConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(configDir, personalWordsFile))
+	}
+	if projectDir != "" {
+		paths = append(paths, filepath.Join(projectDir, projectWordsFile))
+	}
+	return paths
+}
+
+// loadWordLists replaces the accepted words with the personal and project lists
+func (sc *SpellChecker) loadWordLists(projectDir string) {
+	sc.knownWords = make(map[string]bool)
+	for _, path := range wordListPaths(projectDir) {
+		words, err := readWordList(path)
+		if err != nil {
+			LogWarningf("Failed to read word list %s: %v", path, err)
+			continue
+		}
+		for _, word := range words {
+			sc.knownWords[word] = true
+		}
+		if len(words) > 0 {
+			LogInfof("Loaded %d words from %s", len(words), path)
+		}
+	}
+}
+
+// isKnownWord reports whether a word list accepts the word. A lowercase
+// entry accepts any capitalisation; other entries must match exactly.
+func (sc *SpellChecker) isKnownWord(word string) bool {
+	return sc.knownWords[word] || sc.knownWords[strings.ToLower(word)]
+}
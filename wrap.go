@@ -1,6 +1,6 @@
 package main
 
-import "strings"
+import "unicode"
 
 // getVisibleWrappedLines returns only the wrapped lines needed for the current viewport
 // This is the core of the lazy wrapping system - only wraps what's visible!
@@ -10,10 +10,7 @@ func (m *model) getVisibleWrappedLines(startOffset int, count int) []wrappedLine
 	}
 
 	// Calculate wrap width
-	wrapWidth := m.width - 2
-	if wrapWidth < 20 {
-		wrapWidth = 20
-	}
+	wrapWidth := m.textWrapWidth()
 
 	// If width changed, invalidate all cache
 	if m.wrapWidth != wrapWidth {
@@ -62,15 +59,16 @@ func (m *model) getWrappedLine(lineIdx int) []wrappedLine {
 	}
 
 	line := m.lines[lineIdx]
-	wrappedTexts := wrapLine(line, m.wrapWidth)
+	segments := wrapLineSegments(line, m.wrapWidth)
 
 	// Convert to wrappedLine structs
-	result := make([]wrappedLine, len(wrappedTexts))
-	for i, text := range wrappedTexts {
+	result := make([]wrappedLine, len(segments))
+	for i, segment := range segments {
 		result[i] = wrappedLine{
-			text:        text,
+			text:        segment.text,
 			sourceLineY: lineIdx,
-			isLastWrap:  i == len(wrappedTexts)-1,
+			startX:      segment.start,
+			isLastWrap:  i == len(segments)-1,
 		}
 	}
 
@@ -112,10 +110,7 @@ func (m *model) rewrapLines() {
 	}
 
 	// Calculate wrap width
-	wrapWidth := m.width - 2
-	if wrapWidth < 20 {
-		wrapWidth = 20
-	}
+	wrapWidth := m.textWrapWidth()
 
 	// If width changed, invalidate cache
 	if m.wrapWidth != wrapWidth {
@@ -127,74 +122,106 @@ func (m *model) rewrapLines() {
 	m.adjustViewport()
 }
 
+// textWrapWidth returns the width text is wrapped to, based on the editor column
+func (m model) textWrapWidth() int {
+	wrapWidth := m.editorWidth() - 2
+	if wrapWidth < 20 {
+		wrapWidth = 20
+	}
+	return wrapWidth
+}
+
+// wrapSegment is one row of a wrapped line
+type wrapSegment struct {
+	text  string // the row's text, a substring of the source line
+	start int    // byte offset of the row within the source line
+}
+
 // wrapLine wraps a single line to the specified width
 func wrapLine(line string, width int) []string {
-	if len(line) == 0 {
-		return []string{""}
+	segments := wrapLineSegments(line, width)
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.text
 	}
+	return texts
+}
 
-	if len(line) <= width {
-		return []string{line}
+// wrapLineSegments wraps a line at spaces to the specified width, breaking
+// words longer than the width. The whitespace at each wrap point is dropped,
+// so each row records where it starts in the source line.
+func wrapLineSegments(line string, width int) []wrapSegment {
+	if len(line) <= width || width <= 0 {
+		return []wrapSegment{{text: line}}
 	}
 
-	var wrapped []string
-	words := strings.Fields(line)
-
-	if len(words) == 0 {
-		// Line is only whitespace
-		return []string{line}
+	var wrapped []wrapSegment
+	rowStart, rowEnd := 0, -1 // rowEnd < 0 until the row has a word
+	flush := func() {
+		if rowEnd >= 0 {
+			wrapped = append(wrapped, wrapSegment{line[rowStart:rowEnd], rowStart})
+		}
+		rowEnd = -1
 	}
 
-	currentLine := ""
+	for _, word := range wordBounds(line) {
+		start, end := word[0], word[1]
 
-	for _, word := range words {
 		// If word itself is longer than width, break it
-		if len(word) > width {
-			if currentLine != "" {
-				wrapped = append(wrapped, currentLine)
-				currentLine = ""
-			}
-			// Break long word across lines
-			for len(word) > width {
-				wrapped = append(wrapped, word[:width])
-				word = word[width:]
-			}
-			if len(word) > 0 {
-				currentLine = word
+		if end-start > width {
+			flush()
+			for end-start > width {
+				wrapped = append(wrapped, wrapSegment{line[start : start+width], start})
+				start += width
 			}
+			rowStart, rowEnd = start, end
 			continue
 		}
 
-		// Try adding word to current line
-		testLine := currentLine
-		if testLine != "" {
-			testLine += " "
-		}
-		testLine += word
-
-		if len(testLine) <= width {
-			currentLine = testLine
-		} else {
-			// Word doesn't fit, start new line
-			if currentLine != "" {
-				wrapped = append(wrapped, currentLine)
+		switch {
+		case rowEnd < 0:
+			// First word of the row; leading whitespace only stays if it fits
+			if end-rowStart > width {
+				rowStart = start
 			}
-			currentLine = word
+			rowEnd = end
+		case end-rowStart <= width:
+			rowEnd = end
+		default:
+			// Word doesn't fit, start new row
+			flush()
+			rowStart, rowEnd = start, end
 		}
 	}
-
-	// Add remaining text
-	if currentLine != "" {
-		wrapped = append(wrapped, currentLine)
-	}
+	flush()
 
 	if len(wrapped) == 0 {
-		return []string{""}
+		// Line is only whitespace
+		return []wrapSegment{{text: line}}
 	}
-
 	return wrapped
 }
 
+// wordBounds returns the start and end byte offsets of the whitespace-separated words of a line
+func wordBounds(line string) [][2]int {
+	var bounds [][2]int
+	start := -1
+	for i, r := range line {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				bounds = append(bounds, [2]int{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		bounds = append(bounds, [2]int{start, len(line)})
+	}
+	return bounds
+}
+
 // getWrappedLineIndexForCursor returns the wrapped line index for the cursor position
 func (m *model) getWrappedLineIndexForCursor() int {
 	wrappedIdx := 0
@@ -211,15 +238,12 @@ func (m *model) getWrappedLineIndexForCursor() int {
 		wrappedForLine := m.getWrappedLine(m.cursorY)
 
 		// Determine which wrapped segment contains the cursor based on cursorX
-		charCount := 0
-		for i, wl := range wrappedForLine {
-			lineLen := len(wl.text)
-			if m.cursorX <= charCount+lineLen || i == len(wrappedForLine)-1 {
+		for i := range wrappedForLine {
+			if i == len(wrappedForLine)-1 || m.cursorX < wrappedForLine[i+1].startX {
 				// Cursor is in this wrapped line
 				wrappedIdx += i
 				break
 			}
-			charCount += lineLen
 		}
 	}
 
@@ -242,14 +266,12 @@ func (m *model) moveToWrappedLine(targetWrappedIdx int) bool {
 	// Calculate visual X position within current wrapped line
 	if m.cursorY < len(m.lines) {
 		wrappedForCurrentLine := m.getWrappedLine(m.cursorY)
-		charCount := 0
 		for _, wl := range wrappedForCurrentLine {
 			if currentWrappedIdx == wrappedIdx {
-				visualX = m.cursorX - charCount
+				visualX = max(0, m.cursorX-wl.startX)
 				break
 			}
 			wrappedIdx++
-			charCount += len(wl.text)
 		}
 	}
 
@@ -266,14 +288,9 @@ func (m *model) moveToWrappedLine(targetWrappedIdx int) bool {
 			// Update cursor Y position
 			m.cursorY = lineIdx
 
-			// Calculate the starting character position for this wrapped line
-			charPos := 0
-			for i := 0; i < wrappedOffset && i < len(wrappedForLine); i++ {
-				charPos += len(wrappedForLine[i].text)
-			}
-
 			// Try to position cursor at similar visual X within this wrapped line
 			if wrappedOffset < len(wrappedForLine) {
+				charPos := wrappedForLine[wrappedOffset].startX
 				wrapLineText := wrappedForLine[wrappedOffset].text
 				newCursorX := charPos + visualX
 
@@ -315,13 +332,7 @@ func (m *model) getSourceLineFromWrappedIndex(wrappedIdx int) (int, int) {
 			// Target is within this source line
 			wrappedOffset := wrappedIdx - currentWrappedIdx
 
-			// Calculate character offset
-			charOffset := 0
-			for i := 0; i < wrappedOffset && i < len(wrappedForLine); i++ {
-				charOffset += len(wrappedForLine[i].text)
-			}
-
-			return lineIdx, charOffset
+			return lineIdx, wrappedForLine[wrappedOffset].startX
 		}
 
 		currentWrappedIdx += len(wrappedForLine)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -147,3 +148,35 @@ func TestInvalidateAllCache(t *testing.T) {
 		t.Errorf("Expected 0 cached lines after full invalidation, got %d", len(m.wrapCache))
 	}
 }
+
+// TestWrappedSegmentOffsets verifies each segment's startX is where its text
+// really starts in the source line, past the whitespace dropped at wrap points
+func TestWrappedSegmentOffsets(t *testing.T) {
+	line := "alpha beta gamma delta epsilon zeta  eta   theta iota kappa lambdamuxinomicron pi"
+	m := model{lines: []string{line}, wrapWidth: 12, wrapCache: make(map[int][]wrappedLine)}
+
+	segments := m.getWrappedLine(0)
+	if len(segments) < 5 {
+		t.Fatalf("expected the line to wrap, got %d segments", len(segments))
+	}
+	from := 0
+	for i, wl := range segments {
+		want := from + strings.Index(line[from:], wl.text)
+		if wl.startX != want || line[wl.startX:wl.startX+len(wl.text)] != wl.text {
+			t.Errorf("segment %d %q: startX = %d, want %d", i, wl.text, wl.startX, want)
+		}
+		if len(wl.text) > 12 {
+			t.Errorf("segment %d %q is wider than the wrap width", i, wl.text)
+		}
+		from = wl.startX + len(wl.text)
+	}
+	if got := []int{segments[1].startX, segments[2].startX, segments[3].startX}; got[0] != 11 || got[1] != 23 || got[2] != 37 {
+		t.Errorf("startX of rows 2-4 = %v", got)
+	}
+
+	// The cursor's row follows the real offsets
+	m.cursorX = segments[2].startX
+	if idx := m.getWrappedLineIndexForCursor(); idx != 2 {
+		t.Errorf("cursor at the start of row 3 is on row %d", idx+1)
+	}
+}
@@ -1,22 +1,39 @@
 package main
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	"fmt"
+	"strconv"
+	"strings"
 
-// toggleZenMode toggles between normal and zen (fullscreen/borderless) mode
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultZenMeasure is the default text column width in zen mode
+const defaultZenMeasure = 70
+
+// Zen focus modes: which text stays bright while the rest is dimmed
+const (
+	zenFocusParagraph = "paragraph"
+	zenFocusSentence  = "sentence"
+	zenFocusOff       = "off"
+)
+
+// toggleZenMode toggles between normal and zen (distraction-free) mode
 func (m model) toggleZenMode() (tea.Model, tea.Cmd) {
 	m.zenMode = !m.zenMode
 
 	if m.zenMode {
-		LogEvent("ZEN_MODE", "Entered zen mode (fullscreen)")
+		LogEvent("ZEN_MODE", fmt.Sprintf("Entered zen mode (measure=%d, focus=%s)", m.config.ZenMeasure, m.config.ZenFocus))
+		m.fileTreeFocused = false
 		m.setStatus("Zen Mode: ON (F11 to exit)", "green")
-		// Enter fullscreen/borderless mode
-		return m, tea.EnterAltScreen
 	} else {
 		LogEvent("ZEN_MODE", "Exited zen mode")
 		m.setStatus("Zen Mode: OFF", "green")
-		// We're already in alt screen, but we can send a message
-		return m, nil
 	}
+
+	m.rewrapLines()
+	return m, nil
 }
 
 // getZenModeIndicator returns a visual indicator for zen mode in the status bar
@@ -26,3 +43,285 @@ func (m model) getZenModeIndicator() string {
 	}
 	return ""
 }
+
+// handleZenCommand handles ":zen [measure] | focus <mode> | typewriter on|off"
+func (m model) handleZenCommand(args []string) (tea.Model, tea.Cmd) {
+	if len(args) == 0 {
+		return m.toggleZenMode()
+	}
+
+	switch args[0] {
+	case "focus":
+		if len(args) != 2 || (args[1] != zenFocusParagraph && args[1] != zenFocusSentence && args[1] != zenFocusOff) {
+			m.setStatus("Usage: :zen focus paragraph|sentence|off", "yellow")
+			return m, nil
+		}
+		m.config.ZenFocus = args[1]
+		m.setStatus("Zen focus: "+m.config.ZenFocus, "green")
+
+	case "typewriter":
+		if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+			m.setStatus("Usage: :zen typewriter on|off", "yellow")
+			return m, nil
+		}
+		m.config.ZenTypewriter = args[1] == "on"
+		m.adjustViewport()
+		m.setStatus("Typewriter scrolling: "+args[1], "green")
+
+	default:
+		measure, err := strconv.Atoi(args[0])
+		if err != nil || measure < 20 {
+			m.setStatus("Usage: :zen [measure >= 20] | focus <mode> | typewriter on|off", "yellow")
+			return m, nil
+		}
+		m.config.ZenMeasure = measure
+		if !m.zenMode {
+			return m.toggleZenMode()
+		}
+		m.rewrapLines()
+		m.setStatus(fmt.Sprintf("Zen measure: %d columns", measure), "green")
+	}
+
+	return m, nil
+}
+
+// zenEditorWidth returns the centered editor column width in zen mode
+func (m model) zenEditorWidth() int {
+	measure := m.config.ZenMeasure
+	if measure <= 0 {
+		measure = defaultZenMeasure
+	}
+	width := measure + 2 // textWrapWidth leaves 2 columns of slack
+	if width > m.width {
+		width = m.width
+	}
+	if width < 1 {
+		width = 1
+	}
+	return width
+}
+
+// typewriterPadding returns the blank rows drawn above the text so that the
+// cursor line stays vertically centered near the top of the document
+func (m model) typewriterPadding() int {
+	if !m.zenMode || !m.config.ZenTypewriter {
+		return 0
+	}
+	pad := m.editorHeight()/2 - m.getWrappedLineIndexForCursor()
+	if pad < 0 {
+		return 0
+	}
+	return pad
+}
+
+// paragraphBounds returns the first and last source lines of the paragraph containing line y
+func (m model) paragraphBounds(y int) (int, int) {
+	if y < 0 || y >= len(m.lines) || strings.TrimSpace(m.lines[y]) == "" {
+		return y, y
+	}
+	start, end := y, y
+	for start > 0 && strings.TrimSpace(m.lines[start-1]) != "" {
+		start--
+	}
+	for end < len(m.lines)-1 && strings.TrimSpace(m.lines[end+1]) != "" {
+		end++
+	}
+	return start, end
+}
+
+// sentenceBoundsIn finds the sentence around pos in text. A sentence ends
+// after its terminator, any closing quotes and the spaces that follow, so the
+// cursor between two sentences belongs to the earlier one.
+func sentenceBoundsIn(text string, pos int) (int, int) {
+	if pos > len(text) {
+		pos = len(text)
+	}
+
+	start, end := 0, len(text)
+	for i := 0; i < len(text); i++ {
+		if text[i] != '.' && text[i] != '!' && text[i] != '?' {
+			continue
+		}
+		j := i + 1
+		for j < len(text) && strings.ContainsRune(".!?\"')]", rune(text[j])) {
+			j++
+		}
+		if j < len(text) && text[j] != ' ' && text[j] != '\t' && text[j] != '\n' {
+			continue // e.g. "3.14" or "e.g"
+		}
+		for j < len(text) && (text[j] == ' ' || text[j] == '\t' || text[j] == '\n') {
+			j++
+		}
+
+		// j is the start of the next sentence
+		if j <= pos {
+			start = j
+		} else {
+			end = j
+			break
+		}
+		i = j - 1
+	}
+
+	return start, end
+}
+
+// focusSpan returns the byte range of source line y that is in focus, or
+// ok=false when the whole line is dimmed
+func (m model) focusSpan(y int) (from int, to int, ok bool) {
+	if y < 0 || y >= len(m.lines) {
+		return 0, 0, false
+	}
+	if !m.zenMode || m.config.ZenFocus == zenFocusOff || m.selectionActive {
+		return 0, len(m.lines[y]), true
+	}
+
+	start, end := m.paragraphBounds(m.cursorY)
+	if y < start || y > end {
+		return 0, 0, false
+	}
+	if m.config.ZenFocus != zenFocusSentence {
+		return 0, len(m.lines[y]), true
+	}
+
+	// Flatten the paragraph with newlines so sentences can span lines
+	var sb strings.Builder
+	lineStart := make([]int, end-start+1)
+	cursorPos := 0
+	for i := start; i <= end; i++ {
+		lineStart[i-start] = sb.Len()
+		if i == m.cursorY {
+			cursorPos = sb.Len() + m.cursorX
+		}
+		sb.WriteString(m.lines[i])
+		if i < end {
+			sb.WriteByte('\n')
+		}
+	}
+
+	sStart, sEnd := sentenceBoundsIn(sb.String(), cursorPos)
+	base := lineStart[y-start]
+	from = sStart - base
+	to = sEnd - base
+	if from < 0 {
+		from = 0
+	}
+	if to > len(m.lines[y]) {
+		to = len(m.lines[y])
+	}
+	if from >= to && !(y == m.cursorY && from == to) {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// renderZenLine renders one wrapped segment in zen mode, dimming text outside the focus
+func (m model) renderZenLine(wl wrappedLine) string {
+	dimStyle := lipgloss.NewStyle().
+		Background(m.themeColor("editor.bg")).
+		Foreground(m.themeColor("editor.dim")).
+		Faint(monochrome())
+
+	text := wl.text
+	from, to, ok := m.focusSpan(wl.sourceLineY)
+	if !ok {
+		return dimStyle.Render(text)
+	}
+
+	// Intersect the focus range with this segment
+	a := from - wl.startX
+	b := to - wl.startX
+	if a < 0 {
+		a = 0
+	}
+	if b > len(text) {
+		b = len(text)
+	}
+	if a > b {
+		return dimStyle.Render(text)
+	}
+
+	mid := text[a:b]
+	cursorPos := m.cursorX - wl.startX - a
+	isLastSegment := wl.startX+len(text) >= len(m.lines[wl.sourceLineY])
+	hasCursor := wl.sourceLineY == m.cursorY && cursorPos >= 0 &&
+		(cursorPos < len(mid) || (cursorPos == len(mid) && b == len(text) && isLastSegment))
+
+	var rendered string
+	if hasCursor {
+		rendered = m.renderLineWithCursorAndSpellCheck(mid, cursorPos, wl.sourceLineY, wl.startX+a)
+	} else {
+		rendered = m.applySpellCheckHighlighting(mid, wl.sourceLineY, wl.startX+a)
+	}
+	if m.selectionActive {
+		rendered = m.applySelectionHighlighting(rendered, wl.sourceLineY, wl.startX+a)
+	}
+
+	return dimStyle.Render(text[:a]) + rendered + dimStyle.Render(text[b:])
+}
+
+// renderZenView renders the centered, distraction-free layout
+func (m model) renderZenView() string {
+	baseStyle := lipgloss.NewStyle().
+		Background(m.themeColor("editor.bg")).
+		Foreground(m.themeColor("editor.fg"))
+
+	visibleHeight := m.editorHeight()
+	editorWidth := m.editorWidth()
+	leftMargin := (m.width - editorWidth) / 2
+	rightMargin := m.width - editorWidth - leftMargin
+
+	pad := m.typewriterPadding()
+	visibleLines := m.getVisibleWrappedLines(m.offsetY, visibleHeight-pad)
+
+	var sb strings.Builder
+	for i := 0; i < visibleHeight; i++ {
+		line := ""
+		if idx := i - pad; idx >= 0 && idx < len(visibleLines) {
+			line = m.renderZenLine(visibleLines[idx])
+		}
+
+		sb.WriteString(baseStyle.Width(leftMargin).Render(""))
+		sb.WriteString(baseStyle.Width(editorWidth).Render(line))
+		sb.WriteString(baseStyle.Width(rightMargin).Render(""))
+
+		if i < visibleHeight-1 {
+			sb.WriteString("\n")
+		}
+	}
+
+	// Panes still open below the text when asked for
+	if m.infoVisible {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderInfoPane())
+	}
+	if m.thesaurusVisible {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderThesaurus())
+	}
+	if m.quickfixVisible {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderQuickfix())
+	}
+	if m.completionMenuHeight() > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderCompletionMenu())
+	}
+	if m.paletteVisible {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderPalette())
+	}
+	if m.finderVisible {
+		sb.WriteString("\n")
+		sb.WriteString(m.renderFinder())
+	}
+
+	// Only the command line is shown, and only while typing a command
+	if m.commandMode {
+		status := strings.Split(m.renderStatusBar(), "\n")
+		sb.WriteString("\n")
+		sb.WriteString(status[len(status)-1])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestSentenceBoundsIn(t *testing.T) {
+	text := `First one. "Second, quoted!" Third is 3.14 long? Last`
+	tests := []struct {
+		pos  int
+		want string
+	}{
+		{0, "First one. "},
+		{10, "First one. "}, // on the space after the full stop
+		{11, `"Second, quoted!" `},
+		{35, "Third is 3.14 long? "},
+		{len(text), "Last"},
+	}
+	for _, tt := range tests {
+		start, end := sentenceBoundsIn(text, tt.pos)
+		if got := text[start:end]; got != tt.want {
+			t.Errorf("sentenceBoundsIn(%d) = %q, want %q", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestFocusSpan(t *testing.T) {
+	m := model{
+		lines: []string{
+			"Intro line.",
+			"",
+			"One sentence. Another that",
+			"spans two lines. Tail.",
+			"",
+			"Outro.",
+		},
+		zenMode: true,
+		config:  Config{ZenFocus: zenFocusParagraph},
+		cursorY: 3,
+		cursorX: 2,
+	}
+
+	if _, _, ok := m.focusSpan(0); ok {
+		t.Error("line outside the paragraph should be dimmed")
+	}
+	if from, to, ok := m.focusSpan(2); !ok || from != 0 || to != len(m.lines[2]) {
+		t.Errorf("paragraph focus on line 2 = %d..%d %v", from, to, ok)
+	}
+
+	m.config.ZenFocus = zenFocusSentence
+	if from, to, ok := m.focusSpan(2); !ok || m.lines[2][from:to] != "Another that" {
+		t.Errorf("sentence focus on line 2 = %d..%d %v", from, to, ok)
+	}
+	if from, to, ok := m.focusSpan(3); !ok || m.lines[3][from:to] != "spans two lines. " {
+		t.Errorf("sentence focus on line 3 = %d..%d %v", from, to, ok)
+	}
+
+	m.config.ZenFocus = zenFocusOff
+	if _, _, ok := m.focusSpan(5); !ok {
+		t.Error("focus off should keep every line bright")
+	}
+}
+
+func TestZenViewLayout(t *testing.T) {
+	var lines []string
+	for i := 0; i < 40; i++ {
+		lines = append(lines, strings.Repeat("word ", 30))
+	}
+
+	m := model{
+		lines:           lines,
+		width:           120,
+		height:          30,
+		wrapCache:       make(map[int][]wrappedLine),
+		wordCounter:     newWordCounter(),
+		fileTreeVisible: true,
+		config:          Config{ZenMeasure: 60, ZenFocus: zenFocusParagraph, ZenTypewriter: true},
+	}
+	result, _ := m.toggleZenMode()
+	m = result.(model)
+
+	if got := m.textWrapWidth(); got != 60 {
+		t.Errorf("textWrapWidth in zen = %d, want 60", got)
+	}
+	if got := m.editorHeight(); got != 30 {
+		t.Errorf("editorHeight in zen = %d, want 30 (no status bar)", got)
+	}
+
+	// The cursor line sits in the middle: padding at the top of the document
+	if got := m.typewriterPadding(); got != 15 {
+		t.Errorf("typewriterPadding at top = %d, want 15", got)
+	}
+
+	// Further down the viewport scrolls instead of padding
+	m.cursorY = 20
+	m.adjustViewport()
+	if pad := m.typewriterPadding(); pad != 0 || m.getWrappedLineIndexForCursor()-m.offsetY != 15 {
+		t.Errorf("cursor row = %d (pad %d), want 15", m.getWrappedLineIndexForCursor()-m.offsetY, pad)
+	}
+
+	rows := strings.Split(m.View(), "\n")
+	if len(rows) != 30 {
+		t.Fatalf("zen view has %d rows, want 30", len(rows))
+	}
+	for i, row := range rows {
+		if w := lipgloss.Width(row); w != 120 {
+			t.Errorf("row %d width = %d, want 120", i, w)
+		}
+	}
+}
+
+func TestZenSelectionOnWrappedSegment(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	m := model{
+		lines:     []string{strings.Repeat("word ", 30), "next"},
+		wrapWidth: 40,
+		wrapCache: make(map[int][]wrappedLine),
+		config:    Config{ZenFocus: zenFocusOff},
+		zenMode:   true,
+	}
+	segments := m.getWrappedLine(0)
+	if len(segments) < 2 {
+		t.Fatalf("line wrapped into %d segments, want several", len(segments))
+	}
+	seg := segments[1]
+
+	// Select from the middle of the second segment into the next line
+	m.selectionActive = true
+	m.selectionStartY, m.selectionStartX = 0, seg.startX+10
+	m.cursorY, m.cursorX = 1, 2
+
+	got := m.renderZenLine(seg)
+	want := m.applySelectionHighlighting(seg.text, 0, seg.startX)
+	if want == seg.text || !strings.Contains(got, want) {
+		t.Errorf("selection on the second segment = %q, want it to contain %q", got, want)
+	}
+	if !strings.Contains(got, seg.text[:10]+"\x1b[") {
+		t.Errorf("selection should start 10 bytes into the segment: %q", got)
+	}
+}